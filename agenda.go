@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agendaDays is how many days ahead the agenda lays out, starting today
+const agendaDays = 7
+
+// agendaGroup is one heading of the agenda and the open list's unfinished
+// tasks under it
+type agendaGroup struct {
+	title string
+	tasks []int // Indices into m.tasks, in list order
+}
+
+// agenda sorts the open list's unfinished tasks into overdue, each of the
+// next seven days, and undated. Tasks due after the week are left out, and
+// their number returned as later.
+func (m model) agenda(now time.Time) (groups []agendaGroup, later int) {
+	today := startOfDay(now)
+	groups = make([]agendaGroup, agendaDays+2)
+	groups[0].title = "Overdue"
+	for d := 0; d < agendaDays; d++ {
+		day := today.AddDate(0, 0, d)
+		switch d {
+		case 0:
+			groups[d+1].title = "Today, " + m.formatDate(day)
+		case 1:
+			groups[d+1].title = "Tomorrow, " + m.formatDate(day)
+		default:
+			groups[d+1].title = day.Format("Monday") + ", " + m.formatDate(day)
+		}
+	}
+	groups[agendaDays+1].title = "No due date"
+
+	for i, t := range m.tasks {
+		if t.Status == statusDone {
+			continue
+		}
+		g := agendaDays + 1
+		switch {
+		case t.Due.IsZero():
+		case t.overdue(now):
+			g = 0
+		default:
+			g = int(startOfDay(t.Due).Sub(today).Hours()+12)/24 + 1
+			if g > agendaDays {
+				later++
+				continue
+			}
+		}
+		groups[g].tasks = append(groups[g].tasks, i)
+	}
+	return groups, later
+}
+
+// agendaTasks lists the tasks the agenda shows, top to bottom, for moving
+// the cursor through
+func (m model) agendaTasks() []int {
+	groups, _ := m.agenda(time.Now())
+	var tasks []int
+	for _, g := range groups {
+		tasks = append(tasks, g.tasks...)
+	}
+	return tasks
+}
+
+// updateViewingAgenda handles key input in the agenda: enter goes to the
+// highlighted task in the list and e hides or shows the empty days
+func (m model) updateViewingAgenda(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tasks := m.agendaTasks()
+	switch msg.String() {
+	case "esc", "q", "N":
+		m.state = browsing
+
+	case "up", "k":
+		if m.agendaCursor > 0 {
+			m.agendaCursor--
+		}
+
+	case "down", "j":
+		if m.agendaCursor < len(tasks)-1 {
+			m.agendaCursor++
+		}
+
+	case "e":
+		m.agendaHideEmpty = !m.agendaHideEmpty
+
+	case "enter":
+		if m.agendaCursor < len(tasks) {
+			m.reveal(tasks[m.agendaCursor])
+			m.moveCursorTo(tasks[m.agendaCursor])
+			m.state = browsing
+		}
+	}
+	return m, nil
+}
+
+// viewAgenda renders the week ahead, each day a heading over the tasks due
+// then, drawing only the lines that fit around the highlighted task
+func (m model) viewAgenda() string {
+	groups, later := m.agenda(time.Now())
+	s := titleStyle.Render("🗓  Week ahead in "+m.lists[m.active].label()) + "\n\n"
+
+	var lines []string
+	at, n := 0, 0
+	for gi, g := range groups {
+		day := gi > 0 && gi <= agendaDays // Not overdue or undated
+		if len(g.tasks) == 0 {
+			if day && !m.agendaHideEmpty {
+				lines = append(lines, "  "+blurredStyle.Render(g.title+" • nothing due"))
+			}
+			continue
+		}
+		style := foldStyle.Bold(true)
+		if gi == 0 {
+			style = badgeStyle.Bold(true)
+		}
+		lines = append(lines, "  "+style.Render(fmt.Sprintf("%s (%d)", g.title, len(g.tasks))))
+		for _, i := range g.tasks {
+			t := m.tasks[i]
+			if n == m.agendaCursor {
+				at = len(lines)
+				lines = append(lines, cursorStyle.Render(m.icons.Cursor+" ")+"  "+m.statusMarker(t.Status)+" "+selectedStyle.Render(t.Text))
+			} else {
+				lines = append(lines, m.gutter()+"  "+m.statusMarker(t.Status)+" "+taskStyle.Render(t.Text))
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		lines = append(lines, normalStyle.Render("Nothing left to do in this list."))
+	}
+
+	room := len(lines)
+	if m.height > 0 {
+		room = max(m.height-8, 3)
+	}
+	start := max(min(at-room/2, len(lines)-room), 0)
+	for _, line := range lines[start:min(start+room, len(lines))] {
+		s += line + "\n"
+	}
+	if later > 0 {
+		s += "\n" + metaStyle.Render(fmt.Sprintf("  %d due after the week not shown", later)) + "\n"
+	}
+	empty := "e: hide empty days"
+	if m.agendaHideEmpty {
+		empty = "e: show empty days"
+	}
+	return s + "\n" + helpStyle.Render("↑/↓: choose • enter: go to task • "+empty+" • esc: back")
+}
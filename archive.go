@@ -0,0 +1,585 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archivedTask is a finished task as the archive file keeps it, with the
+// list it came from and when it was archived. Its Completed time says when
+// it was finished.
+type archivedTask struct {
+	task
+	List     string    `json:"list"`
+	Archived time.Time `json:"archived"`
+}
+
+// archivePath returns where finished tasks are archived, archive.json next
+// to the tasks file
+func archivePath(dataFile string) string {
+	return filepath.Join(filepath.Dir(dataFile), "archive.json")
+}
+
+// loadArchive reads every task archived so far, oldest first. A missing
+// file yields none and no error.
+func loadArchive(path string) ([]archivedTask, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var archived []archivedTask
+	if err := json.Unmarshal(raw, &archived); err != nil {
+		return nil, fmt.Errorf("%s is unreadable: %w", path, err)
+	}
+	return archived, nil
+}
+
+// appendArchive adds tasks to the end of the archive file, keeping what it
+// already holds but for earlier copies of the same tasks, by ID, as
+// archiving again after an undo leaves. It returns the whole archive as
+// written. An archive that can't be read is left alone rather than written
+// over.
+func appendArchive(path string, tasks []archivedTask) ([]archivedTask, error) {
+	archived, err := loadArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, t := range tasks {
+		if t.ID != "" {
+			ids[t.ID] = true
+		}
+	}
+	archived = append(slices.DeleteFunc(archived, func(a archivedTask) bool { return ids[a.ID] }), tasks...)
+	return archived, writeArchive(path, archived)
+}
+
+// writeArchive replaces the archive file with archived
+func writeArchive(path string, archived []archivedTask) error {
+	raw, err := json.MarshalIndent(archived, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, raw)
+}
+
+// doneStamp is when an archived task was finished, by its ID
+type doneStamp struct {
+	id string
+	at time.Time
+}
+
+// doneStamps returns when each finished task in archived was finished, for
+// the completion goals to count
+func doneStamps(archived []archivedTask) []doneStamp {
+	var stamps []doneStamp
+	for _, a := range archived {
+		if a.Status == statusDone && !a.Completed.IsZero() {
+			stamps = append(stamps, doneStamp{a.ID, a.Completed})
+		}
+	}
+	return stamps
+}
+
+// archiveRuns copies the runs of finished tasks starting at starts in list
+// l, each with its subtasks, into the archive file, giving tasks IDs first
+// so an earlier copy of one is replaced. The list is left as it is, for
+// dropArchived to take them out once they're safely written.
+func (m *model) archiveRuns(l int, starts []int) (int, error) {
+	m.assignIDs()
+	tasks := m.lists[l].Tasks
+	if l == m.active {
+		tasks = m.tasks
+	}
+	now := time.Now()
+	var archived []archivedTask
+	for _, s := range starts {
+		for _, t := range tasks[s:subtreeEnd(tasks, s)] {
+			t.Depth -= tasks[s].Depth // Subtasks stay under their finished parent
+			t.TimerStarted = time.Time{}
+			archived = append(archived, archivedTask{task: t, List: m.lists[l].Name, Archived: now})
+		}
+	}
+	all, err := appendArchive(archivePath(m.dataFile), archived)
+	if err != nil {
+		return 0, err
+	}
+	m.archivedDone = doneStamps(all)
+	return len(archived), nil
+}
+
+// dropArchived takes the runs archiveRuns archived out of list l
+func (m *model) dropArchived(l int, starts []int) {
+	tasks := m.lists[l].Tasks
+	if l == m.active {
+		tasks = m.tasks
+	}
+	for s := len(starts) - 1; s >= 0; s-- {
+		end := subtreeEnd(tasks, starts[s])
+		for _, t := range tasks[starts[s]:end] {
+			m.record("archive", t.Text)
+		}
+		tasks = slices.Delete(tasks, starts[s], end)
+	}
+	if l == m.active {
+		m.tasks = tasks
+	} else {
+		m.lists[l].Tasks = tasks
+	}
+}
+
+// archiveDone moves every finished task, as clearDone would delete them,
+// out of the open list and into the archive file, as a change undo can
+// take back. Undoing brings the tasks back to the list, but the archive
+// keeps its copy until they're archived again and it's replaced.
+func (m *model) archiveDone() {
+	starts := finishedSubtrees(m.tasks)
+	if len(starts) == 0 {
+		m.notice = "Nothing finished to archive"
+		return
+	}
+	n, err := m.archiveRuns(m.active, starts)
+	if err != nil {
+		m.notice = "Could not archive: " + err.Error()
+		return
+	}
+
+	m.checkpoint()
+	m.describeChange("archiving " + finishedCount(n))
+	m.dropArchived(m.active, starts)
+	m.moveCursorTo(starts[0])
+	m.notice = fmt.Sprintf("Archived %s to %s", finishedCount(n), archivePath(m.dataFile))
+}
+
+// archiveFinished archives the tasks finished in this update, as
+// archiveOnDone has them, at once or once the delay is up
+func (m *model) archiveFinished() tea.Cmd {
+	ids := m.pendingArchive
+	m.pendingArchive = nil
+	if m.archiveDelay <= 0 {
+		m.autoArchive(ids, false) // Part of marking them done, for undo
+		return nil
+	}
+	return tea.Tick(m.archiveDelay, func(time.Time) tea.Msg { return archiveDueMsg(ids) })
+}
+
+// autoArchive moves each task with one of ids, and its subtasks, into the
+// archive, as long as they're all done still. Each is looked for in every
+// list, since the one it was finished on may no longer be open by the time
+// a delayed archive comes due. The cursor stays on the task it was on,
+// unless that one went. A change of its own, when undoable, takes a
+// checkpoint first.
+func (m *model) autoArchive(ids []string, undoable bool) {
+	runs := make([][]int, len(m.lists)) // Where the runs to archive start, per list
+	var from []string                   // Other lists they're archived from
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := 0; i < len(tasks); i++ {
+			if !slices.Contains(ids, tasks[i].ID) {
+				continue
+			}
+			end := subtreeEnd(tasks, i)
+			if !slices.ContainsFunc(tasks[i:end], func(t task) bool { return t.Status != statusDone }) {
+				runs[l] = append(runs[l], i)
+				i = end - 1
+			}
+		}
+		if len(runs[l]) > 0 && l != m.active {
+			from = append(from, m.lists[l].label())
+		}
+	}
+	if len(runs[m.active]) == 0 && len(from) == 0 {
+		return
+	}
+	var on string
+	if i, ok := m.selected(); ok {
+		on = m.tasks[i].ID
+	}
+	n := 0
+	for l, starts := range runs {
+		if len(starts) == 0 {
+			continue
+		}
+		archived, err := m.archiveRuns(l, starts)
+		if err != nil {
+			m.notice = "Could not archive: " + err.Error()
+			return
+		}
+		n += archived
+	}
+
+	if undoable {
+		m.checkpoint()
+		m.describeChange("archiving " + finishedCount(n))
+	}
+	for l, starts := range runs {
+		if len(starts) > 0 {
+			m.dropArchived(l, starts)
+		}
+	}
+	if starts := runs[m.active]; len(starts) > 0 {
+		if i := slices.IndexFunc(m.tasks, func(t task) bool { return t.ID == on }); i >= 0 {
+			m.moveCursorTo(i)
+		} else {
+			m.moveCursorTo(starts[0])
+		}
+	}
+	archived := "Archived " + finishedCount(n)
+	if len(from) > 0 {
+		archived += " from " + strings.Join(from, ", ")
+	}
+	switch {
+	case m.notice == "":
+		m.notice = archived
+	case len(from) > 0:
+		m.notice += " • " + archived
+	default:
+		m.notice += " • archived"
+	}
+}
+
+// archiveAsk is a change to the archive that can't be taken back, waiting
+// on a y to go ahead
+type archiveAsk int
+
+const (
+	askNothing archiveAsk = iota
+	askDelete             // Delete the selected task, subtasks and all
+	askEmpty              // Delete everything archived
+)
+
+// startArchive opens the archive to look through, newest first
+func (m model) startArchive() (tea.Model, tea.Cmd) {
+	archived, err := loadArchive(archivePath(m.dataFile))
+	if err != nil {
+		m.notice = "Could not read the archive: " + err.Error()
+		return m, nil
+	}
+	m.setArchive(archived)
+	m.archiveCursor, m.archiveOffset, m.archiveAsk = 0, 0, askNothing
+	m.state = viewingArchive
+	return m, nil
+}
+
+// setArchive makes archived, oldest first, the archive being looked
+// through, keeping the cursor on it and the goals counting what's in it
+func (m *model) setArchive(archived []archivedTask) {
+	m.archive, m.archiveOrder = archived, newestFirst(archived)
+	m.archivedDone = doneStamps(archived)
+	m.archiveCursor = max(min(m.archiveCursor, len(m.archiveOrder)-1), 0)
+}
+
+// newestFirst returns where each archived task is, in the order the archive
+// shows them: newest first, each task's subtasks kept under it
+func newestFirst(archived []archivedTask) []int {
+	var order []int
+	end := len(archived)
+	for i := end - 1; i >= 0; i-- {
+		if archived[i].Depth == 0 || i == 0 {
+			for j := i; j < end; j++ {
+				order = append(order, j)
+			}
+			end = i
+		}
+	}
+	return order
+}
+
+// archivedRun returns where the selected archived task and its subtasks
+// start and end in the archive
+func (m model) archivedRun() (int, int) {
+	start := m.archiveOrder[m.archiveCursor]
+	end := start + 1
+	for end < len(m.archive) && m.archive[end].Depth > m.archive[start].Depth {
+		end++
+	}
+	return start, end
+}
+
+// archivedRuns returns where each run of an archived task with its subtasks
+// starts and ends, covering the whole archive
+func archivedRuns(archived []archivedTask) [][2]int {
+	var runs [][2]int
+	for i := range archived {
+		if archived[i].Depth == 0 || i == 0 {
+			runs = append(runs, [2]int{i, i + 1})
+		} else {
+			runs[len(runs)-1][1] = i + 1
+		}
+	}
+	return runs
+}
+
+// withoutRuns returns archived without the tasks in runs
+func withoutRuns(archived []archivedTask, runs [][2]int) []archivedTask {
+	var kept []archivedTask
+	next := 0
+	for _, r := range runs {
+		kept = append(kept, archived[next:r[0]]...)
+		next = r[1]
+	}
+	return append(kept, archived[next:]...)
+}
+
+// restoreArchived puts each run of archived tasks back at the end of the
+// list it came from, or of the open list once that one's gone, and takes
+// them out of the archive. The undo steps get them too, since they know
+// nothing of the archive, so going back past the restore doesn't lose them.
+func (m *model) restoreArchived(runs [][2]int) error {
+	kept := withoutRuns(m.archive, runs)
+	if err := writeArchive(archivePath(m.dataFile), kept); err != nil {
+		return err
+	}
+	for _, r := range runs {
+		var tasks []task
+		for _, a := range m.archive[r[0]:r[1]] {
+			t := a.task
+			t.Depth -= m.archive[r[0]].Depth
+			tasks = append(tasks, t)
+			m.record("restore", t.Text)
+		}
+		name := m.archive[r[0]].List
+		l := slices.IndexFunc(m.lists, func(l taskList) bool { return strings.EqualFold(l.Name, name) })
+		if l < 0 {
+			l = m.active
+		}
+		if l == m.active {
+			m.tasks = append(m.tasks, tasks...)
+		} else {
+			m.lists[l].Tasks = append(m.lists[l].Tasks, tasks...)
+		}
+		for _, stack := range [][]snapshot{m.undo, m.redo} {
+			for n := range stack {
+				stack[n].addTasks(name, tasks)
+			}
+		}
+	}
+	m.dirty = true
+	m.setArchive(kept)
+	return nil
+}
+
+// addTasks adds copies of tasks to the end of the snapshot's list called
+// name, or of its open list when it has none by that name. Tasks the
+// snapshot already holds, as those taken before they were archived do,
+// are left out so going back to it doesn't show them twice.
+func (s *snapshot) addTasks(name string, tasks []task) {
+	held := make(map[string]bool)
+	for _, l := range s.lists {
+		for _, t := range l.Tasks {
+			if t.ID != "" {
+				held[t.ID] = true
+			}
+		}
+	}
+	var missing []task
+	for _, t := range tasks {
+		if t.ID == "" || !held[t.ID] {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	l := slices.IndexFunc(s.lists, func(l taskList) bool { return strings.EqualFold(l.Name, name) })
+	if l < 0 {
+		l = s.list
+	}
+	s.lists[l].Tasks = append(slices.Clip(s.lists[l].Tasks), cloneTasks(missing)...)
+}
+
+// deleteArchived deletes the runs of archived tasks from the archive for
+// good
+func (m *model) deleteArchived(runs [][2]int) error {
+	kept := withoutRuns(m.archive, runs)
+	if err := writeArchive(archivePath(m.dataFile), kept); err != nil {
+		return err
+	}
+	for _, r := range runs {
+		for _, a := range m.archive[r[0]:r[1]] {
+			m.record("delete from archive", a.Text)
+		}
+	}
+	m.setArchive(kept)
+	return nil
+}
+
+// archiveRows is how many archived tasks fit on screen at once
+func (m model) archiveRows() int {
+	if m.height <= 0 {
+		return historyScreenRows
+	}
+	return max(m.height-6, 3)
+}
+
+// updateViewingArchive handles key input in the archive: ↑ and ↓ move
+// through it, r restores the selected task to its list and R every task, x
+// deletes the selected task for good and X everything, each once y
+// confirms, and esc goes back
+func (m model) updateViewingArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.archiveAsk != askNothing {
+		return m.updateArchiveAsk(msg)
+	}
+	last := len(m.archiveOrder) - 1
+	switch msg.String() {
+	case "esc", "q", "ctrl+l":
+		m.archive, m.archiveOrder = nil, nil
+		m.state = browsing
+
+	case "up", "k":
+		m.archiveCursor = max(m.archiveCursor-1, 0)
+
+	case "down", "j":
+		m.archiveCursor = max(min(m.archiveCursor+1, last), 0)
+
+	case "g", "home":
+		m.archiveCursor = 0
+
+	case "G", "end":
+		m.archiveCursor = max(last, 0)
+
+	case "r":
+		if len(m.archive) == 0 {
+			break
+		}
+		start, end := m.archivedRun()
+		text, list := m.archive[start].Text, m.archive[start].List
+		if err := m.restoreArchived([][2]int{{start, end}}); err != nil {
+			m.notice = "Could not restore: " + err.Error()
+			break
+		}
+		m.notice = fmt.Sprintf("Restored %q to %s", truncateRunes(text, 40), m.restoredTo(list))
+
+	case "R":
+		if len(m.archive) == 0 {
+			break
+		}
+		n := len(m.archive)
+		if err := m.restoreArchived(archivedRuns(m.archive)); err != nil {
+			m.notice = "Could not restore: " + err.Error()
+			break
+		}
+		m.notice = "Restored " + taskCount(n) + " to their lists"
+
+	case "x", "d", "delete":
+		if len(m.archive) > 0 {
+			m.archiveAsk = askDelete
+		}
+
+	case "X":
+		if len(m.archive) > 0 {
+			m.archiveAsk = askEmpty
+		}
+	}
+	m.archiveOffset = max(min(m.archiveOffset, m.archiveCursor), m.archiveCursor-m.archiveRows()+1)
+	return m, nil
+}
+
+// restoredTo names where tasks from the list called name were restored to:
+// that list, or the open one when it's gone
+func (m model) restoredTo(name string) string {
+	if slices.ContainsFunc(m.lists, func(l taskList) bool { return strings.EqualFold(l.Name, name) }) {
+		return name
+	}
+	return m.lists[m.active].Name + ", as " + name + " is gone"
+}
+
+// updateArchiveAsk handles the answer to whether to delete from the archive
+// for good: y goes ahead, and anything else thinks better of it
+func (m model) updateArchiveAsk(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	ask := m.archiveAsk
+	m.archiveAsk = askNothing
+	if msg.String() != "y" && msg.String() != "Y" {
+		return m, nil
+	}
+	runs := archivedRuns(m.archive)
+	if ask == askDelete {
+		start, end := m.archivedRun()
+		runs = [][2]int{{start, end}}
+	}
+	n := 0
+	for _, r := range runs {
+		n += r[1] - r[0]
+	}
+	if err := m.deleteArchived(runs); err != nil {
+		m.notice = "Could not delete: " + err.Error()
+		return m, nil
+	}
+	m.notice = "Deleted " + taskCount(n) + " from the archive"
+	if ask == askEmpty {
+		m.notice = "Emptied the archive"
+	}
+	m.archiveOffset = max(min(m.archiveOffset, m.archiveCursor), m.archiveCursor-m.archiveRows()+1)
+	return m, nil
+}
+
+// archiveQuestion asks whether to go ahead with the change waiting on a y
+func (m model) archiveQuestion() string {
+	if m.archiveAsk == askEmpty {
+		return fmt.Sprintf("Delete all %s in the archive for good? (y/n)", taskCount(len(m.archive)))
+	}
+	start, end := m.archivedRun()
+	q := fmt.Sprintf("Delete %q", truncateRunes(m.archive[start].Text, 40))
+	switch end - start {
+	case 1:
+	case 2:
+		q += " and its subtask"
+	default:
+		q += fmt.Sprintf(" and its %d subtasks", end-start-1)
+	}
+	return q + " for good? (y/n)"
+}
+
+// viewArchive renders the archived tasks that fit, newest first, each with
+// the day it was finished and the list it came from
+func (m model) viewArchive() string {
+	s := titleStyle.Render("🗄 Archive") + "\n\n"
+	if len(m.archive) == 0 {
+		s += normalStyle.Render("Nothing archived yet. ctrl+f archives the finished tasks of a list.") + "\n"
+	}
+	width := 0
+	for _, a := range m.archive {
+		width = max(width, len([]rune(a.List)))
+	}
+	end := min(m.archiveOffset+m.archiveRows(), len(m.archiveOrder))
+	for row := m.archiveOffset; row < end; row++ {
+		a := m.archive[m.archiveOrder[row]]
+		finished := a.Completed
+		if finished.IsZero() {
+			finished = a.Archived
+		}
+		list := a.List + strings.Repeat(" ", width-len([]rune(a.List)))
+		lead, text := m.gutter(), doneStyle.Render(a.Text)
+		if row == m.archiveCursor {
+			lead, text = cursorStyle.Render(m.icons.Cursor+" "), selectedStyle.Render(a.Text)
+		}
+		s += lead + metaStyle.Render(m.formatDate(finished)+"  "+list) + "  " +
+			strings.Repeat("  ", a.Depth) + text + "\n"
+	}
+	if len(m.archive) > 0 {
+		s += "\n" + metaStyle.Render(fmt.Sprintf("%d-%d of %d archived", m.archiveOffset+1, end, len(m.archive))) + "\n"
+	}
+	if m.archiveAsk != askNothing {
+		s += "\n" + noticeStyle.Render(m.archiveQuestion()) + "\n"
+	} else if m.notice != "" {
+		s += "\n" + noticeStyle.Render(m.notice) + "\n"
+	}
+	return s + "\n" + helpStyle.Render("↑/↓: move • r: restore • R: restore all • x: delete • X: empty • esc: back")
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archiveKey archives every finished task, as ctrl+f does
+var archiveKey = tea.KeyMsg{Type: tea.KeyCtrlF}
+
+func TestArchivingAgainAfterUndoKeepsOneCopy(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "ship it", "keep going")
+	m.setStatus(0, statusDone)
+	m = press(m, archiveKey, runes("u"), archiveKey)
+
+	archived, err := loadArchive(archivePath(m.dataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 1 || archived[0].Text != "ship it" {
+		t.Fatalf("archive holds %v, want ship it once", archived)
+	}
+}
+
+func TestAppendArchiveKeepsTasksWithoutIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.json")
+	old := []archivedTask{{task: task{Text: "from before IDs"}}, {task: task{ID: "aaa111", Text: "first try"}}}
+	if _, err := appendArchive(path, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := appendArchive(path, []archivedTask{{task: task{Text: "also without"}}, {task: task{ID: "aaa111", Text: "again"}}}); err != nil {
+		t.Fatal(err)
+	}
+	archived, err := loadArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, a := range archived {
+		texts = append(texts, a.Text)
+	}
+	if len(texts) != 3 || texts[0] != "from before IDs" || texts[1] != "also without" || texts[2] != "again" {
+		t.Fatalf("archive holds %q, want the two without IDs and the newer copy of aaa111", texts)
+	}
+}
+
+// archivingConfig is the default config with archiveOnDone on, archiving
+// after delay seconds
+func archivingConfig(delay int) config {
+	cfg := defaultConfig()
+	cfg.ArchiveOnDone, cfg.ArchiveDelaySeconds = true, delay
+	return cfg
+}
+
+func TestArchiveOnDoneAtOnce(t *testing.T) {
+	m := newTestModel(t, archivingConfig(0))
+	m = addTasks(m, "ship it", "keep going")
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if len(m.tasks) != 1 || m.tasks[0].Text != "keep going" {
+		t.Fatalf("list holds %v, want only keep going", m.tasks)
+	}
+	archived, err := loadArchive(archivePath(m.dataFile))
+	if err != nil || len(archived) != 1 || archived[0].Text != "ship it" {
+		t.Fatalf("archive holds %v (%v), want ship it", archived, err)
+	}
+	if n := m.doneSince(startOfDay(time.Now())); n != 1 {
+		t.Errorf("done today = %d, want the archived task counted", n)
+	}
+
+	m = press(m, runes("u"))
+	if len(m.tasks) != 2 || m.tasks[0].Status == statusDone {
+		t.Fatalf("undo left %v, want ship it back and open", m.tasks)
+	}
+	if n := m.doneSince(startOfDay(time.Now())); n != 0 {
+		t.Errorf("done today after undo = %d, want 0", n)
+	}
+}
+
+func TestArchiveOnDoneAfterDelay(t *testing.T) {
+	m := newTestModel(t, archivingConfig(2))
+	m = addTasks(m, "ship it", "keep going")
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp})
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if len(m.tasks) != 2 || m.tasks[0].Status != statusDone {
+		t.Fatalf("list holds %v, want ship it done and still showing", m.tasks)
+	}
+
+	id := m.tasks[0].ID
+	next, _ = m.Update(archiveDueMsg{id})
+	m = next.(model)
+	if len(m.tasks) != 1 || m.tasks[0].Text != "keep going" {
+		t.Fatalf("list holds %v once the delay is up, want only keep going", m.tasks)
+	}
+	m = press(m, runes("u"))
+	if len(m.tasks) != 2 || m.tasks[0].Status != statusDone {
+		t.Fatalf("undo left %v, want ship it back, still done", m.tasks)
+	}
+	if n := m.doneSince(startOfDay(time.Now())); n != 1 {
+		t.Errorf("done today = %d, want ship it counted once", n)
+	}
+}
+
+func TestArchiveOnDoneAfterSwitchingLists(t *testing.T) {
+	m := newTestModel(t, archivingConfig(2))
+	m = addTasks(m, "ship it", "keep going")
+	m.lists = append(m.lists, taskList{Name: "Other", Tasks: []task{{ID: "ccc333", Text: "elsewhere"}}})
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyEnter})
+	id := m.tasks[0].ID
+
+	// The delay runs out with another list open
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	next, _ := m.Update(archiveDueMsg{id})
+	m = next.(model)
+	if len(m.tasks) != 1 || m.tasks[0].Text != "elsewhere" {
+		t.Fatalf("open list holds %v, want it left as it was", m.tasks)
+	}
+	if !strings.Contains(m.notice, "Archived") {
+		t.Errorf("notice = %q, want it to say the task was archived", m.notice)
+	}
+	archived, err := loadArchive(archivePath(m.dataFile))
+	if err != nil || len(archived) != 1 || archived[0].Text != "ship it" || archived[0].List != m.lists[0].Name {
+		t.Fatalf("archive holds %v (%v), want ship it from the first list", archived, err)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if len(m.tasks) != 1 || m.tasks[0].Text != "keep going" {
+		t.Fatalf("first list holds %v, want only keep going", m.tasks)
+	}
+}
+
+func TestArchiveOnDoneSkipsReopenedTask(t *testing.T) {
+	m := newTestModel(t, archivingConfig(2))
+	m = addTasks(m, "ship it")
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ := m.Update(archiveDueMsg{m.tasks[0].ID})
+	m = next.(model)
+	if len(m.tasks) != 1 {
+		t.Fatalf("list holds %v, want the reopened task kept", m.tasks)
+	}
+}
+
+// archivedModel returns the app with "old" and "new", a task with a
+// subtask, archived from its list in that order, and the archive open
+func archivedModel(t *testing.T) model {
+	t.Helper()
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "old", "new", "new's subtask")
+	m.tasks[2].Depth = 1
+	for i := range m.tasks {
+		m.setStatus(i, statusDone)
+	}
+	return press(m, archiveKey, tea.KeyMsg{Type: tea.KeyCtrlL})
+}
+
+// archivedTexts returns the text of each task in the archive file
+func archivedTexts(t *testing.T, m model) []string {
+	t.Helper()
+	archived, err := loadArchive(archivePath(m.dataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, a := range archived {
+		texts = append(texts, a.Text)
+	}
+	return texts
+}
+
+func TestArchiveShowsNewestFirst(t *testing.T) {
+	m := archivedModel(t)
+	var shown []string
+	for _, i := range m.archiveOrder {
+		shown = append(shown, m.archive[i].Text)
+	}
+	if want := []string{"new", "new's subtask", "old"}; !slices.Equal(shown, want) {
+		t.Fatalf("archive shows %q, want %q", shown, want)
+	}
+	if view := m.View(); !strings.Contains(view, "new's subtask") || !strings.Contains(view, "1-3 of 3 archived") {
+		t.Fatalf("archive view doesn't show every task:\n%s", view)
+	}
+}
+
+func TestRestoreFromArchive(t *testing.T) {
+	m := archivedModel(t)
+	m = press(m, runes("r"))
+	if want := []string{"old"}; !slices.Equal(archivedTexts(t, m), want) {
+		t.Fatalf("archive holds %q after restoring, want %q", archivedTexts(t, m), want)
+	}
+	if len(m.tasks) != 2 || m.tasks[0].Text != "new" || m.tasks[1].Depth != 1 {
+		t.Fatalf("list holds %v, want new with its subtask", m.tasks)
+	}
+
+	// Undoing the archiving from before brings back the list as it was,
+	// holding the restored tasks once
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc}, runes("u"))
+	var texts []string
+	ids := make(map[string]bool)
+	for _, task := range m.tasks {
+		texts = append(texts, task.Text)
+		if ids[task.ID] {
+			t.Fatalf("undo left %q in the list twice: %q", task.Text, texts)
+		}
+		ids[task.ID] = true
+	}
+	if want := []string{"old", "new", "new's subtask"}; !slices.Equal(texts, want) {
+		t.Fatalf("undo left %q, want %q", texts, want)
+	}
+}
+
+func TestUndoAfterRestoreKeepsOneCopy(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "ship it", "keep going")
+	m.setStatus(0, statusDone)
+	m = press(m, archiveKey, tea.KeyMsg{Type: tea.KeyCtrlL}, runes("r"), tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Each step back, to before the restore and before the archiving, holds
+	// ship it once
+	for step := 0; step < 2; step++ {
+		m = press(m, runes("u"))
+		count := 0
+		for _, task := range m.tasks {
+			if task.Text == "ship it" {
+				count++
+			}
+		}
+		if count > 1 {
+			t.Fatalf("after %d undos the list holds ship it %d times: %v", step+1, count, m.tasks)
+		}
+	}
+}
+
+func TestRestoreAllFromArchive(t *testing.T) {
+	m := archivedModel(t)
+	m = press(m, runes("R"))
+	if texts := archivedTexts(t, m); len(texts) != 0 {
+		t.Fatalf("archive holds %q after restoring everything, want nothing", texts)
+	}
+	if len(m.tasks) != 3 || m.tasks[0].Text != "old" {
+		t.Fatalf("list holds %v, want all three back, oldest first", m.tasks)
+	}
+}
+
+func TestDeleteFromArchiveAsksFirst(t *testing.T) {
+	m := archivedModel(t)
+	m = press(m, runes("x"), runes("n"))
+	if texts := archivedTexts(t, m); len(texts) != 3 {
+		t.Fatalf("archive holds %q after saying no, want all three", texts)
+	}
+	m = press(m, runes("x"), runes("y"))
+	if want := []string{"old"}; !slices.Equal(archivedTexts(t, m), want) {
+		t.Fatalf("archive holds %q after deleting, want %q", archivedTexts(t, m), want)
+	}
+	if len(m.tasks) != 0 {
+		t.Fatalf("deleting from the archive restored %v", m.tasks)
+	}
+}
+
+func TestEmptyArchive(t *testing.T) {
+	m := archivedModel(t)
+	m = press(m, runes("X"), runes("y"))
+	if texts := archivedTexts(t, m); len(texts) != 0 {
+		t.Fatalf("archive holds %q after emptying it, want nothing", texts)
+	}
+	if n := m.doneSince(startOfDay(time.Now())); n != 0 {
+		t.Errorf("done today = %d after emptying the archive, want 0", n)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// attachStyle marks a task's attached files, and brokenStyle those with no
+// file behind them any more
+var (
+	attachStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("110"))
+	brokenStyle = metaStyle.Strikethrough(true)
+)
+
+// attachmentPath tidies a typed path into the one kept on the task: home
+// expanded and made absolute, so it opens from wherever the app starts
+func attachmentPath(path string) string {
+	path = expandHome(strings.Trim(strings.TrimSpace(path), `"'`))
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// fileExists reports whether something is at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// missingAttachments counts the task's attachments with no file behind them
+func (t task) missingAttachments() int {
+	n := 0
+	for _, path := range t.Attachments {
+		if !fileExists(path) {
+			n++
+		}
+	}
+	return n
+}
+
+// startAttach asks for a file to attach to the selected task
+func (m model) startAttach() (tea.Model, tea.Cmd) {
+	if _, ok := m.selected(); !ok {
+		return m, nil
+	}
+	return m.startPrompt(promptAttach, "Attach which file? (a path already attached is taken off)", "")
+}
+
+// attachFile attaches the file at path to the selected task, or takes it off
+// when it's already attached. A file that isn't there can still be attached,
+// with a warning, as it may be on a drive that isn't mounted.
+func (m *model) attachFile(path string) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	path = attachmentPath(path)
+	m.checkpoint()
+	t := &m.tasks[i]
+	if n := slices.Index(t.Attachments, path); n >= 0 {
+		t.Attachments = slices.Delete(t.Attachments, n, n+1)
+		m.record("detach "+filepath.Base(path), t.Text)
+		m.notice = "Took off " + path
+		return
+	}
+	t.Attachments = append(t.Attachments, path)
+	m.record("attach "+filepath.Base(path), t.Text)
+	m.notice = "Attached " + path
+	if !fileExists(path) {
+		m.notice += ", though there's nothing there yet"
+	}
+}
+
+// openAttachments opens each of the selected task's attached files with the
+// system's default app for it, skipping any that have gone missing
+func (m *model) openAttachments() {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	t := m.tasks[i]
+	if len(t.Attachments) == 0 {
+		m.notice = "No files attached • l attaches one"
+		return
+	}
+	opened, missing := 0, 0
+	for _, path := range t.Attachments {
+		if !fileExists(path) {
+			missing++
+			continue
+		}
+		if err := openURL(path); err != nil {
+			m.notice = "Could not open " + path + ": " + err.Error()
+			return
+		}
+		opened++
+	}
+	switch {
+	case opened == 0:
+		m.notice = "Every attached file is missing"
+	case opened == 1 && missing == 0:
+		m.notice = "Opened " + filepath.Base(t.Attachments[0])
+	case missing == 0:
+		m.notice = fmt.Sprintf("Opened %d files", opened)
+	default:
+		m.notice = fmt.Sprintf("Opened %d files; %d missing", opened, missing)
+	}
+}
+
+// attachmentBadge renders the 📎 of a task with files attached, counting
+// them, or naming them when details are on. Missing files are greyed.
+func (m model) attachmentBadge(t task) string {
+	if !m.detailed {
+		style := attachStyle
+		if t.missingAttachments() > 0 {
+			style = brokenStyle
+		}
+		return style.Render(fmt.Sprintf("📎%d", len(t.Attachments)))
+	}
+	names := make([]string, len(t.Attachments))
+	for n, path := range t.Attachments {
+		if fileExists(path) {
+			names[n] = attachStyle.Render(filepath.Base(path))
+		} else {
+			names[n] = brokenStyle.Render(filepath.Base(path))
+		}
+	}
+	return attachStyle.Render("📎 ") + strings.Join(names, metaStyle.Render(", "))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// boostStyle marks a task boosted to the top, with the days it has left
+var boostStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+
+// defaultBoostDays is what the boost prompt offers for a task not boosted yet
+const defaultBoostDays = 3
+
+// boosted reports whether the task is still being kept at the top
+func (t task) boosted(now time.Time) bool {
+	return now.Before(t.Boosted)
+}
+
+// onTop reports whether the task goes above the rest of the list, pinned
+// for good or boosted for now
+func (t task) onTop(now time.Time) bool {
+	return t.Pinned || t.boosted(now)
+}
+
+// boostDaysLeft counts the days a boosted task stays at the top, today
+// included
+func (t task) boostDaysLeft(now time.Time) int {
+	return int(startOfDay(t.Boosted).Sub(startOfDay(now)).Hours()+12) / 24
+}
+
+// startBoost asks how long to keep the selected top-level task at the top
+func (m model) startBoost() (tea.Model, tea.Cmd) {
+	i, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+	t := m.tasks[i]
+	switch {
+	case t.Depth > 0:
+		m.notice = "Only top-level tasks can be boosted"
+		return m, nil
+	case t.Pinned:
+		m.notice = "Already pinned for good • ^ unpins it"
+		return m, nil
+	}
+	days := defaultBoostDays
+	if now := time.Now(); t.boosted(now) {
+		days = t.boostDaysLeft(now)
+	}
+	return m.startPrompt(promptBoost, "Boost to the top for how many days, or until when? (0 ends the boost)", strconv.Itoa(days))
+}
+
+// boostTask keeps the selected task at the top for the days the answer to
+// the boost prompt names: a number of days counting today, or the last day
+// as after @ (fri, 2w, 2024-06-30). Zero ends the boost.
+func (m *model) boostTask(answer string) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	var until time.Time
+	if days, err := strconv.Atoi(answer); err == nil && days >= 0 {
+		if days > 0 {
+			until = startOfDay(now).AddDate(0, 0, days)
+		}
+	} else if day, ok := parseDay(answer, now); ok && !day.Before(startOfDay(now)) {
+		until = startOfDay(day).AddDate(0, 0, 1)
+	} else {
+		m.notice = fmt.Sprintf("%q isn't a number of days or a day to come", answer)
+		return
+	}
+
+	t := &m.tasks[i]
+	if until.IsZero() && !t.boosted(now) {
+		m.notice = "It isn't boosted"
+		return
+	}
+	m.checkpoint()
+	t.Boosted = until
+	if until.IsZero() {
+		m.record("unboost", t.Text)
+		m.notice = "No longer boosted: " + truncateRunes(t.Text, 40)
+	} else {
+		m.record("boost", t.Text)
+		m.notice = fmt.Sprintf("Boosted to the top for %s, through %s", dayCount(t.boostDaysLeft(now)), m.formatDate(until.AddDate(0, 0, -1)))
+	}
+	m.moveCursorTo(i)
+}
+
+// boostBadge renders how long a boosted task has left at the top, spelled
+// out when details are on
+func (m model) boostBadge(t task) string {
+	left := t.boostDaysLeft(time.Now())
+	if m.detailed {
+		return boostStyle.Render(fmt.Sprintf("🚀 boosted through %s, %s left", m.formatDate(t.Boosted.AddDate(0, 0, -1)), dayCount(left)))
+	}
+	return boostStyle.Render(fmt.Sprintf("🚀 %dd", left))
+}
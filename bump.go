@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// bumpOverdue raises the priority of each unfinished task, in any list,
+// that has gone overdue since last checked by one level, once per due date
+// so it doesn't keep climbing. The raises are one change for undo, and
+// undoing them leaves the tasks counted as raised so they stay put.
+func (m *model) bumpOverdue(now time.Time) {
+	if !m.bumpOverdueOn {
+		return
+	}
+	var bumped []*task
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := range tasks {
+			t := &tasks[i]
+			if !t.overdue(now) || t.Bumped.Equal(t.Due) || t.Priority == priorityHigh {
+				continue
+			}
+			t.Bumped = t.Due
+			bumped = append(bumped, t)
+		}
+	}
+	n := len(bumped)
+	if n == 0 {
+		return
+	}
+	m.checkpoint()
+	for _, t := range bumped {
+		t.Priority++
+		m.record("bump to "+t.Priority.String(), t.Text)
+	}
+	notice := fmt.Sprintf("Raised the priority of %d overdue tasks (u to undo)", n)
+	if n == 1 {
+		notice = "Raised the priority of 1 overdue task (u to undo)"
+	}
+	if m.notice != "" {
+		notice = m.notice + "; " + notice
+	}
+	m.notice = notice
+}
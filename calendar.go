@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Calendar styles
+var (
+	calHeaderStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	calDayStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	calTodayStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Underline(true)
+	calSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("212")).Bold(true)
+)
+
+// startDatePicker opens the calendar for task i, starting on its due date or
+// today when it has none
+func (m model) startDatePicker(i int) (tea.Model, tea.Cmd) {
+	m.calTask = i
+	m.calDay = startOfDay(time.Now())
+	if due := m.tasks[i].Due; !due.IsZero() {
+		m.calDay = startOfDay(due)
+	}
+	m.state = pickingDate
+	return m, nil
+}
+
+// addMonths moves day by n months, keeping the day of the month where it
+// exists and clamping to the month's last day where it doesn't
+func addMonths(day time.Time, n int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), last)-1)
+}
+
+// updatePickingDate handles key input in the calendar
+func (m model) updatePickingDate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Leave the due date as it was
+	case "esc", "q":
+		m.state = browsing
+
+	case "left", "h":
+		m.calDay = m.calDay.AddDate(0, 0, -1)
+	case "right", "l":
+		m.calDay = m.calDay.AddDate(0, 0, 1)
+	case "up", "k":
+		m.calDay = m.calDay.AddDate(0, 0, -7)
+	case "down", "j":
+		m.calDay = m.calDay.AddDate(0, 0, 7)
+
+	// Page through months
+	case "pgup", "[":
+		m.calDay = addMonths(m.calDay, -1)
+	case "pgdown", "]":
+		m.calDay = addMonths(m.calDay, 1)
+
+	case "t":
+		m.calDay = startOfDay(time.Now())
+
+	// Set the highlighted day as the due date
+	case "enter":
+		m.checkpoint()
+		m.tasks[m.calTask].Due = keepClock(m.calDay, m.tasks[m.calTask].Due)
+		m.record("due "+m.formatDate(m.calDay), m.tasks[m.calTask].Text)
+		m.state = browsing
+
+	// Take the due date off entirely
+	case "x", "backspace":
+		if !m.tasks[m.calTask].Due.IsZero() {
+			m.checkpoint()
+			m.tasks[m.calTask].Due = time.Time{}
+			m.record("clear due", m.tasks[m.calTask].Text)
+		}
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// viewPickingDate renders a month grid around the highlighted day, weeks
+// starting on Monday
+func (m model) viewPickingDate() string {
+	s := titleStyle.Render("📅 Due date") + "\n\n"
+	s += normalStyle.Render(m.tasks[m.calTask].Text) + "\n\n"
+
+	first := time.Date(m.calDay.Year(), m.calDay.Month(), 1, 0, 0, 0, 0, m.calDay.Location())
+	today := startOfDay(time.Now())
+
+	s += "    " + lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%-20s", first.Format("January 2006"))) + "\n"
+	s += "    " + calHeaderStyle.Render("Mo Tu We Th Fr Sa Su") + "\n"
+
+	// Blank cells up to the first day's weekday, Monday counted as zero
+	offset := (int(first.Weekday()) + 6) % 7
+	cells := make([]string, offset)
+	for i := range cells {
+		cells[i] = "  "
+	}
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		style := calDayStyle
+		switch {
+		case day.Equal(m.calDay):
+			style = calSelectedStyle
+		case day.Equal(today):
+			style = calTodayStyle
+		}
+		cells = append(cells, style.Render(fmt.Sprintf("%2d", day.Day())))
+	}
+	for week := 0; week < len(cells); week += 7 {
+		s += "    " + strings.Join(cells[week:min(week+7, len(cells))], " ") + "\n"
+	}
+
+	s += "\n" + helpStyle.Render("←↓↑→: day/week • [/]: month • t: today • enter: set • x: clear • esc: cancel")
+	return s
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// carryModes are the settings carryOver takes: ask on the first launch of
+// a new day, carry without asking, or leave tasks where they are
+var carryModes = []string{"ask", "always", "off"}
+
+// carryable counts the unfinished tasks in every list due before today,
+// which the daily roll-up moves on to today
+func (m model) carryable(now time.Time) int {
+	n := 0
+	for _, l := range m.allLists() {
+		for _, t := range l.Tasks {
+			if t.overdue(now) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// startCarryOver offers to move the tasks left unfinished on earlier days
+// on to today, on the first launch of the day
+func (m model) startCarryOver(n int) model {
+	noun := "tasks"
+	if n == 1 {
+		noun = "task"
+	}
+	label := fmt.Sprintf("New day! Carry the %d unfinished %s due before today over to today? (y/n)", n, noun)
+	next, _ := m.startPrompt(promptCarryOver, label, "y")
+	return next.(model)
+}
+
+// answerCarryOver carries the tasks over if the answer to the roll-up
+// prompt is yes, or leaves them be
+func (m *model) answerCarryOver(answer string) {
+	switch answer {
+	case "y", "Y", "yes":
+		m.carryOver()
+	default:
+		m.notice = "Left them where they were • S reschedules them later"
+	}
+}
+
+// carryOver moves every unfinished task due before today, in every list,
+// on to today as one change for undo, counting each move on the task when
+// carryOverCount is set
+func (m *model) carryOver() {
+	now := time.Now()
+	m.checkpoint()
+	n := 0
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := range tasks {
+			t := &tasks[i]
+			if !t.overdue(now) {
+				continue
+			}
+			t.Due = startOfDay(now)
+			if m.carryCount {
+				t.Carried++
+			}
+			m.record("carry over", t.Text)
+			n++
+		}
+	}
+	notice := fmt.Sprintf("Carried %d tasks over to today (u to undo)", n)
+	if n == 1 {
+		notice = "Carried 1 task over to today (u to undo)"
+	}
+	if m.notice != "" {
+		notice = m.notice + "; " + notice
+	}
+	m.notice = notice
+}
+
+// rollUp starts the day as mode says, the first time the app is launched
+// on it, and records today as the last launch. Quick capture doesn't count
+// as a launch.
+func (m model) rollUp(mode string) model {
+	now := time.Now()
+	lastRun := m.lastRun
+	m.lastRun = now.Format(dueLayout)
+	if lastRun == m.lastRun {
+		return m
+	}
+	m.dirty = true // Remember today's start even if nothing else changes
+	n := m.carryable(now)
+	if lastRun == "" || n == 0 {
+		return m
+	}
+	switch mode {
+	case "always":
+		m.carryOver()
+	case "ask":
+		m = m.startCarryOver(n)
+	}
+	return m
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Boxed panel listing the most used keys next to the list
+var (
+	cheatSheetStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("99")).
+			Padding(0, 1)
+	cheatKeyStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+)
+
+// cheatSheetKeys are the keys shown in the panel, in the order drawn
+var cheatSheetKeys = [][2]string{
+	{"↑/↓", "move"},
+	{"n", "add"},
+	{"A", "capture to inbox"},
+	{"e", "edit"},
+	{"space", "cycle status"},
+	{"x", "delete"},
+	{"p", "priority"},
+	{"D", "due date"},
+	{"t", "stopwatch"},
+	{"> / <", "nest / unnest"},
+	{"M", "move to list"},
+	{"/", "filter"},
+	{"ctrl+s", "search all lists"},
+	{"T", "tags"},
+	{"L", "lists"},
+	{"u", "undo"},
+	{"?", "full help"},
+	{"|", "detail pane"},
+	{"K", "hide this"},
+}
+
+// minListWidth is the narrowest the list is squeezed to so the panel fits
+// beside it; narrower terminals get the panel beneath the list instead
+const minListWidth = 40
+
+// viewCheatSheet renders the key panel
+func (m model) viewCheatSheet() string {
+	lines := []string{titleStyle.UnsetMarginBottom().Render("Keys")}
+	for _, k := range cheatSheetKeys {
+		lines = append(lines, cheatKeyStyle.Render(k[0]+strings.Repeat(" ", max(6-lipgloss.Width(k[0]), 0)))+" "+taskStyle.Render(k[1]))
+	}
+	return cheatSheetStyle.Render(strings.Join(lines, "\n"))
+}
+
+// cheatSheetBeside reports whether the panel fits to the right of the list
+func (m model) cheatSheetBeside(panel string) bool {
+	return m.width <= 0 || m.width-lipgloss.Width(panel)-1 >= minListWidth
+}
+
+// listArea returns the model as the list sees it while the key panel is
+// open: narrowed to leave room for the panel beside it, or shortened to
+// leave room beneath
+func (m model) listArea() model {
+	if !m.cheatSheet {
+		return m
+	}
+	panel := m.viewCheatSheet()
+	m.cheatSheet = false
+	if m.cheatSheetBeside(panel) {
+		if m.width > 0 {
+			m.width -= lipgloss.Width(panel) + 1
+		}
+	} else if m.height > 0 {
+		m.height = max(m.height-lipgloss.Height(panel), 1)
+	}
+	return m
+}
+
+// viewTasksWithCheatSheet renders the task list with the key panel beside
+// or beneath it, squeezing the list rather than drawing over it
+func (m model) viewTasksWithCheatSheet() string {
+	panel := m.viewCheatSheet()
+	area := m.listArea()
+	body := area.viewTasks()
+	if !m.cheatSheetBeside(panel) {
+		return body + "\n" + panel
+	}
+	if area.width > 0 {
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			lines[i] = ansi.Truncate(strings.TrimRight(line, " "), area.width, "…")
+		}
+		body = strings.Join(lines, "\n")
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, body, " ", panel)
+}
@@ -0,0 +1,773 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dateFormatPresets maps the named date formats to Go reference layouts
+var dateFormatPresets = map[string]string{
+	"iso":   "2006-01-02",
+	"short": "Jan 2",
+	"eu":    "02/01",
+	"us":    "01/02",
+}
+
+// defaultDateFormat is used when no valid date format is configured
+const defaultDateFormat = "iso"
+
+// defaultQuietHours keeps reminders quiet overnight
+const defaultQuietHours = "22:00-08:00"
+
+// defaultArchiveDelay is how many seconds a finished task shows as done
+// before archiveOnDone moves it to the archive
+const defaultArchiveDelay = 2
+
+// config holds user-tunable settings loaded from config.json
+type config struct {
+	// DataFile is where the lists are saved, ~/.todotui/tasks.json when
+	// empty. A leading ~/ expands to the home directory.
+	DataFile string `json:"dataFile"`
+
+	// CharLimit caps the length of a task, in characters. Zero or less
+	// removes the limit entirely.
+	CharLimit int `json:"charLimit"`
+
+	// LogFile, when set, receives a line for every change made to the
+	// task list. A leading ~/ expands to the home directory.
+	LogFile string `json:"logFile"`
+
+	// Hooks runs a shell command after each change of a kind, keyed by
+	// event: add, done or delete. The command gets the change as JSON on
+	// its standard input and runs in the background; a failure only shows
+	// a notice. Nothing runs unless it's set here.
+	Hooks map[string]string `json:"hooks"`
+
+	// DateFormat is a preset name (iso, short, eu, us) or a Go reference
+	// layout such as "Mon Jan 2"
+	DateFormat string `json:"dateFormat"`
+
+	// AutoAdvance moves the cursor to the next unfinished task after one
+	// is marked done
+	AutoAdvance bool `json:"autoAdvance"`
+
+	// WrapCursor moves the cursor from the last task to the first on down,
+	// and from the first to the last on up, rather than stopping
+	WrapCursor bool `json:"wrapCursor"`
+
+	// FocusMinutes makes each stopwatch run a focus session of that many
+	// minutes, after which the stopwatch stops and asks whether to move on
+	// to the next task, stay, or mark the task done. Zero runs on untimed.
+	FocusMinutes int `json:"focusMinutes"`
+
+	// IdleLockMinutes locks the app after that many minutes without a key
+	// press, so nothing changes until a key unlocks it. Zero never locks.
+	IdleLockMinutes int `json:"idleLockMinutes"`
+
+	// ShowHints shows the line of key hints under the list; b hides or
+	// shows it from then on
+	ShowHints bool `json:"showHints"`
+
+	// RelativeDue shows due dates relative to today ("tomorrow", "in 3
+	// days") rather than in DateFormat, until @ switches them
+	RelativeDue bool `json:"relativeDue"`
+
+	// ShowIDs starts with each task's ID at the end of its row, as ctrl+g
+	// toggles
+	ShowIDs bool `json:"showIDs"`
+
+	// ShowAge starts with how long ago each task was added at the end of its
+	// row, as ctrl+w toggles
+	ShowAge bool `json:"showAge"`
+
+	// Mouse lets a click select a task and the wheel move the cursor. The
+	// app then takes over the whole screen, to know where each row is
+	// drawn, and the terminal's own text selection needs shift held.
+	Mouse bool `json:"mouse"`
+
+	// KeepDrafts makes esc in the new-task field keep what was typed, to
+	// come back the next time the field opens, rather than throwing it away
+	KeepDrafts bool `json:"keepDrafts"`
+
+	// FrogOnStart opens with a banner suggesting the unfinished task to do
+	// first, the one with the highest priority, until f or esc
+	FrogOnStart bool `json:"frogOnStart"`
+
+	// DashboardOnStart opens on the overview of every list rather than
+	// straight into the list left open
+	DashboardOnStart bool `json:"dashboardOnStart"`
+
+	// CarryOver is what the first launch of a new day does with unfinished
+	// tasks due before it: ask (offer to move them on to today), always
+	// (move them without asking) or off. CarryOverCount counts the moves
+	// on each task.
+	CarryOver      string `json:"carryOver"`
+	CarryOverCount bool   `json:"carryOverCount"`
+
+	// FlashAdded briefly highlights each new task's row as it's added, with
+	// a notice, to show it landed
+	FlashAdded bool `json:"flashAdded"`
+
+	// ProgressDone marks a task done once + takes its progress to 100%
+	ProgressDone bool `json:"progressDone"`
+
+	// ArchiveOnDone moves a task to the archive, subtasks and all, once
+	// it's marked done, rather than leaving it in the list. It shows as
+	// done for ArchiveDelaySeconds first; zero archives it at once.
+	ArchiveOnDone       bool `json:"archiveOnDone"`
+	ArchiveDelaySeconds int  `json:"archiveDelaySeconds"`
+
+	// EnterAction is what enter does in browse mode: done (toggle done),
+	// detail (show the task's details), edit, or url (open its first link)
+	EnterAction string `json:"enterAction"`
+
+	// FilteredAdd is what adding a task the filters hide does: notice (say
+	// so and leave the filters on) or clear (lift them to show it)
+	FilteredAdd string `json:"filteredAdd"`
+
+	// SortTieBreak settles tasks , sorts as equal: created (oldest first),
+	// name, or none to leave them in the order they had. Tasks still equal
+	// after it keep their order.
+	SortTieBreak string `json:"sortTieBreak"`
+
+	// DefaultPriority is the priority new tasks start with: none, low,
+	// medium or high. A !0 to !3 in the task's text still wins.
+	DefaultPriority string `json:"defaultPriority"`
+
+	// AgeWarnDays is how old an unfinished task gets before its color
+	// starts shifting toward a warning, and AgeAlertDays how old before it
+	// turns the alert color. Zero or less for AgeWarnDays turns fading off.
+	AgeWarnDays  int `json:"ageWarnDays"`
+	AgeAlertDays int `json:"ageAlertDays"`
+
+	// TrimSpace, CollapseSpaces and Capitalize tidy a task's text as it's
+	// entered: trimming the ends, squeezing runs of spaces down to one,
+	// and upper-casing the first letter
+	TrimSpace      bool `json:"trimSpace"`
+	CollapseSpaces bool `json:"collapseSpaces"`
+	Capitalize     bool `json:"capitalize"`
+
+	// WrapTasks wraps tasks too long for the terminal onto more lines
+	// instead of truncating them with an ellipsis
+	WrapTasks bool `json:"wrapTasks"`
+
+	// DueReminders shows, once a day, how many tasks are due or overdue,
+	// and DueBell rings the terminal bell with it. QuietHours (HH:MM-HH:MM,
+	// empty for none) holds reminders back overnight until the window ends.
+	DueReminders bool   `json:"dueReminders"`
+	DueBell      bool   `json:"dueBell"`
+	QuietHours   string `json:"quietHours"`
+
+	// ReminderLeadDays reminds about a task that many days before it's due,
+	// once per due date, unless the task sets a lead of its own. Zero only
+	// reminds on the day.
+	ReminderLeadDays int `json:"reminderLeadDays"`
+
+	// BumpOverdue raises an unfinished task's priority one level when it
+	// goes overdue, once for each due date it passes
+	BumpOverdue bool `json:"bumpOverdue"`
+
+	// DailyGoal and WeeklyGoal are how many tasks to finish a day and a
+	// week (from Monday), tracked in the status bar. Zero for no goal.
+	DailyGoal  int `json:"dailyGoal"`
+	WeeklyGoal int `json:"weeklyGoal"`
+
+	// SnoozeHour is the hour of the morning, 0 to 23, a task snoozed with
+	// ctrl+n comes back
+	SnoozeHour int `json:"snoozeHour"`
+
+	// SinkFuture starts with tasks due more than SinkAfterDays from today
+	// shown below the rest, as F toggles
+	SinkFuture    bool `json:"sinkFuture"`
+	SinkAfterDays int  `json:"sinkAfterDays"`
+
+	// Backups is how many earlier versions of the tasks file to keep, as
+	// tasks.json.1 (the newest) to tasks.json.N. Zero keeps none.
+	Backups int `json:"backups"`
+
+	// ZebraRows shades every other row, which some themes make hard to read
+	ZebraRows bool `json:"zebraRows"`
+
+	// StrikeDone strikes through the text of finished tasks
+	StrikeDone bool `json:"strikeDone"`
+
+	// Checkboxes puts a [x] before the text of finished tasks and a [ ]
+	// before the rest, after their status marker
+	Checkboxes bool `json:"checkboxes"`
+
+	// PriorityColors draws unfinished high priority tasks in red and
+	// medium ones in yellow, as their badges are
+	PriorityColors bool `json:"priorityColors"`
+
+	// DueColors draws unfinished overdue tasks in red and those due today
+	// in yellow, as their due dates are
+	DueColors bool `json:"dueColors"`
+
+	// ConfirmDelete asks before x deletes a task; off deletes at once,
+	// leaving u to bring it back
+	ConfirmDelete bool `json:"confirmDelete"`
+
+	// WaitingInline keeps waiting tasks in the main view, dimmed, rather
+	// than only in the waiting view. Moving the cursor passes over them.
+	WaitingInline bool `json:"waitingInline"`
+
+	// FoldCompleted gathers finished tasks under a fold at the bottom of
+	// the list, closed to begin with; ctrl+o opens and closes it
+	FoldCompleted bool `json:"foldCompleted"`
+
+	// SplitPane shows the selected task's details beside the list once the
+	// terminal is wide enough, as | toggles; off keeps the list alone
+	SplitPane bool `json:"splitPane"`
+
+	// TagColors colors the tasks carrying a tag, keyed by tag name with or
+	// without its # and valued as for list colors (0-255 or #hex)
+	TagColors map[string]string `json:"tagColors"`
+
+	// ListCap warns in the status bar once a list holds more tasks than
+	// this, as a nudge to clear it out; zero never warns. ListCaps sets a
+	// cap of its own for lists by name, zero turning the warning off.
+	ListCap  int            `json:"listCap"`
+	ListCaps map[string]int `json:"listCaps"`
+
+	// QuickActions binds keys browse mode leaves free to one change each to
+	// the selected task, listed in a bar under the tasks
+	QuickActions []quickAction `json:"quickActions"`
+
+	// CSVColumns picks the columns a CSV export includes, and their order,
+	// from text, done, priority, due, tags, created and completed
+	CSVColumns []string `json:"csvColumns"`
+
+	// Icons replaces the markers drawn in the list, for instance with
+	// ASCII on terminals whose fonts lack the default symbols
+	Icons iconSet `json:"icons"`
+
+	// CursorStyle picks how the cursor row is marked: arrow (the cursor
+	// icon, → unless changed), triangle (▶), star (*), angle (>), or
+	// highlight, a background across the whole row with no glyph.
+	// CursorColor colors the glyph or the highlight, 0-255 or #hex.
+	CursorStyle string `json:"cursorStyle"`
+	CursorColor string `json:"cursorColor"`
+
+	// Theme picks the built-in palette to start with: dark or light, for
+	// terminals with a light background, as ctrl+b switches. Colors
+	// overrides any of its colors, whichever theme is in use.
+	Theme  string  `json:"theme"`
+	Colors palette `json:"colors"`
+}
+
+// iconSet holds the markers drawn in the list. An empty field uses the
+// default.
+type iconSet struct {
+	Cursor string `json:"cursor"` // Points at the selected row
+
+	// Status markers
+	Todo    string `json:"todo"`
+	Doing   string `json:"doing"`
+	Done    string `json:"done"`
+	Waiting string `json:"waiting"`
+
+	Priority string `json:"priority"` // Repeated once per priority level
+
+	// Fold markers on tasks with subtasks
+	Expanded  string `json:"expanded"`
+	Collapsed string `json:"collapsed"`
+
+	// Drawn after the text of a task with each status, such as " ✓"; none
+	// by default
+	TodoAfter    string `json:"todoAfter"`
+	DoingAfter   string `json:"doingAfter"`
+	DoneAfter    string `json:"doneAfter"`
+	WaitingAfter string `json:"waitingAfter"`
+}
+
+// defaultIcons returns the markers used when none are configured
+func defaultIcons() iconSet {
+	return iconSet{
+		Cursor:    "→",
+		Todo:      statusTodo.glyph(),
+		Doing:     statusDoing.glyph(),
+		Done:      statusDone.glyph(),
+		Waiting:   statusWaiting.glyph(),
+		Priority:  "!",
+		Expanded:  "▼",
+		Collapsed: "▶",
+	}
+}
+
+// status returns the marker for a task status
+func (i iconSet) status(s taskStatus) string {
+	switch s {
+	case statusDoing:
+		return i.Doing
+	case statusDone:
+		return i.Done
+	case statusWaiting:
+		return i.Waiting
+	default:
+		return i.Todo
+	}
+}
+
+// after returns the decoration drawn after the text of a task with status s
+func (i iconSet) after(s taskStatus) string {
+	switch s {
+	case statusDoing:
+		return i.DoingAfter
+	case statusDone:
+		return i.DoneAfter
+	case statusWaiting:
+		return i.WaitingAfter
+	default:
+		return i.TodoAfter
+	}
+}
+
+// markerWidth returns the width of the widest status marker, which every
+// marker is padded to so the text after them lines up
+func (i iconSet) markerWidth() int {
+	return max(lipgloss.Width(i.Todo), lipgloss.Width(i.Doing), lipgloss.Width(i.Done), lipgloss.Width(i.Waiting))
+}
+
+// defaultConfig returns the settings used when no config file is present
+func defaultConfig() config {
+	return config{
+		CharLimit:  100,
+		DateFormat: defaultDateFormat,
+
+		ShowHints: true,
+
+		StrikeDone:     true,
+		Checkboxes:     true,
+		SplitPane:      true,
+		ConfirmDelete:  true,
+		PriorityColors: true,
+		DueColors:      true,
+
+		EnterAction:     defaultEnterAction,
+		FilteredAdd:     "notice",
+		SortTieBreak:    defaultTieBreak,
+		DefaultPriority: "none",
+		CarryOver:       "ask",
+		CursorStyle:     "arrow",
+		Theme:           "dark",
+
+		SinkAfterDays:       7,
+		Backups:             3,
+		ArchiveDelaySeconds: defaultArchiveDelay,
+
+		DueReminders: true,
+		QuietHours:   defaultQuietHours,
+		SnoozeHour:   defaultSnoozeHour,
+
+		AgeWarnDays:  7,
+		AgeAlertDays: 30,
+
+		TrimSpace:      true,
+		CollapseSpaces: true,
+
+		CSVColumns: slices.Clone(csvColumns),
+
+		Icons: defaultIcons(),
+	}
+}
+
+// configDir returns the directory holding all of the app's files
+func configDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".todotui"
+	}
+	return filepath.Join(home, ".todotui")
+}
+
+// configPath returns the location of the config file
+func configPath() string {
+	return filepath.Join(configDir(), "config.json")
+}
+
+// loadConfig reads the config file, starting from the defaults so that any
+// key left out of the file keeps its default value. A missing file is not an
+// error; an unreadable one, or one that isn't JSON, returns the defaults
+// along with the error. Each key is read on its own, so an unknown key or
+// a value of the wrong type is left out with a warning and the rest still
+// apply.
+func loadConfig() (config, []string, error) {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(configPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil, nil
+	}
+	if err != nil {
+		return cfg, nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return defaultConfig(), nil, fmt.Errorf("parsing %s: %w", configPath(), err)
+	}
+	known := configKeys()
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var warnings, unknown []string
+	for _, key := range keys {
+		if !slices.ContainsFunc(known, func(k string) bool { return strings.EqualFold(k, key) }) {
+			unknown = append(unknown, strconv.Quote(key))
+			continue
+		}
+		one, _ := json.Marshal(map[string]json.RawMessage{key: raw[key]})
+		if err := json.Unmarshal(one, &cfg); err != nil {
+			warnings = append(warnings, configError(key, err))
+		}
+	}
+	if len(unknown) > 0 {
+		warnings = append(warnings, "ignoring unknown settings in config (a typo?): "+strings.Join(unknown, ", "))
+	}
+	cfg.LogFile = expandHome(cfg.LogFile)
+	cfg.DataFile = expandHome(cfg.DataFile)
+	return cfg, warnings, nil
+}
+
+// configKeys lists the keys the config file can set, as their JSON names
+func configKeys() []string {
+	t := reflect.TypeOf(config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// configError warns that the value for key was left out, saying what was
+// wrong with it in a few words
+func configError(key string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field != "" {
+			key = typeErr.Field // Such as icons.cursor
+		}
+		return fmt.Sprintf("ignoring %s in config: expected %s, not %s", key, jsonKind(typeErr.Type), typeErr.Value)
+	}
+	return fmt.Sprintf("ignoring %s in config: %v", key, err)
+}
+
+// jsonKind names the kind of JSON value a setting of type t takes
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// envPrefix starts the name of every environment variable read as a setting
+const envPrefix = "TODOTUI_"
+
+// applyEnv overrides settings with the environment variables that are set,
+// so the precedence is environment, then config file, then defaults.
+// getenv looks a variable up, as os.Getenv does. A value that can't be
+// parsed leaves the setting alone and returns a warning.
+func (c *config) applyEnv(getenv func(string) string) []string {
+	var warnings []string
+	str := func(name string, dst *string) {
+		if v := getenv(envPrefix + name); v != "" {
+			*dst = v
+		}
+	}
+	num := func(name string, dst *int) {
+		if v := getenv(envPrefix + name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("ignoring %s%s=%q, not a whole number", envPrefix, name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	toggle := func(name string, dst *bool) {
+		if v := getenv(envPrefix + name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("ignoring %s%s=%q, use true or false", envPrefix, name, v))
+				return
+			}
+			*dst = b
+		}
+	}
+
+	str("FILE", &c.DataFile)
+	str("LOG_FILE", &c.LogFile)
+	str("DATE_FORMAT", &c.DateFormat)
+	str("ENTER_ACTION", &c.EnterAction)
+	str("FILTERED_ADD", &c.FilteredAdd)
+	str("SORT_TIE_BREAK", &c.SortTieBreak)
+	str("DEFAULT_PRIORITY", &c.DefaultPriority)
+	str("CARRY_OVER", &c.CarryOver)
+	str("CURSOR_STYLE", &c.CursorStyle)
+	str("CURSOR_COLOR", &c.CursorColor)
+	str("THEME", &c.Theme)
+	str("QUIET_HOURS", &c.QuietHours)
+	num("SNOOZE_HOUR", &c.SnoozeHour)
+	num("DAILY_GOAL", &c.DailyGoal)
+	num("WEEKLY_GOAL", &c.WeeklyGoal)
+	toggle("BUMP_OVERDUE", &c.BumpOverdue)
+	num("CHAR_LIMIT", &c.CharLimit)
+	num("LIST_CAP", &c.ListCap)
+	num("SINK_AFTER_DAYS", &c.SinkAfterDays)
+	num("REMINDER_LEAD_DAYS", &c.ReminderLeadDays)
+	num("FOCUS_MINUTES", &c.FocusMinutes)
+	num("IDLE_LOCK_MINUTES", &c.IdleLockMinutes)
+	num("BACKUPS", &c.Backups)
+	num("ARCHIVE_DELAY_SECONDS", &c.ArchiveDelaySeconds)
+	toggle("AUTO_ADVANCE", &c.AutoAdvance)
+	toggle("WRAP_CURSOR", &c.WrapCursor)
+	toggle("PROGRESS_DONE", &c.ProgressDone)
+	toggle("ARCHIVE_ON_DONE", &c.ArchiveOnDone)
+	toggle("FLASH_ADDED", &c.FlashAdded)
+	toggle("KEEP_DRAFTS", &c.KeepDrafts)
+	toggle("SHOW_HINTS", &c.ShowHints)
+	toggle("SHOW_IDS", &c.ShowIDs)
+	toggle("SHOW_AGE", &c.ShowAge)
+	toggle("MOUSE", &c.Mouse)
+	toggle("RELATIVE_DUE", &c.RelativeDue)
+	toggle("FROG_ON_START", &c.FrogOnStart)
+	toggle("DASHBOARD_ON_START", &c.DashboardOnStart)
+	toggle("CARRY_OVER_COUNT", &c.CarryOverCount)
+	toggle("WRAP_TASKS", &c.WrapTasks)
+	toggle("ZEBRA_ROWS", &c.ZebraRows)
+	toggle("STRIKE_DONE", &c.StrikeDone)
+	toggle("CHECKBOXES", &c.Checkboxes)
+	toggle("PRIORITY_COLORS", &c.PriorityColors)
+	toggle("DUE_COLORS", &c.DueColors)
+	toggle("CONFIRM_DELETE", &c.ConfirmDelete)
+	toggle("SPLIT_PANE", &c.SplitPane)
+	toggle("WAITING_INLINE", &c.WaitingInline)
+	toggle("FOLD_COMPLETED", &c.FoldCompleted)
+	toggle("SINK_FUTURE", &c.SinkFuture)
+	toggle("DUE_REMINDERS", &c.DueReminders)
+	toggle("DUE_BELL", &c.DueBell)
+	c.DataFile, c.LogFile = expandHome(c.DataFile), expandHome(c.LogFile)
+	return warnings
+}
+
+// validate resets any setting that can't be used to its default,
+// returning a warning for each one it changed
+func (c *config) validate() []string {
+	var warnings []string
+	if _, ok := dateLayout(c.DateFormat); !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid dateFormat %q, using %s", c.DateFormat, defaultDateFormat))
+		c.DateFormat = defaultDateFormat
+	}
+	if _, ok := enterActions[c.EnterAction]; !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid enterAction %q, using %s", c.EnterAction, defaultEnterAction))
+		c.EnterAction = defaultEnterAction
+	}
+	if _, ok := tieBreaks[c.SortTieBreak]; !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid sortTieBreak %q, using %s", c.SortTieBreak, defaultTieBreak))
+		c.SortTieBreak = defaultTieBreak
+	}
+	if _, ok := priorityNamed(c.DefaultPriority); !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid defaultPriority %q, using none", c.DefaultPriority))
+		c.DefaultPriority = "none"
+	}
+	if !slices.Contains(filteredAddModes, c.FilteredAdd) {
+		warnings = append(warnings, fmt.Sprintf("invalid filteredAdd %q, using notice", c.FilteredAdd))
+		c.FilteredAdd = "notice"
+	}
+	if !slices.Contains(carryModes, c.CarryOver) {
+		warnings = append(warnings, fmt.Sprintf("invalid carryOver %q, using ask", c.CarryOver))
+		c.CarryOver = "ask"
+	}
+	if _, err := parseQuietHours(c.QuietHours); err != nil {
+		warnings = append(warnings, fmt.Sprintf("invalid quietHours: %v, using %s", err, defaultQuietHours))
+		c.QuietHours = defaultQuietHours
+	}
+	if c.SnoozeHour < 0 || c.SnoozeHour > 23 {
+		warnings = append(warnings, fmt.Sprintf("invalid snoozeHour %d, using %d", c.SnoozeHour, defaultSnoozeHour))
+		c.SnoozeHour = defaultSnoozeHour
+	}
+	if c.DailyGoal < 0 {
+		warnings = append(warnings, fmt.Sprintf("invalid dailyGoal %d, using no goal", c.DailyGoal))
+		c.DailyGoal = 0
+	}
+	if c.WeeklyGoal < 0 {
+		warnings = append(warnings, fmt.Sprintf("invalid weeklyGoal %d, using no goal", c.WeeklyGoal))
+		c.WeeklyGoal = 0
+	}
+	if c.ArchiveDelaySeconds < 0 {
+		warnings = append(warnings, fmt.Sprintf("invalid archiveDelaySeconds %d, using %d", c.ArchiveDelaySeconds, defaultArchiveDelay))
+		c.ArchiveDelaySeconds = defaultArchiveDelay
+	}
+	if c.AgeWarnDays > 0 && c.AgeAlertDays <= c.AgeWarnDays {
+		d := defaultConfig()
+		warnings = append(warnings, fmt.Sprintf("ageAlertDays (%d) must be later than ageWarnDays (%d), using %d and %d",
+			c.AgeAlertDays, c.AgeWarnDays, d.AgeWarnDays, d.AgeAlertDays))
+		c.AgeWarnDays, c.AgeAlertDays = d.AgeWarnDays, d.AgeAlertDays
+	}
+	colors := map[string]string{}
+	for tag, color := range c.TagColors {
+		name := strings.ToLower(strings.TrimPrefix(tag, "#"))
+		if !colorPattern.MatchString(color) || !tagPattern.MatchString("#"+name) {
+			warnings = append(warnings, fmt.Sprintf("ignoring tagColors %q: %q; use a tag name and 0-255 or #hex", tag, color))
+			continue
+		}
+		colors[name] = color
+	}
+	c.TagColors = colors
+	if c.ListCap < 0 {
+		warnings = append(warnings, fmt.Sprintf("invalid listCap %d, using 0", c.ListCap))
+		c.ListCap = 0
+	}
+	for name, limit := range c.ListCaps {
+		if limit < 0 {
+			warnings = append(warnings, fmt.Sprintf("ignoring listCaps %q: %d; use a number of tasks, or 0 for none", name, limit))
+			delete(c.ListCaps, name)
+		}
+	}
+	var bad []string
+	if c.CSVColumns, bad = checkCSVColumns(c.CSVColumns); len(bad) > 0 {
+		warnings = append(warnings, "csvColumns: "+strings.Join(bad, "; "))
+	}
+	c.QuickActions, bad = checkQuickActions(c.QuickActions)
+	warnings = append(warnings, bad...)
+	c.Hooks, bad = checkHooks(c.Hooks)
+	warnings = append(warnings, bad...)
+	warnings = append(warnings, c.Icons.validate()...)
+	if glyph, ok := cursorStyles[c.CursorStyle]; !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid cursorStyle %q, using arrow", c.CursorStyle))
+		c.CursorStyle = "arrow"
+	} else if glyph != "" {
+		c.Icons.Cursor = glyph
+	}
+	if c.CursorColor != "" && !colorPattern.MatchString(c.CursorColor) {
+		warnings = append(warnings, fmt.Sprintf("ignoring cursorColor %q; use 0-255 or #hex", c.CursorColor))
+		c.CursorColor = ""
+	}
+	if _, ok := themeNamed(c.Theme); !ok {
+		warnings = append(warnings, fmt.Sprintf("invalid theme %q, using dark", c.Theme))
+		c.Theme = "dark"
+	}
+	warnings = append(warnings, c.Colors.validate()...)
+	return warnings
+}
+
+// cursorStyles maps each cursorStyle to the glyph it puts before the cursor
+// row, empty where the cursor icon is left as it is
+var cursorStyles = map[string]string{
+	"arrow":     "",
+	"triangle":  "▶",
+	"star":      "*",
+	"angle":     ">",
+	"highlight": "",
+}
+
+// Widest an icon may be, in terminal cells. Status markers and the
+// decorations after a task's text may be wider, to fit the likes of "- [ ]".
+const (
+	maxIconWidth   = 4
+	maxStatusWidth = 8
+)
+
+// validate swaps any icon that's empty, or that can't be drawn in a single
+// line of the list, for its default, returning a warning for each one it
+// rejected
+func (i *iconSet) validate() []string {
+	var warnings []string
+	defaults := defaultIcons()
+	for _, icon := range []struct {
+		name     string
+		value    *string
+		fallback string
+		width    int
+	}{
+		{"cursor", &i.Cursor, defaults.Cursor, maxIconWidth},
+		{"todo", &i.Todo, defaults.Todo, maxStatusWidth},
+		{"doing", &i.Doing, defaults.Doing, maxStatusWidth},
+		{"done", &i.Done, defaults.Done, maxStatusWidth},
+		{"waiting", &i.Waiting, defaults.Waiting, maxStatusWidth},
+		{"priority", &i.Priority, defaults.Priority, maxIconWidth},
+		{"expanded", &i.Expanded, defaults.Expanded, maxIconWidth},
+		{"collapsed", &i.Collapsed, defaults.Collapsed, maxIconWidth},
+		{"todoAfter", &i.TodoAfter, "", maxStatusWidth},
+		{"doingAfter", &i.DoingAfter, "", maxStatusWidth},
+		{"doneAfter", &i.DoneAfter, "", maxStatusWidth},
+		{"waitingAfter", &i.WaitingAfter, "", maxStatusWidth},
+	} {
+		switch {
+		case *icon.value == "":
+			*icon.value = icon.fallback
+		case !printable(*icon.value, icon.width) && icon.fallback == "":
+			warnings = append(warnings, fmt.Sprintf("icon %s %q can't be displayed, leaving it out", icon.name, *icon.value))
+			*icon.value = ""
+		case !printable(*icon.value, icon.width):
+			warnings = append(warnings, fmt.Sprintf("icon %s %q can't be displayed, using %q", icon.name, *icon.value, icon.fallback))
+			*icon.value = icon.fallback
+		}
+	}
+	return warnings
+}
+
+// printable reports whether s is valid text made only of visible characters
+// and spaces, at most width terminal cells wide
+func printable(s string, width int) bool {
+	if !utf8.ValidString(s) || lipgloss.Width(s) > width {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) && !unicode.Is(unicode.Variation_Selector, r) && r != '\u200d' {
+			return false
+		}
+	}
+	return true
+}
+
+// dateLayout resolves a configured date format to a Go layout. A layout
+// without any date or time elements formats every date identically, so it
+// is reported as invalid.
+func dateLayout(format string) (string, bool) {
+	if layout, ok := dateFormatPresets[format]; ok {
+		return layout, true
+	}
+	probe := time.Date(1999, time.December, 31, 23, 59, 58, 0, time.UTC)
+	if format == "" || probe.Format(format) == format {
+		return dateFormatPresets[defaultDateFormat], false
+	}
+	return format, true
+}
+
+// expandHome replaces a leading ~/ in path with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
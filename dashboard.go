@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listStats counts what a list holds for the overview
+type listStats struct {
+	open, today, overdue, doneToday int
+}
+
+// add counts t into the stats as of now
+func (s *listStats) add(t task, now time.Time) {
+	switch {
+	case t.Status == statusDone:
+		if !t.Completed.IsZero() && startOfDay(t.Completed).Equal(startOfDay(now)) {
+			s.doneToday++
+		}
+		return
+	case t.dueToday(now):
+		s.today++
+	case t.overdue(now):
+		s.overdue++
+	}
+	s.open++
+}
+
+// overview counts each list's tasks, in list order, along with the totals
+// across every list
+func (m model) overview(now time.Time) (lists []listStats, total listStats) {
+	all := m.allLists()
+	lists = make([]listStats, len(all))
+	for i, l := range all {
+		for _, t := range l.Tasks {
+			lists[i].add(t, now)
+			total.add(t, now)
+		}
+	}
+	return lists, total
+}
+
+// updateViewingDashboard handles key input in the overview: enter opens the
+// highlighted list and g sets the completion goals
+func (m model) updateViewingDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "s":
+		m.state = browsing
+
+	case "up", "k":
+		if m.dashCursor > 0 {
+			m.dashCursor--
+		}
+
+	case "down", "j":
+		if m.dashCursor < len(m.lists)-1 {
+			m.dashCursor++
+		}
+
+	case "enter":
+		m.switchList(m.dashCursor)
+		m.state = browsing
+
+	case "g":
+		return m.startGoal()
+	}
+	return m, nil
+}
+
+// viewDashboard renders the totals across every list over a row for each
+// list, the open one highlighted to start with
+func (m model) viewDashboard() string {
+	lists, total := m.overview(time.Now())
+	s := titleStyle.Render("🏠 Overview") + "\n\n"
+
+	noun := "lists"
+	if len(m.lists) == 1 {
+		noun = "list"
+	}
+	summary := []string{normalStyle.Render(fmt.Sprintf("%d open across %d %s", total.open, len(m.lists), noun))}
+	if badges := m.statsBadges(total); badges != "" {
+		summary = append(summary, badges)
+	}
+	s += strings.Join(summary, metaStyle.Render(" • ")) + "\n"
+	if goals := m.goalProgress(time.Now()); len(goals) > 0 {
+		s += strings.Join(goals, metaStyle.Render(" • ")) + "\n"
+	}
+	s += "\n"
+
+	width := 0
+	for _, l := range m.lists {
+		width = max(width, lipgloss.Width(l.label()))
+	}
+	for i, l := range m.lists {
+		label := l.label()
+		line := label + strings.Repeat(" ", width-lipgloss.Width(label)) + fmt.Sprintf("  %3d open", lists[i].open)
+		if i == m.dashCursor {
+			s += cursorStyle.Render(m.icons.Cursor+" ") + l.style().Bold(true).Render(line) + " " + m.statsBadges(lists[i]) + "\n"
+		} else {
+			s += m.gutter() + l.style().Render(line) + " " + m.statsBadges(lists[i]) + "\n"
+		}
+	}
+	if m.notice != "" {
+		s += "\n" + noticeStyle.Render(m.notice) + "\n"
+	}
+	return s
+}
+
+// statsBadges renders what's due today, overdue and done today, leaving out
+// whatever is zero
+func (m model) statsBadges(st listStats) string {
+	var parts []string
+	if st.today > 0 {
+		parts = append(parts, badgeStyle.Render(fmt.Sprintf("%d due today", st.today)))
+	}
+	if st.overdue > 0 {
+		parts = append(parts, badgeStyle.Render(fmt.Sprintf("%d overdue", st.overdue)))
+	}
+	if st.doneToday > 0 {
+		parts = append(parts, doneStyle.Render(fmt.Sprintf("%d done today", st.doneToday)))
+	}
+	return strings.Join(parts, metaStyle.Render(" • "))
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPhraseWords is the most words an @ date written out in words runs to,
+// as in "@day after tomorrow"
+const maxPhraseWords = 3
+
+// parseWhenPhrase reads the @ date starting at words[0], which may run on
+// over the next few words ("@next friday", "@in 3 days"), returning it with
+// how many words it took. The longest reading wins.
+func parseWhenPhrase(words []string, now time.Time) (time.Time, int, bool) {
+	if len(words) == 0 || !strings.HasPrefix(words[0], "@") {
+		return time.Time{}, 0, false
+	}
+	// "@3pm" on its own is today at that time
+	if hour, minute, ok := parseClock(strings.TrimPrefix(words[0], "@")); ok {
+		return atClock(now, hour, minute), 1, true
+	}
+	for n := min(len(words), maxPhraseWords); n > 0; n-- {
+		if d, ok := parseDay(strings.Join(words[:n], " "), now); ok {
+			return withClock(d, words[n:], n)
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// withClock sets the time of day of d, an @ date that took used words, from
+// a time that follows it ("3pm" or "at 15:30"), adding the words that took
+func withClock(d time.Time, rest []string, used int) (time.Time, int, bool) {
+	skip := 0
+	if len(rest) > 1 && strings.ToLower(rest[0]) == "at" {
+		skip = 1
+	}
+	if len(rest) > skip {
+		if hour, minute, ok := parseClock(rest[skip]); ok {
+			return atClock(d, hour, minute), used + skip + 1, true
+		}
+	}
+	return d, used, true
+}
+
+// parseClock reads a time of day as 15:30, 3pm, 3:30pm or noon
+func parseClock(s string) (hour, minute int, ok bool) {
+	s = strings.ToLower(s)
+	if s == "noon" {
+		return 12, 0, true
+	}
+	half := ""
+	if rest, found := strings.CutSuffix(s, "am"); found {
+		s, half = rest, "am"
+	} else if rest, found := strings.CutSuffix(s, "pm"); found {
+		s, half = rest, "pm"
+	}
+	h, m, colon := strings.Cut(s, ":")
+	if !colon && half == "" {
+		return 0, 0, false // A bare number is a count or a day, not a time
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil || len(h) > 2 {
+		return 0, 0, false
+	}
+	if colon {
+		if minute, err = strconv.Atoi(m); err != nil || len(m) != 2 || minute > 59 {
+			return 0, 0, false
+		}
+	}
+	switch {
+	case half == "" && hour <= 23:
+		return hour, minute, true
+	case half != "" && hour >= 1 && hour <= 12:
+		hour %= 12
+		if half == "pm" {
+			hour += 12
+		}
+		return hour, minute, true
+	}
+	return 0, 0, false
+}
+
+// atClock returns day at the given time of day
+func atClock(day time.Time, hour, minute int) time.Time {
+	y, mo, d := day.Date()
+	return time.Date(y, mo, d, hour, minute, 0, 0, day.Location())
+}
+
+// hasClock reports whether a due date carries a time of day. Midnight
+// can't be told apart from the whole day, so it counts as the day.
+func hasClock(due time.Time) bool {
+	return !due.IsZero() && !due.Equal(startOfDay(due))
+}
+
+// keepClock moves the due date to day, keeping the time of day it had
+func keepClock(day, due time.Time) time.Time {
+	if !hasClock(due) {
+		return startOfDay(day)
+	}
+	return atClock(day, due.Hour(), due.Minute())
+}
+
+// parseDay reads a day relative to now in any form an @when token takes,
+// with or without the @, or written out in words: "next friday", "in 3
+// days", "a week", "end of month", "day after tomorrow" or "june 5", with
+// dashes standing in for spaces when it's typed as one word
+func parseDay(s string, now time.Time) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "@")
+	if d, ok := parseWhen("@"+s, now); ok {
+		return d, true
+	}
+	words := strings.Fields(strings.ReplaceAll(s, "-", " "))
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	today := startOfDay(now)
+	switch strings.Join(words, " ") {
+	case "day after tomorrow":
+		return today.AddDate(0, 0, 2), true
+	case "next week":
+		// The Monday after this one
+		return today.AddDate(0, 0, (7-int(today.Weekday()))%7+1), true
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), true
+	case "next year":
+		return time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, today.Location()), true
+	case "end of week", "eow":
+		return today.AddDate(0, 0, (7-int(today.Weekday()))%7), true
+	case "end of month", "eom":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location()), true
+	case "end of year", "eoy":
+		return time.Date(today.Year(), 12, 31, 0, 0, 0, 0, today.Location()), true
+	}
+
+	// "next fri" and "this friday" mean the coming one, as @fri does
+	if len(words) == 2 && (words[0] == "next" || words[0] == "this" || words[0] == "on") {
+		if _, ok := weekdayNamed(words[1]); ok {
+			return parseWhen("@"+words[1], now)
+		}
+	}
+
+	// "in 3 days", "in a week", or the same without "in"
+	if words[0] == "in" {
+		words = words[1:]
+	}
+	if len(words) == 2 {
+		if d, ok := addSpan(today, words[0], words[1]); ok {
+			return d, true
+		}
+	}
+
+	// "june 5" or "5 june", next year once this year's has gone by
+	if len(words) == 2 {
+		month, day := words[0], words[1]
+		if _, err := strconv.Atoi(strings.TrimRight(month, "stndrh")); err == nil {
+			month, day = day, month
+		}
+		if d, ok := monthDay(today, month, day); ok {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// weekdayNamed looks up a weekday by its name or first three letters
+func weekdayNamed(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// addSpan moves day on by a count ("3", "a", "an" or "one") of units
+// (days, weeks, months or years)
+func addSpan(day time.Time, count, unit string) (time.Time, bool) {
+	n, err := strconv.Atoi(count)
+	switch {
+	case count == "a" || count == "an" || count == "one":
+		n = 1
+	case err != nil || n < 0:
+		return time.Time{}, false
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "day":
+		return day.AddDate(0, 0, n), true
+	case "week":
+		return day.AddDate(0, 0, 7*n), true
+	case "month":
+		return addMonths(day, n), true
+	case "year":
+		return addMonths(day, 12*n), true
+	}
+	return time.Time{}, false
+}
+
+// monthDay reads a month name (or its first three letters) and a day of it
+// ("5" or "5th"), as the next time that date comes round from today
+func monthDay(today time.Time, month, day string) (time.Time, bool) {
+	n, err := strconv.Atoi(strings.TrimRight(day, "stndrh"))
+	if err != nil || n < 1 || n > 31 {
+		return time.Time{}, false
+	}
+	for mo := time.January; mo <= time.December; mo++ {
+		full := strings.ToLower(mo.String())
+		if month != full && month != full[:3] {
+			continue
+		}
+		d := time.Date(today.Year(), mo, n, 0, 0, 0, 0, today.Location())
+		if d.Month() != mo {
+			return time.Time{}, false // Such as june 31
+		}
+		if d.Before(today) {
+			d = d.AddDate(1, 0, 0)
+		}
+		return d, true
+	}
+	return time.Time{}, false
+}
+
+// dateWords start the @ words that are most likely meant as a date, along
+// with digits, weekdays and months; "@bob" is left alone as a mention
+var dateWords = []string{"next", "this", "in", "on", "end", "day", "eow", "eom", "eoy", "today", "tomorrow", "tmr"}
+
+// unreadDates lists the @ words and due: tokens left in a task's text after
+// parsing that look like due dates but couldn't be read as one
+func unreadDates(text string) []string {
+	var unread []string
+	for _, w := range strings.Fields(text) {
+		if looksLikeDate(w) {
+			unread = append(unread, w)
+		}
+	}
+	return unread
+}
+
+// looksLikeDate reports whether an @ word starts like a date would. Any
+// due: token counts, as one that was read has been taken out already.
+func looksLikeDate(w string) bool {
+	if strings.HasPrefix(strings.ToLower(w), "due:") {
+		return true
+	}
+	rest, ok := strings.CutPrefix(strings.ToLower(w), "@")
+	if !ok || rest == "" {
+		return false
+	}
+	if rest[0] == '+' || (rest[0] >= '0' && rest[0] <= '9') {
+		return true
+	}
+	lead, _, _ := strings.Cut(rest, "-")
+	if _, ok := weekdayNamed(lead); ok || slices.Contains(dateWords, lead) {
+		return true
+	}
+	for mo := time.January; mo <= time.December; mo++ {
+		if full := strings.ToLower(mo.String()); lead == full || lead == full[:3] {
+			return true
+		}
+	}
+	return false
+}
+
+// unreadNotice gently points out the words in text that weren't read as
+// a due date, or is empty when there are none
+func unreadNotice(text string) string {
+	unread := unreadDates(text)
+	if len(unread) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Couldn't read %s as a date, so no due date was set; try due:YYYY-MM-DD, @fri, @3d, @next week or @june 5", strings.Join(unread, ", "))
+}
+
+// formatDue renders a due date as the relative toggle says: in the
+// configured date format, or relative to today ("tomorrow", "in 3 days").
+// A time of day follows the day, which is named when it's today or
+// tomorrow either way, as in "today 14:00".
+func (m model) formatDue(due time.Time) string {
+	now := time.Now()
+	if !hasClock(due) {
+		if m.relativeDue {
+			return relativeDay(due, now)
+		}
+		return m.formatDate(due)
+	}
+	day, today := m.formatDate(due), startOfDay(now)
+	if d := startOfDay(due); m.relativeDue || d.Equal(today) || d.Equal(today.AddDate(0, 0, 1)) {
+		day = relativeDay(due, now)
+	}
+	return day + " " + due.Format("15:04")
+}
+
+// relativeDay describes day relative to now, in days while that's close,
+// then weeks, months and years
+func relativeDay(day, now time.Time) string {
+	days := int(math.Round(startOfDay(day).Sub(startOfDay(now)).Hours() / 24))
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	case -1:
+		return "yesterday"
+	}
+	n, unit := days, "day"
+	if n < 0 {
+		n = -n
+	}
+	switch {
+	case n >= 365:
+		n, unit = n/365, "year"
+	case n >= 60:
+		n, unit = n/30, "month"
+	case n >= 14:
+		n, unit = n/7, "week"
+	}
+	span := fmt.Sprintf("%d %ss", n, unit)
+	if n == 1 {
+		span = "1 " + unit
+	}
+	if days < 0 {
+		return span + " ago"
+	}
+	return "in " + span
+}
+
+// shortAge puts d, how long ago something happened, in the fewest letters:
+// "moments" under a minute, then "5m", "3h", "2d", "4w", "6mo" or "1y"
+func shortAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "moments"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	days := int(d.Hours() / 24)
+	switch {
+	case days < 14:
+		return fmt.Sprintf("%dd", days)
+	case days < 60:
+		return fmt.Sprintf("%dw", days/7)
+	case days < 365:
+		return fmt.Sprintf("%dmo", days/30)
+	}
+	return fmt.Sprintf("%dy", days/365)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions enter can be configured to take in browse mode
+const (
+	enterToggleDone = "done"
+	enterDetail     = "detail"
+	enterEdit       = "edit"
+	enterOpenURL    = "url"
+)
+
+// enterActions describes each enter action for the help screen
+var enterActions = map[string]string{
+	enterToggleDone: "Mark selected task done, or reopen it",
+	enterDetail:     "Show everything about selected task",
+	enterEdit:       "Edit selected task",
+	enterOpenURL:    "Open the first link in selected task",
+}
+
+// defaultEnterAction is used when no valid enter action is configured
+const defaultEnterAction = enterToggleDone
+
+// urlPattern finds links in a task's text
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// pressEnter carries out the configured enter action on task i
+func (m model) pressEnter(i int) (tea.Model, tea.Cmd) {
+	switch m.enterAction {
+	case enterDetail:
+		m.detailTask = i
+		m.state = viewingDetail
+	case enterEdit:
+		return m.startEdit(i)
+	case enterOpenURL:
+		url := urlPattern.FindString(m.tasks[i].Text)
+		if url == "" {
+			m.notice = "No link in this task"
+			break
+		}
+		if err := openURL(url); err != nil {
+			m.notice = "Could not open " + url + ": " + err.Error()
+			break
+		}
+		m.notice = "Opened " + url
+	default:
+		s := statusDone
+		if m.tasks[i].Status == statusDone {
+			s = statusTodo
+		}
+		m.setStatus(i, s)
+	}
+	return m, nil
+}
+
+// openURL hands url to the system's browser without waiting for it
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// updateViewingDetail handles key input in the detail view: e edits the
+// task, n its notes, 1-9 go to the tasks its notes refer to, and anything
+// else goes back to the list
+func (m model) updateViewingDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if k := msg.String(); len(k) == 1 && k >= "1" && k <= "9" {
+		m.followRef(int(k[0] - '0'))
+		return m, nil
+	}
+	switch msg.String() {
+	case "e":
+		m.state = browsing
+		return m.startEdit(m.detailTask)
+	case "n":
+		return m.startNotes(m.detailTask)
+	}
+	m.state = browsing
+	return m, nil
+}
+
+// viewDetail renders every field of one task on a screen of its own
+func (m model) viewDetail() string {
+	t := m.tasks[m.detailTask]
+	s := titleStyle.Render("📄 Task") + "\n\n"
+	s += "    " + m.statusMarker(t.Status) + " " + selectedStyle.Render(t.Text) + "\n\n"
+	for _, f := range append(m.detailFields(t), m.notesLines(t)...) {
+		s += normalStyle.Render(f) + "\n"
+	}
+	if m.notice != "" {
+		s += "\n" + noticeStyle.Render(m.notice) + "\n"
+	}
+	help := "e: edit • n: notes • any other key: back"
+	if len(noteRefs(t)) > 0 {
+		help = "e: edit • n: notes • 1-9: go to a linked task • any other key: back"
+	}
+	return s + "\n" + helpStyle.Render(help)
+}
+
+// detailsText lays a task out for pasting elsewhere: its text over each of
+// its fields, as the detail view lists them
+func (m model) detailsText(t task) string {
+	text := t.Text
+	if t.Emoji != "" {
+		text = t.Emoji + " " + text
+	}
+	lines := append([]string{text, ""}, m.detailFields(t)...)
+	return strings.Join(append(lines, m.notesLines(t)...), "\n") + "\n"
+}
+
+// copyDetails puts everything about the selected task on the system
+// clipboard as a block of text
+func (m *model) copyDetails() {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	if err := clipboard.WriteAll(m.detailsText(m.tasks[i])); err != nil {
+		m.notice = "Could not copy to clipboard: " + err.Error()
+		return
+	}
+	m.notice = "Copied the task's details to the clipboard"
+}
+
+// detailFields lists each of the task's fields that's set, one line apiece
+// with the name padded so the values line up
+func (m model) detailFields(t task) []string {
+	var fields []string
+	field := func(name, value string) {
+		if value != "" {
+			fields = append(fields, fmt.Sprintf("%-10s %s", name, value))
+		}
+	}
+	field("ID", t.ID)
+	field("Status", t.Status.String())
+	if t.Status == statusWaiting {
+		field("Waiting on", t.WaitingOn)
+	}
+	if t.Priority != priorityNone {
+		field("Priority", t.Priority.String())
+	}
+	if t.Progress > 0 {
+		field("Progress", fmt.Sprintf("%d%%", t.Progress))
+	}
+	if !t.Due.IsZero() {
+		field("Due", m.formatDue(t.Due))
+		if lead := m.leadFor(t); lead > 0 {
+			field("Reminder", dayCount(lead)+" before")
+		}
+	}
+	if t.Recur != recurNone {
+		field("Repeats", t.repeats())
+	}
+	if len(t.Tags) > 0 {
+		field("Tags", "#"+strings.Join(t.Tags, " #"))
+	}
+	for n, path := range t.Attachments {
+		name := ""
+		if n == 0 {
+			name = "Files"
+		}
+		if !fileExists(path) {
+			path += " (missing)"
+		}
+		field(name, path)
+	}
+	if !t.Created.IsZero() {
+		field("Created", m.formatDate(t.Created)+t.Created.Format(" 15:04"))
+	}
+	if !t.Completed.IsZero() {
+		field("Completed", m.formatDate(t.Completed)+t.Completed.Format(" 15:04"))
+	}
+	if t.TimeSpent > 0 || !t.TimerStarted.IsZero() {
+		field("Time", formatDuration(t.spent(time.Now())))
+	}
+	return fields
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doneEntry is a finished task in the done log, with the list it's in
+type doneEntry struct {
+	task task
+	list string
+}
+
+// startDoneLog asks which days the done log covers
+func (m model) startDoneLog() (tea.Model, tea.Cmd) {
+	return m.startPrompt(promptDoneLog, "Done log for the last how many days, a range like 2024-06-01..2024-06-30, or all:", "7")
+}
+
+// parseDoneRange reads the days the done log covers, as the start of the
+// first and the start of the day after the last, relative to now: a number
+// of days up to and including today, one YYYY-MM-DD day or a range of two
+// joined by "..", or "all". Zero times mean no limit.
+func parseDoneRange(answer string, now time.Time) (from, to time.Time, err error) {
+	today := startOfDay(now)
+	if strings.EqualFold(answer, "all") {
+		return time.Time{}, time.Time{}, nil
+	}
+	if days, err := strconv.Atoi(answer); err == nil {
+		if days < 1 {
+			return from, to, fmt.Errorf("%q isn't a number of days", answer)
+		}
+		return today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1), nil
+	}
+	first, last, ranged := strings.Cut(answer, "..")
+	if !ranged {
+		last = first
+	}
+	from, err1 := time.ParseInLocation(dueLayout, strings.TrimSpace(first), now.Location())
+	to, err2 := time.ParseInLocation(dueLayout, strings.TrimSpace(last), now.Location())
+	if err1 != nil || err2 != nil {
+		return from, to, fmt.Errorf("%q isn't a number of days, a date range or all", answer)
+	}
+	if to.Before(from) {
+		from, to = to, from
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// doneEntries collects the tasks in every list finished in [from, to),
+// oldest first
+func doneEntries(lists []taskList, from, to time.Time) []doneEntry {
+	var done []doneEntry
+	for _, l := range lists {
+		for _, t := range l.Tasks {
+			if t.Status != statusDone || t.Completed.IsZero() ||
+				t.Completed.Before(from) || !to.IsZero() && !t.Completed.Before(to) {
+				continue
+			}
+			done = append(done, doneEntry{task: t, list: l.label()})
+		}
+	}
+	slices.SortStableFunc(done, func(a, b doneEntry) int { return a.task.Completed.Compare(b.task.Completed) })
+	return done
+}
+
+// writeDoneLog writes done as Markdown, a heading for each day with the
+// tasks finished that day beneath it, and the total at the end
+func (m model) writeDoneLog(w io.Writer, done []doneEntry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Done log")
+	var day time.Time
+	var spent time.Duration
+	for _, d := range done {
+		if at := startOfDay(d.task.Completed); !at.Equal(day) {
+			day = at
+			fmt.Fprintf(bw, "\n## %s, %s\n\n", day.Format("Monday"), m.formatDate(day))
+		}
+		line := fmt.Sprintf("- %s %s (%s)", d.task.Completed.Format("15:04"), d.task.Text, d.list)
+		if d.task.TimeSpent > 0 {
+			line += " ⏱ " + formatDuration(d.task.TimeSpent)
+			spent += d.task.TimeSpent
+		}
+		fmt.Fprintln(bw, line)
+	}
+	noun := "tasks"
+	if len(done) == 1 {
+		noun = "task"
+	}
+	total := fmt.Sprintf("\n%d %s done", len(done), noun)
+	if spent > 0 {
+		total += ", " + formatDuration(spent) + " tracked"
+	}
+	fmt.Fprintln(bw, total)
+	return bw.Flush()
+}
+
+// exportDoneLog writes the tasks finished in the days the answer names, in
+// every list, to done-log.md next to the data file
+func (m *model) exportDoneLog(answer string) {
+	from, to, err := parseDoneRange(answer, time.Now())
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	done := doneEntries(m.allLists(), from, to)
+	if len(done) == 0 {
+		m.notice = "Nothing was finished then"
+		return
+	}
+	path := filepath.Join(filepath.Dir(m.dataFile), "done-log.md")
+	f, err := os.Create(path)
+	if err == nil {
+		err = m.writeDoneLog(f, done)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		m.notice = "Could not export: " + err.Error()
+		return
+	}
+	m.notice = fmt.Sprintf("Exported %d finished tasks to %s", len(done), path)
+	if len(done) == 1 {
+		m.notice = "Exported 1 finished task to " + path
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dupeKey normalizes a task's text for spotting duplicates: case and runs
+// of spaces don't count
+func dupeKey(t task) string {
+	return strings.ToLower(strings.Join(strings.Fields(t.Text), " "))
+}
+
+// dupeGroups finds the open list's tasks that share their text with an
+// earlier one, grouped under the first of them, which survives a merge.
+// Tasks with subtasks are left out, since merging would leave the subtasks
+// to choose between parents.
+func (m model) dupeGroups() [][]int {
+	first := map[string]int{} // Key to group
+	var groups [][]int
+	for i, t := range m.tasks {
+		if hasChildren(m.tasks, i) {
+			continue
+		}
+		key := dupeKey(t)
+		if g, ok := first[key]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		first[key] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return slices.DeleteFunc(groups, func(g []int) bool { return len(g) < 2 })
+}
+
+// startMergeDupes asks how to merge the open list's duplicate tasks, saying
+// how many there are
+func (m model) startMergeDupes() (tea.Model, tea.Cmd) {
+	groups := m.dupeGroups()
+	if len(groups) == 0 {
+		m.notice = "No duplicate tasks"
+		return m, nil
+	}
+	dupes := 0
+	for _, g := range groups {
+		dupes += len(g) - 1
+	}
+	label := fmt.Sprintf("Merge %d duplicates into %d tasks? d: done if any copy is • o: open if any copy is • n: cancel", dupes, len(groups))
+	return m.startPrompt(promptMergeDupes, label, "d")
+}
+
+// mergeDupes folds each duplicate into the first task with the same text:
+// tags and tracked time add up, the earliest creation and due dates, the
+// highest priority and the most progress win, and the survivor is done if
+// any copy is (doneWins) or else only if every copy is
+func (m *model) mergeDupes(doneWins bool) {
+	groups := m.dupeGroups()
+	if len(groups) == 0 {
+		return
+	}
+	m.checkpoint()
+	var drop, survivors []int
+	for _, g := range groups {
+		keep := &m.tasks[g[0]]
+		status, done := keep.Status, keep.Status == statusDone
+		for _, i := range g[1:] {
+			t := m.tasks[i]
+			for _, tag := range t.Tags {
+				if !keep.hasTag(tag) {
+					keep.Tags = append(keep.Tags, tag)
+				}
+			}
+			if !t.Created.IsZero() && (keep.Created.IsZero() || t.Created.Before(keep.Created)) {
+				keep.Created = t.Created
+			}
+			if !t.Due.IsZero() && (keep.Due.IsZero() || t.Due.Before(keep.Due)) {
+				keep.Due = t.Due
+			}
+			if t.Completed.After(keep.Completed) {
+				keep.Completed = t.Completed
+			}
+			keep.Priority = max(keep.Priority, t.Priority)
+			keep.Progress = max(keep.Progress, t.Progress)
+			keep.Pinned = keep.Pinned || t.Pinned
+			if keep.Emoji == "" {
+				keep.Emoji = t.Emoji
+			}
+			if t.Boosted.After(keep.Boosted) {
+				keep.Boosted = t.Boosted
+			}
+			keep.TimeSpent += t.TimeSpent
+			if keep.TimerStarted.IsZero() {
+				keep.TimerStarted = t.TimerStarted
+			}
+
+			// The first open copy's status stands in for the group's
+			if t.Status != statusDone {
+				if status == statusDone {
+					status, keep.WaitingOn = t.Status, t.WaitingOn
+				}
+			} else {
+				done = true
+			}
+			drop = append(drop, i)
+			m.record("merge duplicate", t.Text)
+		}
+		if doneWins && done {
+			status = statusDone
+		}
+		keep.Status = status
+		if status != statusDone {
+			keep.Completed = time.Time{}
+		}
+		survivors = append(survivors, g[0])
+	}
+
+	names := make([]string, 0, 3)
+	for _, i := range survivors[:min(len(survivors), cap(names))] {
+		names = append(names, fmt.Sprintf("%q", truncateRunes(m.tasks[i].Text, 20)))
+	}
+	if len(survivors) > len(names) {
+		names = append(names, fmt.Sprintf("%d more", len(survivors)-len(names)))
+	}
+	m.notice = fmt.Sprintf("Merged %d duplicates into %s", len(drop), strings.Join(names, ", "))
+
+	// Drop from the end so the indices still to go stay put
+	slices.Sort(drop)
+	at, _ := m.selected()
+	for n := len(drop) - 1; n >= 0; n-- {
+		m.tasks = slices.Delete(m.tasks, drop[n], drop[n]+1)
+		if drop[n] < at {
+			at--
+		}
+	}
+	m.moveCursorTo(at)
+}
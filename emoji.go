@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// emojiChoices are the emoji the picker offers, laid out emojiColumns to a
+// row
+var emojiChoices = []string{
+	"⭐", "🔥", "💡", "💜", "✅", "❓", "🚨", "🚧",
+	"📞", "📧", "💬", "📅", "🛒", "💰", "🏠", "🚗",
+	"💻", "🐛", "📚", "📝", "🎉", "🎁", "💪", "🌱",
+}
+
+// emojiColumns is how many emoji each row of the picker holds
+const emojiColumns = 8
+
+// emojiCellStyle and emojiSelectedStyle pad each emoji of the picker, the
+// highlighted one on a colored background
+var (
+	emojiCellStyle     = lipgloss.NewStyle().Padding(0, 1)
+	emojiSelectedStyle = emojiCellStyle.Background(lipgloss.Color("212"))
+)
+
+// startEmojiPicker opens the emoji grid for task i, starting on its emoji
+// if it has one of those offered
+func (m model) startEmojiPicker(i int) (tea.Model, tea.Cmd) {
+	m.emojiTask = i
+	m.emojiCursor = 0
+	for n, e := range emojiChoices {
+		if e == m.tasks[i].Emoji {
+			m.emojiCursor = n
+		}
+	}
+	m.state = pickingEmoji
+	return m, nil
+}
+
+// updatePickingEmoji handles key input in the emoji picker
+func (m model) updatePickingEmoji(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Leave the emoji as it was
+	case "esc", "q":
+		m.state = browsing
+
+	case "left", "h":
+		if m.emojiCursor > 0 {
+			m.emojiCursor--
+		}
+	case "right", "l":
+		if m.emojiCursor < len(emojiChoices)-1 {
+			m.emojiCursor++
+		}
+	case "up", "k":
+		if m.emojiCursor >= emojiColumns {
+			m.emojiCursor -= emojiColumns
+		}
+	case "down", "j":
+		if m.emojiCursor+emojiColumns < len(emojiChoices) {
+			m.emojiCursor += emojiColumns
+		}
+
+	// Put the highlighted emoji at the start of the task
+	case "enter":
+		t := &m.tasks[m.emojiTask]
+		if e := emojiChoices[m.emojiCursor]; t.Emoji != e {
+			m.checkpoint()
+			t.Emoji = e
+			m.record("emoji "+e, t.Text)
+		}
+		m.state = browsing
+
+	// Take the emoji off
+	case "x", "backspace":
+		if t := &m.tasks[m.emojiTask]; t.Emoji != "" {
+			m.checkpoint()
+			t.Emoji = ""
+			m.record("clear emoji", t.Text)
+		}
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// viewPickingEmoji renders the grid of emoji around the highlighted one
+func (m model) viewPickingEmoji() string {
+	t := m.tasks[m.emojiTask]
+	s := titleStyle.Render("😀 Emoji") + "\n\n"
+	s += normalStyle.Render(t.Text) + "\n\n"
+	for row := 0; row < len(emojiChoices); row += emojiColumns {
+		cells := make([]string, 0, emojiColumns)
+		for n := row; n < min(row+emojiColumns, len(emojiChoices)); n++ {
+			style := emojiCellStyle
+			if n == m.emojiCursor {
+				style = emojiSelectedStyle
+			}
+			cells = append(cells, style.Render(emojiChoices[n]))
+		}
+		s += "  " + strings.Join(cells, "") + "\n"
+	}
+	clear := ""
+	if t.Emoji != "" {
+		clear = " • x: clear " + t.Emoji
+	}
+	return s + "\n" + helpStyle.Render("←↓↑→: choose • enter: set"+clear+" • esc: cancel")
+}
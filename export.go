@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// taskExporter writes a list's tasks out in one file format
+type taskExporter interface {
+	// exportTasks writes the list to w, returning how many tasks it included
+	exportTasks(w io.Writer, list taskList) (int, error)
+	// extension is the file extension for the format, without the dot
+	extension() string
+}
+
+// exportPath returns where a list is exported to: next to the data file,
+// named after the list
+func exportPath(dataFile string, list taskList, e taskExporter) string {
+	return filepath.Join(filepath.Dir(dataFile), fileSlug(list.Name)+"."+e.extension())
+}
+
+// writeExport exports list to path with e, returning how many tasks were written
+func writeExport(path string, e taskExporter, list taskList) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(f)
+	n, err := e.exportTasks(w, list)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// exportActive exports list, the open one or what it shows, with e next to
+// the data file, leaving a notice of the outcome. what describes the tasks
+// e includes.
+func (m *model) exportActive(e taskExporter, list taskList, what string) {
+	path := exportPath(m.dataFile, list, e)
+	n, err := writeExport(path, e, list)
+	if err != nil {
+		m.notice = "Could not export: " + err.Error()
+		return
+	}
+	m.notice = fmt.Sprintf("Exported %d %s to %s", n, what, path)
+}
+
+// shownList returns the open list with the tasks its filters let through,
+// in the order it shows them: pinned and boosted tasks first, snoozed and
+// far-off ones last. Subtasks of collapsed tasks are kept under their
+// parent, and finished tasks the completed fold holds come at the end, as
+// they do with it open.
+func (m model) shownList() taskList {
+	list := m.activeList()
+	open := m
+	open.doneOpen = true
+	open.tasks = slices.Clone(m.tasks)
+	for i := range open.tasks {
+		open.tasks[i].Collapsed = false
+	}
+	rows := open.visible()
+	list.Tasks = make([]task, len(rows))
+	for n, i := range rows {
+		list.Tasks[n] = m.tasks[i]
+	}
+	return list
+}
+
+// fileSlug turns a list name into a safe file name
+func fileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "tasks"
+	}
+	return slug
+}
+
+// icsExporter writes tasks with due dates as all-day iCalendar events, the
+// component calendar apps import most reliably. Tasks without a due date
+// are skipped.
+type icsExporter struct {
+	now time.Time // Stamp recorded on every event
+}
+
+func (icsExporter) extension() string { return "ics" }
+
+func (e icsExporter) exportTasks(w io.Writer, list taskList) (int, error) {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//todotui//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + icsEscape(list.Name),
+	}
+
+	n := 0
+	for _, t := range list.Tasks {
+		if t.Due.IsZero() {
+			continue
+		}
+		day := startOfDay(t.Due)
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+icsUID(list.Name, t),
+			"DTSTAMP:"+e.now.UTC().Format("20060102T150405Z"),
+		)
+		if hasClock(t.Due) {
+			// A moment rather than a whole day, in local time
+			lines = append(lines, "DTSTART:"+t.Due.Format("20060102T150405"))
+		} else {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+day.Format("20060102"),
+				"DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"),
+			)
+		}
+		lines = append(lines, "SUMMARY:"+icsEscape(t.Text))
+		if t.Priority != priorityNone {
+			lines = append(lines, "DESCRIPTION:"+icsEscape("Priority: "+t.Priority.String()))
+		}
+		if t.Status == statusDone {
+			lines = append(lines, "CATEGORIES:Completed")
+		}
+		lines = append(lines, "END:VEVENT")
+		n++
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	// iCalendar requires CRLF line endings and lines of at most 75 octets
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icsFold(line)+"\r\n"); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// icsUID derives a stable identifier for a task so that re-importing an
+// export updates events instead of duplicating them: its ID, or for a task
+// yet to be given one, a hash of what it says
+func icsUID(listName string, t task) string {
+	if t.ID != "" {
+		return t.ID + "@todotui"
+	}
+	sum := sha1.Sum([]byte(listName + "\x00" + t.Text + "\x00" + t.Due.Format(dueLayout)))
+	return fmt.Sprintf("%x@todotui", sum[:8])
+}
+
+// icsEscape escapes text for use in an iCalendar property value
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold splits a content line into 75-octet pieces, continuing each piece
+// with a leading space and never splitting a multibyte character
+func icsFold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// csvColumns are the columns a CSV export can include, in their default order
+var csvColumns = []string{"text", "done", "priority", "due", "tags", "created", "completed", "id"}
+
+// checkCSVColumns drops the names in columns that aren't CSV columns,
+// returning the rest along with a complaint about each one dropped. If none
+// are left, every column is used.
+func checkCSVColumns(columns []string) ([]string, []string) {
+	var kept, bad []string
+	for _, c := range columns {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if !slices.Contains(csvColumns, c) {
+			bad = append(bad, fmt.Sprintf("unknown column %q", c))
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if len(kept) == 0 {
+		bad = append(bad, "no columns chosen, using all of them")
+		kept = slices.Clone(csvColumns)
+	}
+	return kept, bad
+}
+
+// csvExporter writes a header row naming the columns, then one row per
+// task, every task included. Quoting of commas, quotes and line breaks is
+// left to encoding/csv.
+type csvExporter struct {
+	columns []string // Names from csvColumns, in the order written
+}
+
+func (csvExporter) extension() string { return "csv" }
+
+func (e csvExporter) exportTasks(w io.Writer, list taskList) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(e.columns); err != nil {
+		return 0, err
+	}
+	for n, t := range list.Tasks {
+		row := make([]string, len(e.columns))
+		for i, c := range e.columns {
+			row[i] = csvField(t, c)
+		}
+		if err := cw.Write(row); err != nil {
+			return n, err
+		}
+	}
+	cw.Flush()
+	return len(list.Tasks), cw.Error()
+}
+
+// csvField formats one column of a task for a spreadsheet. Dates are ISO
+// 8601 and left empty when unset.
+func csvField(t task, column string) string {
+	stamp := func(at time.Time) string {
+		if at.IsZero() {
+			return ""
+		}
+		return at.Format(time.RFC3339)
+	}
+	switch column {
+	case "text":
+		return t.Text
+	case "done":
+		return fmt.Sprint(t.Status == statusDone)
+	case "priority":
+		return t.Priority.String()
+	case "due":
+		if t.Due.IsZero() {
+			return ""
+		}
+		if hasClock(t.Due) {
+			return t.Due.Format(dueLayout + " 15:04")
+		}
+		return t.Due.Format(dueLayout)
+	case "tags":
+		return strings.Join(t.Tags, " ")
+	case "created":
+		return stamp(t.Created)
+	case "completed":
+		return stamp(t.Completed)
+	case "id":
+		return t.ID
+	}
+	return ""
+}
+
+// mdExporter writes a Markdown task list under a heading naming the list,
+// as GitHub and most notes apps render as checkboxes. Subtasks are indented
+// under their parent. An empty list writes just the heading.
+type mdExporter struct{}
+
+func (mdExporter) extension() string { return "md" }
+
+func (mdExporter) exportTasks(w io.Writer, list taskList) (int, error) {
+	if _, err := fmt.Fprintf(w, "# %s\n", list.Name); err != nil {
+		return 0, err
+	}
+	if len(list.Tasks) > 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return 0, err
+		}
+	}
+	for n, t := range list.Tasks {
+		box := "[ ]"
+		if t.Status == statusDone {
+			box = "[x]"
+		}
+		if _, err := fmt.Fprintf(w, "%s- %s %s\n", strings.Repeat("  ", t.Depth), box, t.Text); err != nil {
+			return n, err
+		}
+	}
+	return len(list.Tasks), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMarkdownExportFollowsTheListAsShown(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "plain", "parent", "child", "pinned")
+	m.tasks[2].Depth = 1
+	m.tasks[1].Collapsed = true
+	m.tasks[3].Pinned = true
+	m.moveCursorTo(0)
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlE})
+
+	raw, err := os.ReadFile(exportPath(m.dataFile, m.activeList(), mdExporter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# " + m.activeList().Name + "\n\n- [ ] pinned\n- [ ] plain\n- [ ] parent\n  - [ ] child\n"
+	if string(raw) != want {
+		t.Fatalf("exported\n%s\nwant\n%s", raw, want)
+	}
+}
+
+func TestMarkdownExportKeepsTasksInTheClosedFold(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.FoldCompleted = true
+	m := newTestModel(t, cfg)
+	m = addTasks(m, "finished", "open")
+	m.setStatus(0, statusDone)
+	m.moveCursorTo(0)
+	if m.doneOpen {
+		t.Fatal("the completed fold starts open")
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlE})
+
+	raw, err := os.ReadFile(exportPath(m.dataFile, m.activeList(), mdExporter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# " + m.activeList().Name + "\n\n- [ ] open\n- [x] finished\n"
+	if string(raw) != want {
+		t.Fatalf("exported\n%s\nwant\n%s", raw, want)
+	}
+}
+
+func TestMarkdownExportOfEmptyListIsJustTheHeading(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlE})
+	raw, err := os.ReadFile(exportPath(m.dataFile, m.activeList(), mdExporter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# " + m.activeList().Name + "\n"; string(raw) != want {
+		t.Fatalf("exported %q, want %q", raw, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchText is what the search and the filter match a query against: the
+// task's text and its #tags, lowercased
+func searchText(t task) string {
+	text := strings.ToLower(t.Text)
+	if len(t.Tags) > 0 {
+		text += " #" + strings.Join(t.Tags, " #")
+	}
+	return text
+}
+
+// matches reports whether t's text or tags contain query, already
+// lowercased. Every task matches an empty query.
+func (t task) matches(query string) bool {
+	return query == "" || strings.Contains(searchText(t), query)
+}
+
+// startFilter opens the filter over the open list, holding the query it
+// already has so it can be refined
+func (m model) startFilter() (tea.Model, tea.Cmd) {
+	m.filterInput.SetValue(m.filterQuery)
+	m.filterInput.CursorEnd()
+	m.filterInput.Focus()
+	m.state = filtering
+	return m, textinput.Blink
+}
+
+// setFilter shows only the tasks matching query as it's typed, keeping the
+// selected task selected while it still matches and otherwise moving to
+// the first match
+func (m *model) setFilter(query string) {
+	i, ok := m.selected()
+	m.filterQuery = strings.ToLower(strings.TrimSpace(query))
+	m.offset = 0
+	if ok && m.tasks[i].matches(m.filterQuery) {
+		m.moveCursorTo(i)
+		return
+	}
+	m.cursor = 0
+}
+
+// updateFiltering handles key input while typing a filter. The rows narrow
+// as the query changes; enter keeps them that way and esc shows them all
+// again.
+func (m model) updateFiltering(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.setFilter("")
+		m.state = browsing
+		m.filterInput.Blur()
+		return m, nil
+
+	case "enter":
+		m.state = browsing
+		m.filterInput.Blur()
+		return m, nil
+
+	case "up", "ctrl+p":
+		m.step(m.visible(), -1)
+		return m, nil
+
+	case "down", "ctrl+n":
+		m.step(m.visible(), 1)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.setFilter(m.filterInput.Value())
+	return m, cmd
+}
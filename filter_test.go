@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shownTexts returns the text of each row the open list shows, in order
+func shownTexts(m model) []string {
+	var texts []string
+	for _, i := range m.visible() {
+		texts = append(texts, m.tasks[i].Text)
+	}
+	return texts
+}
+
+func TestFilterNarrowsTheListAsYouType(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk", "call mum", "buy bread")
+	m = press(m, runes("/"), runes("BUY"))
+	if m.state != filtering {
+		t.Fatalf("state is %v after /, want filtering", m.state)
+	}
+	if got := shownTexts(m); strings.Join(got, ",") != "buy milk,buy bread" {
+		t.Fatalf("%q shows %q, want the two buy tasks", "BUY", got)
+	}
+	m = press(m, runes(" b"))
+	if got := shownTexts(m); strings.Join(got, ",") != "buy bread" {
+		t.Fatalf("%q shows %q, want only buy bread", "BUY b", got)
+	}
+}
+
+func TestFilterEnterKeepsItAndEscClearsIt(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk", "call mum", "buy bread")
+	m = press(m, runes("/"), runes("buy"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != browsing || len(m.visible()) != 2 {
+		t.Fatalf("after enter: state %v with %d rows, want browsing with 2", m.state, len(m.visible()))
+	}
+
+	// Keys work on the filtered rows: the second one is buy bread
+	m = press(m, runes("j"))
+	if i, ok := m.selected(); !ok || m.tasks[i].Text != "buy bread" {
+		t.Fatalf("second filtered row is %d, want buy bread", i)
+	}
+	m = press(m, runes("x"), runes("y"))
+	if got := shownTexts(m); strings.Join(got, ",") != "buy milk" {
+		t.Fatalf("after deleting the row, filter shows %q, want buy milk", got)
+	}
+	if len(m.tasks) != 2 {
+		t.Fatalf("%d tasks left, want 2", len(m.tasks))
+	}
+
+	// Reopening the filter starts from its query; esc shows everything
+	m = press(m, runes("/"))
+	if m.filterInput.Value() != "buy" {
+		t.Fatalf("filter reopened with %q, want %q", m.filterInput.Value(), "buy")
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != browsing || m.filterQuery != "" || len(m.visible()) != 2 {
+		t.Fatalf("after esc: state %v, query %q, %d rows; want browsing, none, 2", m.state, m.filterQuery, len(m.visible()))
+	}
+}
+
+func TestEscInTheListClearsTheFilterBeforeQuitting(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk", "call mum")
+	m = press(m, runes("/"), runes("mum"), tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.quitting || m.filterQuery != "" || len(m.visible()) != 2 {
+		t.Fatalf("esc with a filter: quitting %v, query %q, %d rows; want the filter cleared", m.quitting, m.filterQuery, len(m.visible()))
+	}
+}
+
+func TestStatusBarSaysWhatTheFilterMatches(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk")
+	plain := m.View()
+	m = press(m, runes("/"), runes("milk"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(m.View(), `matching "milk"`) || strings.Contains(plain, "matching") {
+		t.Fatal("status bar doesn't say what the list is filtered by")
+	}
+}
+
+func TestEachListKeepsItsOwnFilter(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk", "call mum")
+	m.lists = append(m.lists, taskList{Name: "Other", Tasks: []task{{ID: "ccc333", Text: "call the bank"}, {ID: "ddd444", Text: "pay rent"}}})
+	m = press(m, runes("/"), runes("milk"), tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.filterQuery != "" || len(m.visible()) != 2 {
+		t.Fatalf("the other list opened with filter %q and %d rows, want none and 2", m.filterQuery, len(m.visible()))
+	}
+	m = press(m, runes("/"), runes("rent"), tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if got := shownTexts(m); m.filterQuery != "milk" || strings.Join(got, ",") != "buy milk" {
+		t.Fatalf("back on the first list: filter %q showing %q, want milk showing buy milk", m.filterQuery, got)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if got := shownTexts(m); m.filterQuery != "rent" || strings.Join(got, ",") != "pay rent" {
+		t.Fatalf("back on the other list: filter %q showing %q, want rent showing pay rent", m.filterQuery, got)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerRow is one row of the list switcher: a list, or a folder heading
+// the lists filed under it
+type pickerRow struct {
+	list   int    // Index into m.lists, or -1 for a folder
+	folder string // Path of the folder, such as "work/clients", for folder rows
+	depth  int    // How many folders the row sits inside
+}
+
+// folderName returns the last part of a folder path
+func folderName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// parentFolder returns the folder a folder path sits in, or "" at the top
+func parentFolder(path string) string {
+	return path[:max(strings.LastIndex(path, "/"), 0)]
+}
+
+// cleanFolder tidies a folder path as typed: spaces around each part and
+// empty parts are dropped, so "/" or "" is no folder at all
+func cleanFolder(path string) string {
+	var parts []string
+	for _, p := range strings.Split(path, "/") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// inFolder reports whether a list filed under folder sits inside path,
+// directly or in a folder beneath it
+func inFolder(folder, path string) bool {
+	return folder == path || strings.HasPrefix(folder, path+"/")
+}
+
+// pickerRows lays out the list switcher as a tree. Lists and folders keep
+// the order the lists are in, each folder showing where its first list
+// would be, and the contents of folded folders are left out.
+func (m model) pickerRows() []pickerRow {
+	var rows []pickerRow
+	var walk func(path string, depth int)
+	walk = func(path string, depth int) {
+		seen := map[string]bool{}
+		for i, l := range m.lists {
+			if l.Folder == path {
+				rows = append(rows, pickerRow{list: i, depth: depth})
+				continue
+			}
+			if path != "" && !strings.HasPrefix(l.Folder, path+"/") {
+				continue
+			}
+			rest := strings.TrimPrefix(l.Folder, path+"/")
+			if path == "" {
+				rest = l.Folder
+			}
+			child, _, _ := strings.Cut(rest, "/")
+			if path != "" {
+				child = path + "/" + child
+			}
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			rows = append(rows, pickerRow{list: -1, folder: child, depth: depth})
+			if !m.folded[child] {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk("", 0)
+	return rows
+}
+
+// pickerAt returns which of rows is highlighted: the folder being pointed
+// at, or the row of the highlighted list, or the folded folder it's in
+func (m model) pickerAt(rows []pickerRow) int {
+	find := func(match func(pickerRow) bool) int {
+		return slices.IndexFunc(rows, match)
+	}
+	if m.onFolder != "" {
+		return max(find(func(r pickerRow) bool { return r.list < 0 && r.folder == m.onFolder }), 0)
+	}
+	if at := find(func(r pickerRow) bool { return r.list == m.listCursor }); at >= 0 {
+		return at
+	}
+	for path := m.lists[m.listCursor].Folder; path != ""; path = parentFolder(path) {
+		if at := find(func(r pickerRow) bool { return r.list < 0 && r.folder == path }); at >= 0 {
+			return at
+		}
+	}
+	return 0
+}
+
+// movePicker moves the switcher's highlight dir rows up or down the tree
+func (m *model) movePicker(dir int) {
+	rows := m.pickerRows()
+	at := max(min(m.pickerAt(rows)+dir, len(rows)-1), 0)
+	if rows[at].list < 0 {
+		m.onFolder = rows[at].folder
+	} else {
+		m.onFolder, m.listCursor = "", rows[at].list
+	}
+}
+
+// foldFolder folds or unfolds the highlighted folder, hiding or showing
+// what's filed under it
+func (m *model) foldFolder(fold bool) {
+	if m.onFolder == "" {
+		return
+	}
+	if m.folded == nil {
+		m.folded = map[string]bool{}
+	}
+	if fold {
+		m.folded[m.onFolder] = true
+	} else {
+		delete(m.folded, m.onFolder)
+	}
+	m.dirty = true
+}
+
+// foldedFolders lists the folded folders for saving, in a stable order
+func (m model) foldedFolders() []string {
+	var folded []string
+	for path := range m.folded {
+		folded = append(folded, path)
+	}
+	slices.Sort(folded)
+	return folded
+}
+
+// startFiling asks which folder the highlighted list goes in
+func (m model) startFiling() (tea.Model, tea.Cmd) {
+	return m.startPrompt(promptFileList, "Folder (work/clients for one inside another, / for none):", m.lists[m.listCursor].Folder)
+}
+
+// fileList puts the highlighted list in folder, or takes it out of every
+// folder when folder is empty
+func (m *model) fileList(folder string) {
+	folder = cleanFolder(folder)
+	m.checkpoint()
+	l := &m.lists[m.listCursor]
+	l.Folder = folder
+	m.describeChange(fmt.Sprintf("filing %s", l.label()))
+	if folder == "" {
+		m.notice = l.label() + " is no longer in a folder"
+		return
+	}
+	// Unfold the way to it so it stays in sight
+	for path := folder; path != ""; path = parentFolder(path) {
+		delete(m.folded, path)
+	}
+	m.notice = fmt.Sprintf("Filed %s under 📁 %s", l.label(), folder)
+}
+
+// renameFolder renames the highlighted folder, moving the lists and folders
+// inside it along
+func (m *model) renameFolder(name string) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), "/", "-")
+	old := m.onFolder
+	path := name
+	if parent := parentFolder(old); parent != "" {
+		path = parent + "/" + name
+	}
+	if name == "" || path == old {
+		return
+	}
+	m.checkpoint()
+	m.describeChange(fmt.Sprintf("renaming 📁 %s", old))
+	for i, l := range m.lists {
+		if inFolder(l.Folder, old) {
+			m.lists[i].Folder = path + strings.TrimPrefix(l.Folder, old)
+		}
+	}
+	for f := range m.folded {
+		if inFolder(f, old) {
+			delete(m.folded, f)
+			m.folded[path+strings.TrimPrefix(f, old)] = true
+		}
+	}
+	m.onFolder = path
+}
+
+// viewFolderRow renders a folder heading in the list switcher
+func (m model) viewFolderRow(row pickerRow, highlighted bool) string {
+	lists := 0
+	for _, l := range m.lists {
+		if inFolder(l.Folder, row.folder) {
+			lists++
+		}
+	}
+	fold := m.icons.Expanded
+	if m.folded[row.folder] {
+		fold = m.icons.Collapsed
+	}
+	noun := "lists"
+	if lists == 1 {
+		noun = "list"
+	}
+	line := fmt.Sprintf("%s 📁 %s (%d %s)", fold, folderName(row.folder), lists, noun)
+	indent := strings.Repeat("  ", row.depth)
+	if highlighted {
+		return cursorStyle.Render(m.icons.Cursor+" ") + indent + foldStyle.Bold(true).Render(line)
+	}
+	return m.gutter() + indent + foldStyle.Render(line)
+}
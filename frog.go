@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// frogStyle is the banner suggesting which task to do first
+var frogStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("77")).Bold(true)
+
+// frog picks the task in the open list to do first: the unfinished one with
+// the highest priority, the soonest due among equals (dated before
+// undated), and the oldest after that. Waiting and snoozed tasks, and tasks
+// without a priority, are never picked.
+func (m model) frog() (int, bool) {
+	best := -1
+	now := time.Now()
+	for i, t := range m.tasks {
+		if t.Status == statusDone || t.Status == statusWaiting || t.Priority == priorityNone || t.snoozed(now) {
+			continue
+		}
+		if best < 0 || frogBefore(t, m.tasks[best]) {
+			best = i
+		}
+	}
+	return best, best >= 0
+}
+
+// frogBefore reports whether a should be done before b
+func frogBefore(a, b task) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if !a.Due.Equal(b.Due) {
+		return !a.Due.IsZero() && (b.Due.IsZero() || a.Due.Before(b.Due))
+	}
+	return !a.Created.IsZero() && a.Created.Before(b.Created)
+}
+
+// eatFrog goes to the task to do first and puts it in the notice
+func (m *model) eatFrog() {
+	m.frogBanner = false
+	i, ok := m.frog()
+	if !ok {
+		m.notice = "No unfinished task has a priority"
+		return
+	}
+	m.reveal(i)
+	m.moveCursorTo(i)
+	m.notice = "🐸 Eat the frog: " + truncateRunes(m.tasks[i].Text, 50)
+}
+
+// viewFrogBanner renders the suggestion shown under the title until it's
+// taken up or dismissed, or nothing if there's no task to suggest
+func (m model) viewFrogBanner() string {
+	i, ok := m.frog()
+	if !m.frogBanner || !ok {
+		return ""
+	}
+	t := m.tasks[i]
+	line := frogStyle.Render("🐸 Eat the frog: "+truncateRunes(t.Text, 50)) + " " + m.priorityBadge(t.Priority)
+	if !t.Due.IsZero() {
+		line += " " + metaStyle.Render("📅 "+m.formatDue(t.Due))
+		if t.overdue(time.Now()) {
+			line += " " + badgeStyle.Render("overdue")
+		}
+	}
+	return "  " + line + "  " + metaStyle.Render("f: go to it • esc: dismiss") + "\n\n"
+}
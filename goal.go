@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// goalStyle shows progress toward a completion goal, and goalMetStyle one
+// that's been reached
+var (
+	goalStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+	goalMetStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
+)
+
+// startOfWeek returns midnight on the Monday of now's week, as the calendar
+// lays weeks out
+func startOfWeek(now time.Time) time.Time {
+	today := startOfDay(now)
+	return today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+}
+
+// doneSince counts the tasks finished since from, in every list and in the
+// archive. An archived task that's back in a list, as undo leaves it, only
+// counts once.
+func (m model) doneSince(from time.Time) int {
+	lists := m.allLists()
+	n := len(doneEntries(lists, from, time.Time{}))
+	var archived []doneStamp
+	for _, d := range m.archivedDone {
+		if !d.at.Before(from) {
+			archived = append(archived, d)
+		}
+	}
+	if len(archived) == 0 {
+		return n
+	}
+	listed := map[string]bool{}
+	for _, l := range lists {
+		for _, t := range l.Tasks {
+			listed[t.ID] = true
+		}
+	}
+	for _, d := range archived {
+		if d.id == "" || !listed[d.id] {
+			n++
+		}
+	}
+	return n
+}
+
+// goalProgress renders progress toward each goal that's set, as in "3/5
+// today", celebrating those that have been met
+func (m model) goalProgress(now time.Time) []string {
+	var parts []string
+	track := func(goal int, from time.Time, when string) {
+		if goal <= 0 {
+			return
+		}
+		done := m.doneSince(from)
+		if done >= goal {
+			parts = append(parts, goalMetStyle.Render(fmt.Sprintf("🎉 %d/%d %s", done, goal, when)))
+		} else {
+			parts = append(parts, goalStyle.Render(fmt.Sprintf("🎯 %d/%d %s", done, goal, when)))
+		}
+	}
+	track(m.dailyGoal, startOfDay(now), "today")
+	track(m.weeklyGoal, startOfWeek(now), "this week")
+	return parts
+}
+
+// goalTracker renders the goals' progress for the end of the status bar, or
+// nothing when no goal is set
+func (m model) goalTracker(now time.Time) string {
+	parts := m.goalProgress(now)
+	if len(parts) == 0 {
+		return ""
+	}
+	sep := statusBarStyle.Render(" • ")
+	return sep + strings.Join(parts, sep)
+}
+
+// startGoal asks for the daily and weekly completion goals
+func (m model) startGoal() (tea.Model, tea.Cmd) {
+	return m.startPrompt(promptGoal, "Tasks to finish a day, then a week (0 for no goal):", fmt.Sprintf("%d %d", m.dailyGoal, m.weeklyGoal))
+}
+
+// setGoal sets the completion goals from the answer to the goal prompt: a
+// daily goal, optionally followed by a weekly one
+func (m *model) setGoal(answer string) {
+	fields := strings.Fields(strings.ReplaceAll(answer, "/", " "))
+	goals := []int{m.dailyGoal, m.weeklyGoal}
+	if len(fields) > len(goals) {
+		m.notice = fmt.Sprintf("%q isn't a daily goal and a weekly one", answer)
+		return
+	}
+	for n, f := range fields {
+		goal, err := strconv.Atoi(f)
+		if err != nil || goal < 0 {
+			m.notice = fmt.Sprintf("%q isn't a number of tasks", f)
+			return
+		}
+		goals[n] = goal
+	}
+	m.dailyGoal, m.weeklyGoal = goals[0], goals[1]
+	m.dirty = true
+	var set []string
+	if m.dailyGoal > 0 {
+		set = append(set, taskCount(m.dailyGoal)+" a day")
+	}
+	if m.weeklyGoal > 0 {
+		set = append(set, taskCount(m.weeklyGoal)+" a week")
+	}
+	m.notice = "No completion goal"
+	if len(set) > 0 {
+		m.notice = "Goal: " + strings.Join(set, ", ")
+	}
+}
+
+// taskCount spells out n tasks, as in "1 task" or "5 tasks"
+func taskCount(n int) string {
+	if n == 1 {
+		return "1 task"
+	}
+	return fmt.Sprintf("%d tasks", n)
+}
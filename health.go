@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// repairData checks lists just loaded from the tasks file for values the
+// app would never write itself, fixing each one in place and returning a
+// description of every fix. Only values that can't mean anything are
+// changed; no task is dropped.
+func repairData(data *savedData) []string {
+	var fixes []string
+	fix := func(format string, args ...any) {
+		fixes = append(fixes, fmt.Sprintf(format, args...))
+	}
+
+	inbox, running := -1, false
+	for l := range data.Lists {
+		list := &data.Lists[l]
+		if strings.TrimSpace(list.Name) == "" {
+			list.Name = fmt.Sprintf("List %d", l+1)
+			fix("list %d had no name; named it %q", l+1, list.Name)
+		}
+		if list.Inbox {
+			if inbox >= 0 {
+				list.Inbox = false
+				fix("%q and %q were both the inbox; kept %q", data.Lists[inbox].Name, list.Name, data.Lists[inbox].Name)
+			} else {
+				inbox = l
+			}
+		}
+
+		for i := range list.Tasks {
+			t := &list.Tasks[i]
+			where := fmt.Sprintf("%q in %s", t.Text, list.Name)
+
+			// A subtask goes at most one level deeper than the task above
+			// it; anything more refers to a parent that isn't there
+			parent := -1
+			if i > 0 {
+				parent = list.Tasks[i-1].Depth
+			}
+			if t.Depth < 0 || t.Depth > parent+1 {
+				depth := max(min(t.Depth, parent+1), 0)
+				fix("%s was nested under a missing task; moved it to level %d", where, depth)
+				t.Depth = depth
+			}
+			if t.Pinned && t.Depth > 0 {
+				t.Pinned = false
+				fix("%s is a subtask, which can't be pinned; unpinned it", where)
+			}
+			if !t.Boosted.IsZero() && t.Depth > 0 {
+				t.Boosted = time.Time{}
+				fix("%s is a subtask, which can't be boosted; ended the boost", where)
+			}
+
+			if t.Status < statusTodo || t.Status > statusWaiting {
+				fix("%s had unknown status %d; made it todo", where, t.Status)
+				t.Status = statusTodo
+			}
+			if t.Priority < priorityNone || t.Priority > priorityHigh {
+				fix("%s had unknown priority %d; cleared it", where, t.Priority)
+				t.Priority = priorityNone
+			}
+			if t.Recur < recurNone || t.Recur > recurAfterDone {
+				fix("%s had unknown recurrence %d; stopped it repeating", where, t.Recur)
+				t.Recur = recurNone
+			}
+			if t.Recur == recurAfterDone && (t.RecurDays < 1 || t.RecurDays > maxRecurDays) {
+				fix("%s repeated %d days after done; made it 1", where, t.RecurDays)
+				t.RecurDays = 1
+			}
+			if t.Progress < 0 || t.Progress > 100 {
+				p := max(min(t.Progress, 100), 0)
+				fix("%s had progress %d%%; made it %d%%", where, t.Progress, p)
+				t.Progress = p
+			}
+			if t.Lead < 0 {
+				fix("%s had a negative reminder lead; cleared it", where)
+				t.Lead = 0
+			}
+			if t.TimeSpent < 0 {
+				fix("%s had negative time tracked; reset it", where)
+				t.TimeSpent = 0
+			}
+			if !t.TimerStarted.IsZero() {
+				if running {
+					fix("%s had a second stopwatch running; stopped it", where)
+					t.TimerStarted = time.Time{}
+				}
+				running = true
+			}
+
+			var tags []string
+			for _, tag := range t.Tags {
+				clean := strings.ToLower(strings.TrimPrefix(tag, "#"))
+				if tagPattern.MatchString("#"+clean) && !slices.Contains(tags, clean) {
+					tags = append(tags, clean)
+				}
+			}
+			if !slices.Equal(tags, t.Tags) {
+				fix("%s had malformed or repeated tags; tidied them", where)
+				t.Tags = tags
+			}
+		}
+	}
+	return fixes
+}
+
+// backupTasks copies the tasks file to <path>.orig before repairs are
+// saved over it, returning the copy's path
+func backupTasks(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	backup := path + ".orig"
+	return backup, writeFileAtomic(backup, raw)
+}
+
+// repairNotice sums up fixes for the startup notice, naming the first few.
+// kept says where the original file was copied to, if anywhere.
+func repairNotice(fixes []string, kept string) string {
+	const shown = 3
+	noun := "problems"
+	if len(fixes) == 1 {
+		noun = "problem"
+	}
+	s := fmt.Sprintf("Repaired %d %s in the tasks file (%s): %s", len(fixes), noun, kept, strings.Join(fixes[:min(len(fixes), shown)], "; "))
+	if len(fixes) > shown {
+		s += fmt.Sprintf("; and %d more", len(fixes)-shown)
+	}
+	return s
+}
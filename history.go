@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	maxHistory        = 500     // Entries kept in memory before the oldest drop off
+	maxHistoryFile    = 1 << 20 // Bytes the log file may reach before it rotates
+	historyScreenRows = 20      // Most recent entries shown on the history screen
+)
+
+// historyEntry records a single change made to the task list
+type historyEntry struct {
+	At     time.Time
+	Action string
+	Task   string
+}
+
+// String formats the entry as a single log line
+func (e historyEntry) String() string {
+	return fmt.Sprintf("%s  %-7s %s", e.At.Format("2006-01-02 15:04:05"), e.Action, e.Task)
+}
+
+// record appends an action to the in-memory history and, when configured,
+// to the log file, queueing any hook on it. Write failures surface as a notice rather than an error
+// since the log is purely informational.
+func (m *model) record(action, task string) {
+	entry := historyEntry{At: time.Now(), Action: action, Task: task}
+
+	m.history = append(m.history, entry)
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+
+	if m.logFile != "" {
+		if err := appendHistoryFile(m.logFile, entry); err != nil {
+			m.notice = "Could not write history log: " + err.Error()
+		}
+	}
+	m.queueHook(action, task)
+	m.describeChange(fmt.Sprintf("%s %q", action, truncateRunes(task, 30)))
+}
+
+// appendHistoryFile writes an entry to the log file, first rotating the
+// file to path.1 once it has grown past maxHistoryFile
+func appendHistoryFile(path string, entry historyEntry) error {
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxHistoryFile {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, entry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hookEvents are the changes a hook can run on, named as the history
+// records them
+var hookEvents = []string{"add", "done", "delete"}
+
+// hookTimeout is how long a hook may run before it's stopped
+const hookTimeout = 30 * time.Second
+
+// hookEvent is what a hook is told about the change it runs on, as JSON on
+// its standard input
+type hookEvent struct {
+	Event string    `json:"event"`
+	Task  string    `json:"task"`
+	List  string    `json:"list"`
+	At    time.Time `json:"at"`
+}
+
+// hookDoneMsg reports a hook that has finished, with why it failed if it did
+type hookDoneMsg struct {
+	event string
+	err   error
+}
+
+// checkHooks keeps the hooks set on known events, returning a warning for
+// each one left out
+func checkHooks(hooks map[string]string) (map[string]string, []string) {
+	kept := map[string]string{}
+	var warnings []string
+	for event, command := range hooks {
+		switch {
+		case !slices.Contains(hookEvents, event):
+			warnings = append(warnings, fmt.Sprintf("ignoring hook on %q; use %s", event, strings.Join(hookEvents, ", ")))
+		case strings.TrimSpace(command) != "":
+			kept[event] = command
+		}
+	}
+	return kept, warnings
+}
+
+// queueHook notes a change for the hook on its event, if there is one, to
+// run once the update that made it is done
+func (m *model) queueHook(action, text string) {
+	if _, ok := m.hooks[action]; !ok {
+		return
+	}
+	m.pendingHooks = append(m.pendingHooks, hookEvent{
+		Event: action,
+		Task:  text,
+		List:  m.lists[m.active].Name,
+		At:    time.Now(),
+	})
+}
+
+// flushHooks starts every queued hook in the background
+func (m *model) flushHooks() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, ev := range m.pendingHooks {
+		command, ev := m.hooks[ev.Event], ev
+		cmds = append(cmds, func() tea.Msg {
+			return hookDoneMsg{event: ev.Event, err: runHook(command, ev)}
+		})
+	}
+	m.pendingHooks = nil
+	return tea.Batch(cmds...)
+}
+
+// runHooksNow runs every queued hook and waits for them, for when the app
+// is about to exit, returning why any failed
+func (m *model) runHooksNow() []error {
+	var errs []error
+	for _, ev := range m.pendingHooks {
+		if err := runHook(m.hooks[ev.Event], ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook: %w", ev.Event, err))
+		}
+	}
+	m.pendingHooks = nil
+	return errs
+}
+
+// runHook runs command through the shell with ev as JSON on its standard
+// input, and as TODOTUI_EVENT, TODOTUI_TASK and TODOTUI_LIST in its
+// environment
+func runHook(command string, ev hookEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		envPrefix+"EVENT="+ev.Event,
+		envPrefix+"TASK="+ev.Task,
+		envPrefix+"LIST="+ev.List,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, truncateRunes(msg, 80))
+		}
+		return err
+	}
+	return nil
+}
+
+// hookFailed shows why a hook failed and, when there's a log file, writes
+// it there too
+func (m *model) hookFailed(event string, err error) {
+	m.notice = fmt.Sprintf("The %s hook failed: %v", event, err)
+	if m.logFile != "" {
+		appendHistoryFile(m.logFile, historyEntry{At: time.Now(), Action: "hook", Task: m.notice})
+	}
+}
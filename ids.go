@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// idLength is how many hex digits a task ID has: short enough to type, and
+// with plenty to spare for any one file's worth of tasks
+const idLength = 6
+
+// newTaskID returns a random ID for a task, one that isn't already in seen
+func newTaskID(seen map[string]bool) string {
+	b := make([]byte, idLength/2)
+	for {
+		rand.Read(b)
+		if id := hex.EncodeToString(b); !seen[id] {
+			return id
+		}
+	}
+}
+
+// assignIDs gives every task in each of lists an ID if it has none, or a
+// fresh one if an earlier task already has it, as a pasted copy would. IDs
+// are kept for good after that, so scripts and exports can refer to a task
+// by one. It reports whether any task changed.
+func assignIDs(lists ...[]task) bool {
+	seen := map[string]bool{}
+	changed := false
+	for _, tasks := range lists {
+		for i := range tasks {
+			t := &tasks[i]
+			if t.ID == "" || seen[t.ID] {
+				t.ID = newTaskID(seen)
+				changed = true
+			}
+			seen[t.ID] = true
+		}
+	}
+	return changed
+}
+
+// assignIDs gives IDs to the tasks in every list that need one, reporting
+// whether any did
+func (m *model) assignIDs() bool {
+	lists := make([][]task, len(m.lists))
+	for l := range m.lists {
+		lists[l] = m.lists[l].Tasks
+		if l == m.active {
+			lists[l] = m.tasks
+		}
+	}
+	return assignIDs(lists...)
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// taskwarriorLayout is the timestamp format used throughout Taskwarrior exports
+const taskwarriorLayout = "20060102T150405Z"
+
+// taskwarriorTask is the part of a `task export` record that maps onto a task
+type taskwarriorTask struct {
+	Description string   `json:"description"`
+	Status      string   `json:"status"`   // pending, completed, deleted, waiting or recurring
+	Priority    string   `json:"priority"` // H, M or L
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Due         string   `json:"due"`
+	Entry       string   `json:"entry"` // When the task was created
+	End         string   `json:"end"`   // When the task was completed or deleted
+}
+
+// importResult counts what an import did
+type importResult struct {
+	Imported int
+	Skipped  []string // Why each skipped record was left out
+}
+
+// checkboxPattern matches a checkbox, [ ] or [x], at the start of a line of
+// a text import, with or without a list bullet before it
+var checkboxPattern = regexp.MustCompile(`^(?:[-*]\s+)?\[([ xX])\]\s*`)
+
+// readTextTasks reads a plain text file holding a task on each non-empty
+// line. A leading [x] marks the task done and [ ] leaves it to do; the rest
+// of the line is read as when adding a task.
+func (m model) readTextTasks(path string) ([]task, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []task
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		done := false
+		if box := checkboxPattern.FindStringSubmatch(line); box != nil {
+			done = box[1] != " "
+			line = line[len(box[0]):]
+		}
+		t := m.parseInput(line, priorityNone)
+		if strings.TrimSpace(t.Text) == "" {
+			continue
+		}
+		if done {
+			t.Status = statusDone
+			t.Completed = time.Now()
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// importText adds tasks to the end of the open list, saying how many. When
+// imports replace the list and it has tasks to lose, it asks first.
+func (m *model) importText(source string, tasks []task) {
+	if m.importReplace && len(m.tasks) > 0 {
+		*m = m.startImportPreview(source, tasks, nil)
+		return
+	}
+	if len(tasks) > 0 {
+		m.checkpoint()
+	}
+	for _, t := range tasks {
+		m.addTask(t)
+	}
+	notice := fmt.Sprintf("Imported %d tasks from %s", len(tasks), source)
+	if m.notice != "" {
+		notice = m.notice + "; " + notice
+	}
+	m.notice = notice
+}
+
+// confirmReplace asks on the terminal, for tasks piped in to replace the n
+// tasks in list, whether to replace them, add to them instead, or stop,
+// reporting whether to go on and whether the list is replaced
+func confirmReplace(list string, n int) (proceed, replace bool, err error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false, false, fmt.Errorf("can't ask before replacing %s: %w", list, err)
+	}
+	defer tty.Close()
+	noun := "tasks"
+	if n == 1 {
+		noun = "task"
+	}
+	fmt.Fprintf(os.Stderr, "This replaces the %d %s in %s. Replace (r), merge instead (m), or cancel? ", n, noun, list)
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "r":
+		return true, true, nil
+	case "m":
+		return true, false, nil
+	}
+	return false, false, nil
+}
+
+// clearForCapture empties the list quick capture adds to, once the terminal
+// confirms it should be replaced, reporting whether to go on capturing
+func (m *model) clearForCapture() (bool, error) {
+	i := m.captureTarget()
+	tasks := &m.lists[i].Tasks
+	if i == m.active {
+		tasks = &m.tasks
+	}
+	if len(*tasks) == 0 {
+		return true, nil
+	}
+	proceed, replace, err := confirmReplace(m.lists[i].label(), len(*tasks))
+	if !replace {
+		return proceed, err
+	}
+	for _, t := range *tasks {
+		m.record("delete", t.Text)
+	}
+	*tasks = []task{}
+	return true, nil
+}
+
+// readTaskwarrior parses a Taskwarrior JSON export, either a single array as
+// written by `task export` or the one-object-per-line form older versions
+// produce
+func readTaskwarrior(path string) ([]taskwarriorTask, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []taskwarriorTask
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		return records, nil
+	}
+
+	for n, line := range bytes.Split(raw, []byte("\n")) {
+		line = bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+		if len(line) == 0 {
+			continue
+		}
+		var r taskwarriorTask
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("parsing %s line %d: %w", path, n+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// fromTaskwarrior converts Taskwarrior records into tasks. Deleted records
+// and ones without a description are skipped; the project becomes a tag and
+// anything else without a counterpart, such as annotations, is dropped.
+func fromTaskwarrior(records []taskwarriorTask) ([]task, importResult) {
+	var tasks []task
+	var result importResult
+	for n, r := range records {
+		text := strings.TrimSpace(r.Description)
+		switch {
+		case r.Status == "deleted":
+			result.Skipped = append(result.Skipped, fmt.Sprintf("#%d %q was deleted", n+1, text))
+			continue
+		case text == "":
+			result.Skipped = append(result.Skipped, fmt.Sprintf("#%d has no description", n+1))
+			continue
+		}
+
+		t := task{Text: text}
+		if r.Status == "completed" {
+			t.Status = statusDone
+			if end, err := time.Parse(taskwarriorLayout, r.End); err == nil {
+				t.Completed = end.Local()
+			}
+		}
+		switch r.Priority {
+		case "H":
+			t.Priority = priorityHigh
+		case "M":
+			t.Priority = priorityMedium
+		case "L":
+			t.Priority = priorityLow
+		}
+		if due, err := time.Parse(taskwarriorLayout, r.Due); err == nil {
+			t.Due = startOfDay(due.Local())
+		}
+		if entry, err := time.Parse(taskwarriorLayout, r.Entry); err == nil {
+			t.Created = entry.Local()
+		}
+		if r.Project != "" {
+			t.Tags = append(t.Tags, strings.ToLower(strings.ReplaceAll(r.Project, " ", "-")))
+		}
+		for _, tag := range r.Tags {
+			if tag = strings.ToLower(tag); !t.hasTag(tag) {
+				t.Tags = append(t.Tags, tag)
+			}
+		}
+		tasks = append(tasks, t)
+		result.Imported++
+	}
+	return tasks, result
+}
+
+// importChange says what importing one task would do to the open list
+type importChange int
+
+const (
+	importAdd    importChange = iota // No task with the same text yet
+	importUpdate                     // Matches a task it would change
+	importSame                       // Matches a task that already agrees with it
+)
+
+// importItem is one task of a planned import
+type importItem struct {
+	change importChange
+	task   task     // The task as it would be after the import
+	target int      // Index of the matching task in the open list; -1 when added
+	diff   []string // What an update changes, e.g. "priority none → high"
+}
+
+// planImport works out what merging incoming into existing would do. Tasks
+// match on their text, ignoring case and surrounding space.
+func planImport(existing, incoming []task) []importItem {
+	index := map[string]int{}
+	for i, t := range existing {
+		key := strings.ToLower(strings.TrimSpace(t.Text))
+		if _, ok := index[key]; !ok {
+			index[key] = i
+		}
+	}
+	items := make([]importItem, 0, len(incoming))
+	for _, in := range incoming {
+		i, ok := index[strings.ToLower(strings.TrimSpace(in.Text))]
+		if !ok {
+			items = append(items, importItem{change: importAdd, task: in, target: -1})
+			continue
+		}
+		merged := mergeImported(existing[i], in)
+		item := importItem{change: importSame, task: merged, target: i, diff: importDiff(existing[i], merged)}
+		if len(item.diff) > 0 {
+			item.change = importUpdate
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// mergeImported updates old with what an imported task says about it: its
+// completion, and its priority, due date and tags where it has them. A task
+// the import still has open is only reopened if it was done here.
+func mergeImported(old, in task) task {
+	t := old
+	switch {
+	case in.Status == statusDone && old.Status != statusDone:
+		t.Status, t.Completed = statusDone, in.Completed
+	case in.Status != statusDone && old.Status == statusDone:
+		t.Status, t.Completed = statusTodo, time.Time{}
+	}
+	if in.Priority != priorityNone {
+		t.Priority = in.Priority
+	}
+	if !in.Due.IsZero() {
+		t.Due = in.Due
+	}
+	t.Tags = slices.Clone(old.Tags)
+	for _, tag := range in.Tags {
+		if !t.hasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	return t
+}
+
+// importDiff describes how an update changes a task, one entry per field
+func importDiff(old, updated task) []string {
+	var diff []string
+	if old.Status != updated.Status {
+		diff = append(diff, fmt.Sprintf("%s → %s", old.Status, updated.Status))
+	}
+	if old.Priority != updated.Priority {
+		diff = append(diff, fmt.Sprintf("priority %s → %s", old.Priority, updated.Priority))
+	}
+	if !old.Due.Equal(updated.Due) {
+		from, to := "none", "none"
+		if !old.Due.IsZero() {
+			from = old.Due.Format(dueLayout)
+		}
+		if !updated.Due.IsZero() {
+			to = updated.Due.Format(dueLayout)
+		}
+		diff = append(diff, fmt.Sprintf("due %s → %s", from, to))
+	}
+	for _, tag := range updated.Tags {
+		if !old.hasTag(tag) {
+			diff = append(diff, "+#"+tag)
+		}
+	}
+	return diff
+}
+
+// Markers and colors for each kind of change in the import preview
+var importMarks = map[importChange]struct {
+	mark  string
+	style lipgloss.Style
+}{
+	importAdd:    {"+", doneStyle},
+	importUpdate: {"~", doingStyle},
+	importSame:   {"=", metaStyle},
+}
+
+// startImportPreview shows what importing tasks into the open list would
+// do, leaving the list alone until the preview is confirmed. skipped holds
+// why any records were left out. When imports replace the list, and it has
+// tasks to lose, the preview warns how many and offers merging instead.
+func (m model) startImportPreview(source string, tasks []task, skipped []string) model {
+	m.importWipe = m.importReplace && len(m.tasks) > 0
+	m.importTasks = tasks
+	m.importItems = planImport(m.tasks, tasks)
+	if m.importWipe {
+		m.importItems = planImport(nil, tasks)
+	}
+	m.importSource = source
+	m.importSkipped = skipped
+	m.importCursor = 0
+	m.state = previewingImport
+	return m
+}
+
+// applyImport merges the previewed import into the open list as one change
+// that can be undone
+func (m *model) applyImport() {
+	m.checkpoint()
+	lost := 0
+	if m.importWipe {
+		for _, t := range m.tasks {
+			m.record("delete", t.Text)
+		}
+		lost = len(m.tasks)
+		m.tasks = []task{}
+		m.cursor = 0
+	}
+	var added, updated int
+	for _, item := range m.importItems {
+		switch item.change {
+		case importAdd:
+			m.addTask(item.task)
+			added++
+		case importUpdate:
+			m.tasks[item.target] = item.task
+			m.record("import", item.task.Text)
+			updated++
+		}
+	}
+	m.notice = fmt.Sprintf("Imported from %s: %d added, %d updated", m.importSource, added, updated)
+	if m.importWipe {
+		m.notice = fmt.Sprintf("Replaced %d tasks with %d from %s (u to undo)", lost, added, m.importSource)
+	}
+	m.importItems, m.importTasks = nil, nil
+}
+
+// updatePreviewingImport handles key input in the import preview
+func (m model) updatePreviewingImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Drop the import, leaving the list as it was
+	case "esc", "q":
+		m.state = browsing
+		m.importItems, m.importTasks = nil, nil
+		m.notice = "Import cancelled"
+
+	case "up", "k":
+		if m.importCursor > 0 {
+			m.importCursor--
+		}
+
+	case "down", "j":
+		if m.importCursor < len(m.importItems)-1 {
+			m.importCursor++
+		}
+
+	// A replacing import only goes ahead on r, so enter can't lose tasks
+	case "enter", "y":
+		if m.importWipe {
+			m.notice = fmt.Sprintf("r replaces the %d tasks in the list • m merges instead", len(m.tasks))
+			break
+		}
+		m.applyImport()
+		m.state = browsing
+
+	case "r":
+		if m.importWipe {
+			m.applyImport()
+			m.state = browsing
+		}
+
+	// Merge into the list after all, keeping its tasks
+	case "m":
+		if m.importWipe {
+			m.importWipe = false
+			m.importItems = planImport(m.tasks, m.importTasks)
+			m.importCursor = 0
+		}
+	}
+	return m, nil
+}
+
+// viewImportPreview renders every task of the planned import with what it
+// would do, drawing only the rows that fit around the highlighted one
+func (m model) viewImportPreview() string {
+	var counts [3]int
+	for _, item := range m.importItems {
+		counts[item.change]++
+	}
+	s := titleStyle.Render("📥 Import from "+m.importSource) + "\n\n"
+	s += statusBarStyle.Render(fmt.Sprintf("%d new • %d updated • %d unchanged • %d skipped",
+		counts[importAdd], counts[importUpdate], counts[importSame], len(m.importSkipped))) + "\n\n"
+	if m.importWipe {
+		noun := "tasks"
+		if len(m.tasks) == 1 {
+			noun = "task"
+		}
+		s += badgeStyle.Render(fmt.Sprintf("⚠ Replacing: the %d %s now in %s will be lost", len(m.tasks), noun, m.lists[m.active].label())) + "\n\n"
+	}
+
+	room := len(m.importItems)
+	if m.height > 0 {
+		room = max(m.height-10, 3)
+	}
+	start := max(min(m.importCursor-room/2, len(m.importItems)-room), 0)
+	for n := start; n < min(start+room, len(m.importItems)); n++ {
+		item := m.importItems[n]
+		mark := importMarks[item.change]
+		lead, style := m.gutter(), taskStyle
+		if n == m.importCursor {
+			lead, style = cursorStyle.Render(m.icons.Cursor+" "), selectedStyle
+		}
+		line := lead + mark.style.Render(mark.mark) + " " + style.Render(item.task.Text)
+		if len(item.diff) > 0 {
+			line += " " + metaStyle.Render(strings.Join(item.diff, ", "))
+		}
+		s += line + "\n"
+	}
+	if len(m.importItems) == 0 {
+		s += normalStyle.Render("Nothing to import.") + "\n"
+	}
+	for n, reason := range m.importSkipped {
+		if n == 3 {
+			s += metaStyle.Render(fmt.Sprintf("    …and %d more skipped", len(m.importSkipped)-n)) + "\n"
+			break
+		}
+		s += metaStyle.Render("    skipped "+reason) + "\n"
+	}
+	if m.notice != "" {
+		s += "\n" + noticeStyle.Render(m.notice) + "\n"
+	}
+	if m.importWipe {
+		return s + "\n" + helpStyle.Render("↑/↓: scroll • r: replace • m: merge instead • esc: cancel")
+	}
+	return s + "\n" + helpStyle.Render("↑/↓: scroll • enter: import • esc: cancel")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the keys for the actions used most, so the update handlers
+// and the help both read them from one place. Keys not in it are still
+// matched where they're handled.
+type keyMap struct {
+	// Browsing the list
+	Up, Down     key.Binding
+	Add, Edit    key.Binding
+	Status       key.Binding // Cycles todo → doing → done
+	Delete       key.Binding
+	Undo, Redo   key.Binding
+	Search       key.Binding
+	Filter       key.Binding
+	Lists        key.Binding
+	Help         key.Binding
+	Quit         key.Binding // Steps back, or quits, asking about unsaved changes
+	ForceQuit    key.Binding // Quits without asking
+	Save, Cancel key.Binding // Typing a new task
+	Close        key.Binding // Leaving the help
+}
+
+// defaultKeyMap returns the keys the app has always used
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:        bind("Move selection up", "up", "k"),
+		Down:      bind("Move selection down", "down", "j"),
+		Add:       bind("Add a new task (New/Add)", "n", "a"),
+		Edit:      bind("Edit selected task", "e"),
+		Status:    bind("Cycle status", " "),
+		Delete:    bind("Remove selected task, or every marked one (Delete)", "x", "d", "backspace"),
+		Undo:      bind("Undo the last change", "u"),
+		Redo:      bind("Redo the last change undone", "ctrl+r"),
+		Search:    bind("Search every list and jump to a task (was /)", "ctrl+s"),
+		Filter:    bind("Show only the tasks matching what you type", "/"),
+		Lists:     bind("Open the list switcher (new, clone, rename, icon, color, merge)", "L"),
+		Help:      bind("Toggle this help view", "?", "h"),
+		Quit:      bind("Return to list or Quit", "q", "esc"),
+		ForceQuit: bind("Force quit", "ctrl+c"),
+		Save:      bind("Confirm new task (In input mode)", "enter"),
+		Cancel:    bind("Stop typing a new task (In input mode)", "esc"),
+		Close:     bind("Close the help", "esc", "q", "?", "h", "enter"),
+	}
+}
+
+// bind returns a binding on keys, as Bubble Tea names them, with its keys
+// spelled out for the help beside desc
+func bind(desc string, keys ...string) key.Binding {
+	names := make([]string, len(keys))
+	for n, k := range keys {
+		names[n] = keyName(k)
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(names, " / "), desc))
+}
+
+// keyName spells a key as the help shows it: arrows as arrows, and named
+// keys capitalized, such as Space, Esc and Ctrl+R
+func keyName(k string) string {
+	switch k {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case " ":
+		return "Space"
+	case "backspace":
+		return "bk"
+	}
+	if len(k) < 2 {
+		return k
+	}
+	parts := strings.Split(k, "+")
+	for n, p := range parts {
+		if p != "" {
+			parts[n] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// hintKey returns the first key of b as the hints under the list show it
+func hintKey(b key.Binding) string {
+	keys := b.Keys()
+	switch {
+	case len(keys) == 0:
+		return ""
+	case keys[0] == " ":
+		return "space"
+	case keys[0] == "up", keys[0] == "down":
+		return keyName(keys[0])
+	}
+	return keys[0]
+}
+
+// hints returns the key hints shown under the list while browsing
+func (k keyMap) hints() string {
+	return fmt.Sprintf("%s/%s: navigate • %s: add • %s: status • %s: delete • %s: lists • %s: help • %s: quit",
+		hintKey(k.Up), hintKey(k.Down), hintKey(k.Add), hintKey(k.Status), hintKey(k.Delete), hintKey(k.Lists), hintKey(k.Help), hintKey(k.Quit))
+}
+
+// helpLine renders a line of the help for b: its keys, then what it does
+func helpLine(b key.Binding) string {
+	return helpEntry(b.Help().Key, b.Help().Desc)
+}
+
+// helpEntry renders a line of the help from its keys and what they do,
+// lining the descriptions up
+func helpEntry(keys, desc string) string {
+	return normalStyle.Render(fmt.Sprintf("%-10s - %s", keys, desc)) + "\n"
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Accent colors handed out to new lists so each one looks distinct
+var listPalette = []string{"99", "212", "39", "78", "214", "203"}
+
+// colorPattern matches an ANSI color number (0-255) or a #rgb/#rrggbb hex color
+var colorPattern = regexp.MustCompile(`^(\d{1,2}|1\d\d|2[0-4]\d|25[0-5]|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+
+// taskList is a named, customizable list of tasks
+type taskList struct {
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
+	Tasks []task `json:"tasks"`
+	Inbox bool   `json:"inbox,omitempty"` // Quick captures land here to be sorted into other lists
+
+	// Folder groups lists in the switcher, with / between the names of
+	// folders inside folders; empty at the top level
+	Folder string `json:"folder,omitempty"`
+
+	// Where the list was left, restored when it's opened again
+	Cursor int `json:"cursor,omitempty"`
+	Offset int `json:"offset,omitempty"`
+
+	// How the list was being viewed, restored along with it; nil while
+	// it's viewed the default way
+	View *listView `json:"view,omitempty"`
+}
+
+// listView holds the filters and display options each list keeps to
+// itself, so setting one on a list doesn't carry over to the next
+type listView struct {
+	MinPriority priority `json:"minPriority,omitempty"`
+	TagFilter   string   `json:"tagFilter,omitempty"`
+	WaitingView bool     `json:"waitingView,omitempty"`
+	SinkFuture  bool     `json:"sinkFuture"`
+	Detailed    bool     `json:"detailed,omitempty"`
+	FilterQuery string   `json:"filterQuery,omitempty"`
+}
+
+// newTaskList creates an empty list with the default look for position n
+func newTaskList(name string, n int) taskList {
+	icon := "📋"
+	if n == 0 {
+		icon = "📝"
+	}
+	return taskList{Name: name, Icon: icon, Color: listPalette[n%len(listPalette)], Tasks: []task{}}
+}
+
+// label renders the list's icon and name
+func (l taskList) label() string {
+	return l.Icon + " " + l.Name
+}
+
+// style returns a style using the list's accent color
+func (l taskList) style() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(l.Color))
+}
+
+// capFor returns how many tasks the list holds before it counts as getting
+// large, or zero when it never does
+func (m model) capFor(l taskList) int {
+	if limit, ok := m.listCaps[l.Name]; ok {
+		return limit
+	}
+	return m.listCap
+}
+
+// capWarning nudges toward clearing out the open list once it holds more
+// tasks than its cap, or renders nothing. It never stops tasks being added.
+func (m model) capWarning() string {
+	limit := m.capFor(m.lists[m.active])
+	if limit == 0 || len(m.tasks) <= limit {
+		return ""
+	}
+	return badgeStyle.Render(fmt.Sprintf(" • List is getting large: %d tasks", len(m.tasks)))
+}
+
+// inbox returns the index of the list flagged as the inbox, or -1 when no
+// list is
+func (m model) inbox() int {
+	for i, l := range m.lists {
+		if l.Inbox {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleInbox makes lists[i] the inbox, taking the flag off any other list,
+// or makes it a plain list again if it already is the inbox
+func (m *model) toggleInbox(i int) {
+	m.checkpoint()
+	was := m.lists[i].Inbox
+	for j := range m.lists {
+		m.lists[j].Inbox = false
+	}
+	m.lists[i].Inbox = !was
+	m.describeChange("the inbox change to " + m.lists[i].label())
+	if was {
+		m.notice = m.lists[i].label() + " is no longer the inbox"
+	} else {
+		m.notice = m.lists[i].label() + " is now the inbox"
+	}
+}
+
+// captureTarget returns the list quick capture adds to: the inbox, or the
+// active list when there's no inbox or --list chose it
+func (m model) captureTarget() int {
+	if i := m.inbox(); i >= 0 && !m.captureHere {
+		return i
+	}
+	return m.active
+}
+
+// captureTask adds t to the inbox, or to the active list when there's no
+// inbox, it's already open or --list chose it, returning the list it went to
+func (m *model) captureTask(t task) int {
+	i := m.captureTarget()
+	if i == m.active {
+		m.addTask(t)
+		return m.active
+	}
+	if t.Created.IsZero() {
+		t.Created = time.Now()
+	}
+	m.record("add", t.Text)
+	m.lists[i].Tasks = append(m.lists[i].Tasks, t)
+	return i
+}
+
+// inboxCount returns how many tasks in the inbox are still unfinished
+func (m model) inboxCount() int {
+	i := m.inbox()
+	if i < 0 {
+		return 0
+	}
+	tasks := m.lists[i].Tasks
+	if i == m.active {
+		tasks = m.tasks
+	}
+	n := 0
+	for _, t := range tasks {
+		if t.Status != statusDone {
+			n++
+		}
+	}
+	return n
+}
+
+// startProcessing opens the inbox and walks through its tasks from the top,
+// asking which list each one should move to
+func (m model) startProcessing() (tea.Model, tea.Cmd) {
+	i := m.inbox()
+	if i < 0 {
+		m.notice = "No inbox yet; press I in the list switcher (L) to make one"
+		return m, nil
+	}
+	m.switchList(i)
+	m.cursor = 0
+	if _, ok := m.selected(); !ok {
+		m.notice = "Inbox zero 🎉"
+		return m, nil
+	}
+	m.moving, m.processing = true, true
+	m.listCursor, m.onFolder = (i+1)%len(m.lists), ""
+	m.state = pickingList
+	return m, nil
+}
+
+// processNext files the task under the cursor in lists[target], leaving it
+// in the inbox when target is the inbox itself, and moves on to the next
+// one. Processing ends once the bottom of the inbox is reached.
+func (m *model) processNext(target int) {
+	if target == m.active {
+		m.cursor++
+	} else {
+		m.moveTask(target)
+	}
+	if _, ok := m.selected(); ok {
+		return
+	}
+	m.cursor = max(len(m.visible())-1, 0)
+	m.state = browsing
+	m.moving, m.processing = false, false
+	if m.inboxCount() == 0 {
+		m.notice = "Inbox zero 🎉"
+	} else {
+		m.notice = "Inbox processed"
+	}
+}
+
+// soloBlocks reports whether only the open list is being shown, so keys
+// that reach other lists do nothing, and says how to bring them back
+func (m *model) soloBlocks() bool {
+	if m.solo {
+		m.notice = "Only " + m.lists[m.active].label() + " is showing • o to show every list"
+	}
+	return m.solo
+}
+
+// switchList makes lists[i] the active list. The active list's tasks and
+// position live in the model while it's open, so they're stashed back
+// before switching and the new list picks up where it was left.
+func (m *model) switchList(i int) {
+	if i == m.active || i < 0 || i >= len(m.lists) {
+		return
+	}
+	m.lists[m.active] = m.activeList()
+	m.prevList, m.active = m.active, i
+	m.openList()
+}
+
+// openNamedList switches to the list called name, ignoring case, for the
+// --list flag. A list that doesn't exist is added at the end when create is
+// set and is an error otherwise.
+func (m *model) openNamedList(name string, create bool) error {
+	name = strings.TrimSpace(name)
+	for i, l := range m.lists {
+		if strings.EqualFold(l.Name, name) {
+			m.switchList(i)
+			return nil
+		}
+	}
+	if name == "" || !create {
+		names := make([]string, len(m.lists))
+		for i, l := range m.lists {
+			names[i] = l.Name
+		}
+		return fmt.Errorf("no list called %q (lists: %s); add --create-list to start it", name, strings.Join(names, ", "))
+	}
+	m.checkpoint()
+	m.lists = append(m.lists, newTaskList(name, len(m.lists)))
+	m.switchList(len(m.lists) - 1)
+	return nil
+}
+
+// switchBack flips to the list open before the active one
+func (m *model) switchBack() {
+	if m.prevList < 0 || m.prevList >= len(m.lists) {
+		m.notice = "No other list opened yet"
+		return
+	}
+	m.switchList(m.prevList)
+}
+
+// activeList returns the open list with its tasks, position and view
+// brought up to date from the model
+func (m model) activeList() taskList {
+	l := m.lists[m.active]
+	l.Tasks, l.Cursor, l.Offset = m.tasks, m.cursor, m.offset
+	l.View = nil
+	if v := m.view(); v != m.defaultView() {
+		l.View = &v
+	}
+	return l
+}
+
+// view returns the model's current filters and display options
+func (m model) view() listView {
+	return listView{
+		MinPriority: m.minPriority,
+		TagFilter:   m.tagFilter,
+		WaitingView: m.waitingView,
+		SinkFuture:  m.sinkFuture,
+		Detailed:    m.detailed,
+		FilterQuery: m.filterQuery,
+	}
+}
+
+// defaultView returns how a list is viewed until it's set otherwise
+func (m model) defaultView() listView {
+	return listView{SinkFuture: m.sinkDefault}
+}
+
+// openList loads the active list's tasks, view and position into the
+// model, clamping the position in case the list has shrunk
+func (m *model) openList() {
+	l := m.lists[m.active]
+	m.tasks = l.Tasks
+	v := m.defaultView()
+	if l.View != nil {
+		v = *l.View
+	}
+	m.minPriority, m.tagFilter, m.waitingView = v.MinPriority, v.TagFilter, v.WaitingView
+	m.sinkFuture, m.detailed, m.filterQuery = v.SinkFuture, v.Detailed, v.FilterQuery
+	m.cursor = max(min(l.Cursor, len(m.visible())-1), 0)
+	m.offset = max(min(l.Offset, m.cursor), 0)
+}
+
+// moveTask moves the selected task, with any hidden subtasks, to the end of
+// lists[target] as a top-level task
+func (m *model) moveTask(target int) {
+	i, ok := m.selected()
+	if !ok || target == m.active {
+		return
+	}
+	m.checkpoint()
+	moved := m.removeTask(i)
+	base := moved[0].Depth
+	for j := range moved {
+		moved[j].Depth -= base
+		m.record("move", moved[j].Text)
+	}
+	m.lists[target].Tasks = append(m.lists[target].Tasks, moved...)
+	m.notice = "Moved to " + m.lists[target].label()
+}
+
+// startMerge asks to confirm merging lists[from] into lists[into], saying
+// how many tasks would move
+func (m model) startMerge(from, into int) (tea.Model, tea.Cmd) {
+	m.mergeFrom, m.mergeInto = from, into
+	lists := m.allLists()
+	label := fmt.Sprintf("Merge %d tasks from %s into %s? y: all • d: skip duplicates • n: cancel",
+		len(lists[from].Tasks), lists[from].label(), lists[into].label())
+	return m.startPrompt(promptMergeLists, label, "y")
+}
+
+// mergeLists appends the tasks of lists[from] to lists[into] and removes the
+// emptied list. With dedupe, top-level tasks whose text is already in the
+// target are left out along with their subtasks. Merging a list into itself
+// does nothing.
+func (m *model) mergeLists(from, into int, dedupe bool) {
+	if from == into {
+		return
+	}
+	m.checkpoint()
+	lists := m.allLists()
+
+	seen := map[string]bool{}
+	for _, t := range lists[into].Tasks {
+		seen[strings.ToLower(strings.TrimSpace(t.Text))] = true
+	}
+	moved, skipped := 0, 0
+	src := lists[from].Tasks
+	for i := 0; i < len(src); i = subtreeEnd(src, i) {
+		block := src[i:subtreeEnd(src, i)]
+		if dedupe && seen[strings.ToLower(strings.TrimSpace(src[i].Text))] {
+			skipped += len(block)
+			continue
+		}
+		lists[into].Tasks = append(lists[into].Tasks, block...)
+		moved += len(block)
+	}
+	// The inbox lives on in the list it was merged into
+	lists[into].Inbox = lists[into].Inbox || lists[from].Inbox
+
+	m.record("merge", lists[from].Name+" → "+lists[into].Name)
+	m.notice = fmt.Sprintf("Merged %d tasks from %s into %s", moved, lists[from].label(), lists[into].label())
+	if skipped > 0 {
+		m.notice += fmt.Sprintf(", skipped %d duplicates", skipped)
+	}
+
+	m.lists = slices.Delete(lists, from, from+1)
+	if into > from {
+		into--
+	}
+	if m.active == from {
+		m.active = into
+	} else if m.active > from {
+		m.active--
+	}
+	if m.prevList == from || m.prevList == m.active {
+		m.prevList = -1
+	} else if m.prevList > from {
+		m.prevList--
+	}
+	m.listCursor = into
+	m.openList()
+}
+
+// updatePickingList handles key input in the list switcher
+func (m model) updatePickingList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "L":
+		m.state = browsing
+		m.moving, m.processing, m.merging = false, false, false
+
+	case "up", "k":
+		m.movePicker(-1)
+
+	case "down", "j":
+		m.movePicker(1)
+
+	// Fold or unfold the highlighted folder
+	case "left", "h":
+		m.foldFolder(true)
+	case "right", "l":
+		m.foldFolder(false)
+
+	// Open the highlighted list, or move the selected task there. On a
+	// folder it folds or unfolds instead.
+	case "enter":
+		if m.onFolder != "" {
+			m.foldFolder(!m.folded[m.onFolder])
+			return m, nil
+		}
+		if m.processing {
+			m.processNext(m.listCursor)
+			return m, nil
+		}
+		if m.merging {
+			m.merging = false
+			if m.listCursor == m.mergeFrom {
+				return m, nil
+			}
+			return m.startMerge(m.mergeFrom, m.listCursor)
+		}
+		if m.moving {
+			m.moveTask(m.listCursor)
+		} else {
+			m.switchList(m.listCursor)
+		}
+		m.state = browsing
+		m.moving = false
+
+	// Undo or redo a change, list-level ones included, without leaving
+	case "u", "ctrl+r":
+		if m.moving || m.processing || m.merging {
+			return m, nil
+		}
+		if msg.String() == "u" && !m.undoChange() {
+			m.notice = "Nothing to undo"
+		} else if msg.String() == "ctrl+r" && !m.redoChange() {
+			m.notice = "Nothing to redo"
+		}
+		m.onFolder = ""
+
+	// Create, rename, or restyle lists through the prompt
+	case "n", "a":
+		return m.startPrompt(promptNewList, "New list name:", "")
+	case "r":
+		if m.onFolder != "" {
+			return m.startPrompt(promptRenameFolder, "Rename folder:", folderName(m.onFolder))
+		}
+		return m.startPrompt(promptRenameList, "Rename list:", m.lists[m.listCursor].Name)
+	}
+
+	// Everything else applies to the highlighted list, not a folder
+	if m.onFolder != "" {
+		return m, nil
+	}
+	switch msg.String() {
+	case "f":
+		return m.startFiling()
+	case "d":
+		if !m.moving && !m.processing && !m.merging {
+			return m.startPrompt(promptCloneList, "Clone as a template, named:", m.lists[m.listCursor].Name+" copy")
+		}
+	case "i":
+		return m.startPrompt(promptListIcon, "List icon (emoji):", m.lists[m.listCursor].Icon)
+	case "c":
+		return m.startPrompt(promptListColor, "List color (0-255 or #hex):", m.lists[m.listCursor].Color)
+	case "I":
+		if !m.moving {
+			m.toggleInbox(m.listCursor)
+		}
+
+	// Choose a list to merge the highlighted one into
+	case "m":
+		if !m.moving && len(m.lists) > 1 {
+			m.merging = true
+			m.mergeFrom = m.listCursor
+		}
+	}
+	return m, nil
+}
+
+// applyListPrompt carries out a submitted list prompt
+func (m *model) applyListPrompt(kind promptKind, value string) {
+	if kind == promptListColor && !colorPattern.MatchString(value) {
+		m.notice = fmt.Sprintf("%q isn't a color; use 0-255 or #hex", value)
+		return
+	}
+	m.checkpoint()
+	l := &m.lists[m.listCursor]
+	switch kind {
+	case promptNewList:
+		m.describeChange(fmt.Sprintf("new list %q", value))
+		// New lists go in the folder that's highlighted, or alongside the
+		// highlighted list
+		folder := l.Folder
+		if m.onFolder != "" {
+			folder = m.onFolder
+			delete(m.folded, folder)
+		}
+		nl := newTaskList(value, len(m.lists))
+		nl.Folder = folder
+		m.lists = append(m.lists, nl)
+		m.listCursor, m.onFolder = len(m.lists)-1, ""
+	case promptCloneList:
+		m.describeChange(fmt.Sprintf("cloning %q", value))
+		src := m.allLists()[m.listCursor]
+		nl := newTaskList(value, len(m.lists))
+		nl.Icon, nl.Folder = src.Icon, src.Folder
+		now := time.Now()
+		for _, t := range src.Tasks {
+			nl.Tasks = append(nl.Tasks, templateTask(t, now))
+		}
+		m.lists = append(m.lists, nl)
+		m.listCursor, m.onFolder = len(m.lists)-1, ""
+		m.notice = fmt.Sprintf("Cloned %s as %s, with %d tasks to do", src.label(), nl.label(), len(nl.Tasks))
+	case promptRenameList:
+		m.describeChange(fmt.Sprintf("renaming %s to %q", l.label(), value))
+		l.Name = value
+	case promptListIcon:
+		m.describeChange("the new icon on " + l.label())
+		l.Icon = value
+	case promptListColor:
+		m.describeChange("the new color on " + l.label())
+		l.Color = value
+	}
+}
+
+// templateTask copies t into a list cloned as a template: its text,
+// priority, tags and place among the subtasks, but not its progress, dates
+// or anything else that belongs to the original
+func templateTask(t task, now time.Time) task {
+	return task{
+		Text:     t.Text,
+		Priority: t.Priority,
+		Depth:    t.Depth,
+		Tags:     slices.Clone(t.Tags),
+		Emoji:    t.Emoji,
+		Created:  now,
+	}
+}
+
+// viewListTabs renders a tab strip of every list, highlighting the active one.
+// It's left out entirely while there's only one list.
+func (m model) viewListTabs() string {
+	if len(m.lists) < 2 {
+		return ""
+	}
+	tabs := make([]string, len(m.lists))
+	for i, l := range m.lists {
+		if i == m.active {
+			tabs[i] = l.style().Bold(true).Underline(true).Render(l.label())
+		} else {
+			tabs[i] = blurredStyle.Render(l.label())
+		}
+		if n := m.inboxCount(); l.Inbox && n > 0 {
+			tabs[i] += " " + badgeStyle.Render(fmt.Sprintf("(%d)", n))
+		}
+	}
+	return "  " + strings.Join(tabs, blurredStyle.Render(" │ ")) + "\n\n"
+}
+
+// viewPickingList renders the list switcher
+func (m model) viewPickingList() string {
+	title := "📚 Lists"
+	switch {
+	case m.merging:
+		title = "🔀 Merge " + m.lists[m.mergeFrom].label() + " into"
+	case m.processing:
+		title = fmt.Sprintf("📥 Process inbox (%d left)", len(m.visible())-m.cursor)
+	case m.moving:
+		title = "📦 Move to list"
+	}
+	s := titleStyle.Render(title) + "\n\n"
+	if i, ok := m.selected(); ok && m.processing {
+		s += normalStyle.Render(m.tasks[i].Text) + "\n\n"
+	}
+	rows := m.pickerRows()
+	at := m.pickerAt(rows)
+	for r, row := range rows {
+		if row.list < 0 {
+			s += m.viewFolderRow(row, r == at) + "\n"
+			continue
+		}
+		l := m.lists[row.list]
+		count := len(l.Tasks)
+		if row.list == m.active {
+			count = len(m.tasks)
+		}
+		line := fmt.Sprintf("%s (%d)", l.label(), count)
+		if l.Inbox {
+			line += " • inbox"
+		}
+		indent := strings.Repeat("  ", row.depth)
+		if r == at {
+			s += cursorStyle.Render(m.icons.Cursor+" ") + indent + l.style().Bold(true).Render(line) + "\n"
+		} else {
+			s += m.gutter() + indent + l.style().Render(line) + "\n"
+		}
+	}
+	return s
+}
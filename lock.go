@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleCheckMsg asks whether the app has sat idle long enough to lock
+type idleCheckMsg struct{}
+
+// tickIdle checks for idling again after d
+func tickIdle(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg { return idleCheckMsg{} })
+}
+
+// checkIdle locks the app once no key has been pressed for the idle lock's
+// length, and otherwise checks again when it would run out
+func (m *model) checkIdle(now time.Time) tea.Cmd {
+	if m.idleLock <= 0 {
+		return nil
+	}
+	idle := now.Sub(m.lastKey)
+	if idle < m.idleLock {
+		return tickIdle(m.idleLock - idle)
+	}
+	if !m.locked {
+		m.locked = true
+		m.notice = fmt.Sprintf("🔒 Locked after %d minutes idle • press any key to unlock", int(m.idleLock/time.Minute))
+	}
+	return tickIdle(m.idleLock)
+}
@@ -1,12 +1,28 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
 )
 
 // Application states
@@ -16,6 +32,25 @@ const (
 	browsing appState = iota
 	inputting
 	helping
+	viewingHistory
+	pickingList
+	prompting
+	pickingDate
+	viewingTags
+	reviewing
+	searching
+	viewingDetail
+	previewingImport
+	replacing
+	viewingAgenda
+	pickingEmoji
+	viewingDashboard
+	viewingNext
+	confirmingDelete
+	editingNotes
+	confirmingQuit
+	viewingArchive
+	filtering
 )
 
 // Styles using Lip Gloss for a minimalist aesthetic
@@ -23,10 +58,13 @@ var (
 	// Selected item style: bold with a subtle accent color
 	selectedStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("212")).
-			Bold(true).
-			PaddingLeft(2)
+			Bold(true)
 
 	// Unselected items: dimmed for visual hierarchy
+	taskStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	// Plain text lines: dimmed and indented to line up with task text
 	normalStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245")).
 			PaddingLeft(4)
@@ -52,41 +90,633 @@ var (
 				Foreground(lipgloss.Color("212")).
 				Bold(true).
 				MarginTop(1)
+
+	// Blurred style: replaces accents while the terminal is unfocused
+	blurredStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240"))
+
+	// Status markers: one color per task status
+	todoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	doingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	doneStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+
+	// Waiting tasks are dimmed since nothing can be done about them yet
+	waitingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	// Highlight for the row chosen by the random picker
+	pickedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("230")).
+			Background(lipgloss.Color("62")).
+			Bold(true)
+
+	// Priority badge following the task text, dim for low priority, then
+	// yellow and red as it rises; the high and medium colors tint the text
+	// too when priorityColors is on
+	priorityBadgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	priorityMedColor   = lipgloss.Color("220")
+	priorityHighColor  = lipgloss.Color("196")
+
+	// Due date following the task text, highlighted once the task is
+	// within its reminder lead time
+	dueStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+	dueSoonStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	// Due dates, and with dueColors the text, of tasks due today and of
+	// overdue ones
+	dueTodayColor = lipgloss.Color("220")
+	overdueColor  = lipgloss.Color("196")
+
+	// Progress bar following the task text
+	progressStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+
+	// Metadata line shown beneath tasks in the detailed layout
+	metaStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	// Title badge counting what's due
+	badgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	// Fold marker on tasks that have subtasks
+	foldStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("99"))
+
+	// Mark before tasks picked out for a bulk action
+	markStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+	// Status bar style: task counts above the help line
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			PaddingLeft(2)
+
+	// Aging tasks step through these colors between the warning and alert
+	// thresholds, then settle on ageAlertColor
+	ageRamp       = []lipgloss.Color{"229", "222", "215", "209"}
+	ageAlertColor = lipgloss.Color("196")
+
+	// Background behind every other row when zebra striping is on
+	stripeStyle = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "254", Dark: "236"})
+
+	// Background behind the whole cursor row when the cursor is a highlight
+	cursorRowStyle = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "252", Dark: "238"})
+
+	// Section headings in the help
+	headingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	// Notice style: short-lived feedback and warnings
+	noticeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			PaddingLeft(2)
 )
 
 // Model holds the application state following the Elm architecture
 type model struct {
-	tasks    []string          // List of tasks
-	cursor   int               // Currently selected task index
-	state    appState          // Current application state (browsing or inputting)
-	input    textinput.Model   // Text input component for adding tasks
-	quitting bool              // Flag to indicate app is quitting
+	tasks    []task          // Tasks of the active list
+	cursor   int             // Currently selected row of the visible tasks
+	state    appState        // Current application state (browsing or inputting)
+	input    textinput.Model // Text input component for adding tasks
+	discard  bool            // Quit without saving, as n answers to unsaved changes
+	sortedBy int             // Index into sortKeys of the order , last sorted in; -1 before the first
+	quitting bool            // Flag to indicate app is quitting
+	notice   string          // One-shot message shown until the next key press
+	blurred  bool            // Terminal window has lost focus
+
+	tieBreak func(a, b task) int // Settles tasks a sort finds equal; nil leaves them in the order they had
+
+	width, height int // Terminal size, zero until the first WindowSizeMsg
+	offset        int // First visible row drawn; the list scrolls to keep the cursor on screen
+
+	register    []task // Tasks held for pasting; nil when the register is empty
+	registerCut bool   // Cut tasks paste once, copied tasks paste repeatedly
+
+	history []historyEntry // Append-only record of changes, oldest first
+	logFile string         // Optional file mirroring the history
+
+	completed []string // Tasks finished this session, in order, for the summary on quit
+	summary   bool     // Show the completed tasks when quitting
+
+	dateLayout string    // Go layout used wherever a date is displayed
+	textRules  textRules // Clean-ups applied to the text of new tasks
+	icons      iconSet   // Markers drawn in the list
+	csvColumns []string  // Columns written by the CSV export, in order
+
+	tagColors map[string]string // Colors for tasks carrying a tag, keyed by tag name
+
+	quick []quickAction // Keys bound by the config to a change to the selected task
+
+	keys keyMap // Keys for the actions used most
+
+	helpOffset int // First line of the help on screen, once it's taller than the window
+
+	archive       []archivedTask // Archived tasks being looked through, oldest first as the file keeps them
+	archiveOrder  []int          // Where each of them is in the order shown, newest first
+	archiveCursor int            // Row of the selected one
+	archiveOffset int            // First row on screen
+	archiveAsk    archiveAsk     // Change waiting on a y to confirm it
+
+	archiveOnDone  bool          // Move tasks to the archive once they're done
+	archiveDelay   time.Duration // How long a finished task shows as done before it's archived
+	pendingArchive []string      // IDs of tasks finished in the current update, to archive
+	archivedDone   []doneStamp   // When each archived task was finished, for the goals to count
+
+	hooks        map[string]string // Shell command to run after each kind of change, by event
+	pendingHooks []hookEvent       // Changes whose hooks run once the current update is done
+
+	picked int // Row briefly highlighted by the random picker, -1 when none
+
+	flashAdded bool // Briefly highlight each task as it's added
+	clearOnAdd bool // Adding a task the filters hide lifts them rather than saying so
+	keepDrafts bool // Esc in the new-task field keeps its text for next time
+	hideHints  bool // Leave the key hints off the bottom of the list
+	hintsOff   bool // The config hides the key hints until b shows them
+	showIDs    bool // End each row with the task's ID, for referring to it from scripts
+	showAge    bool // End each row with how long ago the task was added
+
+	relativeDue     bool // Show due dates relative to today ("in 3 days") rather than as dates
+	relativeDefault bool // The config's choice, which @ overrides until pressed back
+
+	dailyGoal, weeklyGoal int    // Tasks to finish a day and a week; zero for no goal
+	goalDefaults          [2]int // The config's daily and weekly goals, which g in the overview overrides
+
+	frogBanner bool // Suggest the task to do first under the title
+
+	lastRun    string // Day the app was last started, for the daily roll-up
+	carryCount bool   // Count on each task how often the roll-up carried it over
+
+	autoAdvance bool // Jump to the next unfinished task after completing one
+	wrapCursor  bool // Moving past either end of the list comes round to the other
+
+	progressDone bool // Mark a task done once its progress reaches 100%
+
+	server *taskServer // Read-only HTTP view, nil unless --serve is given
+
+	minPriority priority // Tasks below this priority are filtered out
+	newPriority priority // Priority new tasks start with unless they set their own
+	tagFilter   string   // Only tasks carrying this tag are shown; empty shows all
+	tagCursor   int      // Highlighted row in the tag overview
+	waitingView bool     // Show only waiting tasks instead of hiding them
+	sinkFuture  bool     // Show tasks due more than sinkDays out below the rest
+	sinkDays    int      // How far ahead a task can be due without sinking
+	sinkDefault bool     // Whether lists sink far-off tasks until told otherwise
+
+	doneFilter doneFilter // Whether to show only unfinished or only finished tasks
+
+	waitingInline bool // Show waiting tasks dimmed in the main view, skipped over
+
+	dueReminders bool       // Say what's due once a day
+	dueBell      bool       // Ring the terminal bell with the reminder
+	quietHours   quietHours // Window when reminders wait
+	remindedDay  time.Time  // Day the last reminder was given
+	remindLead   int        // Days ahead of a due date to remind, for tasks without a lead of their own
+
+	undo []snapshot // States to step back to, most recent last
+	redo []snapshot // States undone since the last change, most recent last
+
+	lists      []taskList // Every list; the active one's tasks live in tasks while open
+	active     int        // Index of the open list
+	listCursor int        // Highlighted row in the list switcher
+	moving     bool       // The list switcher is choosing where to move the selected task
+	processing bool       // Moving inbox tasks one after another until the inbox is sorted
+	capturing  bool       // The new-task field adds to the inbox rather than the open list
+	merging    bool       // The list switcher is choosing a list to merge mergeFrom into
+	mergeFrom  int        // List being merged away
+	mergeInto  int        // List receiving the merged tasks
+
+	prevList int // List open before the active one, which ` flips back to; -1 until a switch
+
+	captureHere bool // --list named the list to open, so quick capture adds there instead of the inbox
+
+	snoozeHour int // Hour of the morning ctrl+n snoozes a task until
+
+	bumpOverdueOn bool // Raise a task's priority a level when it goes overdue
+
+	listCap  int            // Tasks a list holds before the status bar warns; zero never warns
+	listCaps map[string]int // Caps of their own for lists by name
+
+	onFolder string          // Folder highlighted in the list switcher, "" when a list is
+	folded   map[string]bool // Folders folded shut in the list switcher
+
+	promptInput  textinput.Model // One-line input for prompts other than new tasks
+	prompt       promptKind      // What the open prompt's answer is for
+	promptLabel  string          // Question shown above the prompt
+	promptReturn appState        // Screen to go back to once the prompt closes
+
+	notesArea   textarea.Model // Multi-line field for a task's notes
+	notesTask   int            // Task whose notes are being edited
+	notesReturn appState       // Screen to go back to once the notes close
+
+	dataFile string // Where the lists are saved
+	backups  int    // How many numbered backups of the tasks file to keep
+	dirty    bool   // Changes not yet written to dataFile
+
+	detailed bool // Show each task's metadata on its own line beneath it
+	wrap     bool // Wrap long tasks onto more lines rather than truncating them
+	zebra    bool // Shade every other row
+
+	strikeDone     bool // Strike through the text of finished tasks
+	checkboxes     bool // Put [x] or [ ] before each task's text
+	priorityColors bool // Tint high and medium priority tasks red and yellow
+	dueColors      bool // Tint overdue tasks red and those due today yellow
+
+	confirmDelete bool // Ask before deleting a task
+	pendingDelete int  // Index into m.tasks of the task waiting on that answer
+	pendingClear  bool // The question is whether to clear every finished task instead
+	pendingMarked bool // Or whether to delete every marked task
+
+	foldDone bool // Gather finished tasks under a fold at the bottom of the list
+	doneOpen bool // The completed fold is open, showing them
+
+	highlightCursor bool   // Mark the cursor row with a background rather than a leading glyph
+	cursorColor     string // Color of the cursor glyph, or the row highlight; empty for the default
+
+	cheatSheet bool // Show a panel of common keys alongside the list
+	split      bool // Show the selected task in a pane beside the list
+	zen        bool // Show only the tasks' text, without markers, metadata or bars
+	solo       bool // Act as if the open list were the only one, hiding the rest
+	colors     int  // Index into colorProfiles of the color mode in use
+
+	theme        int     // Index into themes of the palette in use
+	themeDefault int     // The config's theme, which ctrl+b overrides until pressed back
+	themeColors  palette // The config's colors, drawn over every theme
+
+	calTask int       // Task whose due date the calendar is setting
+	calDay  time.Time // Day highlighted in the calendar
+
+	emojiTask   int // Task whose emoji the picker is setting
+	emojiCursor int // Highlighted emoji in the picker, an index into emojiChoices
+
+	reviewAt    int // Task being reviewed
+	reviewed    int // Tasks decided on so far in this review
+	reviewTotal int // Unfinished tasks when the review started
+
+	editing int // Task the edit prompt changes
+
+	importItems   []importItem // Planned import awaiting confirmation
+	importSource  string       // File the import comes from
+	importSkipped []string     // Why records were left out of the import
+	importCursor  int          // Highlighted row in the import preview
+
+	importReplace bool   // Imports replace the open list's tasks rather than adding to them
+	importWipe    bool   // The import in preview replaces the list, once confirmed
+	importTasks   []task // Tasks the import in preview brings in, for planning it again as a merge
+
+	replaceFind   string // Text the replace looks for
+	replaceWith   string // Text the replace puts in its place
+	replaceCase   bool   // The replace matches case rather than ignoring it
+	replaceCursor int    // Highlighted row in the replace preview
+
+	agendaCursor    int  // Highlighted task in the agenda, counting from the top
+	agendaHideEmpty bool // Leave days with nothing due out of the agenda
+
+	dashCursor int // Highlighted list in the overview
+	nextCursor int // Highlighted list in the next actions view
+
+	enterAction string // What enter does in browse mode, one of the enter* actions
+	detailTask  int    // Task shown in the detail view
+
+	searchInput  textinput.Model // Query for the search across every list
+	searchCursor int             // Highlighted hit in the search results
+
+	searchIndex []searchEntry // Every task's text to search, lowercased once when the search opens
+	searchQuery string        // Query the hits were last found for
+	searchFound []int         // Hits for searchQuery, as indices into searchIndex
+
+	filterInput textinput.Model // Query typed to narrow the open list
+	filterQuery string          // Lowercased query the rows shown must match; empty shows all
+
+	timerID int // Identifies the stopwatch's latest run so stale ticks are ignored
+
+	countdown   bool // Count down to the selected task's deadline in the status bar
+	countdownID int  // Identifies the countdown shown so ticks from an earlier one are dropped
+
+	focusLength time.Duration // How long a stopwatch run lasts before asking what's next; zero to run on
+	focusTask   int           // Task whose focus session just ended
+
+	clearArmed bool // X was pressed once; pressing it again clears the list
+	clearArmID int  // Identifies the latest arming so stale disarms are ignored
+
+	jumpDigits string // Task number being typed, jumped to once typing pauses
+	jumpID     int    // Identifies the latest digit so stale jumps are ignored
+
+	pendingG bool // g was pressed once; another g goes to the first task
+
+	ageWarn  time.Duration // Unfinished tasks older than this start fading toward a warning; zero disables
+	ageAlert time.Duration // Unfinished tasks older than this show in the alert color
+
+	idleLock time.Duration // How long without a key press before the app locks; zero never locks
+	lastKey  time.Time     // When the last key was pressed, which the idle lock counts from
+	locked   bool          // Locked after idling; the next key press only unlocks
+}
+
+// pickFadeMsg ends the random picker's highlight
+type pickFadeMsg struct{}
+
+// archiveDueMsg archives the tasks with these IDs, finished a moment ago,
+// as long as they're still done
+type archiveDueMsg []string
+
+// clearDisarmMsg cancels an armed clear-all unless it has since been re-armed
+type clearDisarmMsg int
+
+// jumpMsg jumps to the typed task number unless more digits have followed
+type jumpMsg int
+
+// clockMsg fires at the top of every minute so time-based views stay current
+type clockMsg time.Time
+
+// tickClock schedules the next clockMsg
+func tickClock() tea.Cmd {
+	return tea.Every(time.Minute, func(t time.Time) tea.Msg { return clockMsg(t) })
+}
+
+// autosaveInterval is how often autosaveMsg fires
+const autosaveInterval = 30 * time.Second
+
+// autosaveMsg saves any changes still unwritten, such as those a failed
+// save left behind
+type autosaveMsg struct{}
+
+// tickAutosave schedules the next autosaveMsg
+func tickAutosave() tea.Cmd {
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg { return autosaveMsg{} })
 }
 
-// initialModel creates and returns the initial model state
-func initialModel() model {
+// markGlyph flags a task marked for a bulk action
+const markGlyph = "◆"
+
+// minTextWidth is the narrowest a task's text gets squeezed to on screen
+const minTextWidth = 10
+
+// pickHighlight is how long a randomly picked task stays highlighted
+const pickHighlight = 1500 * time.Millisecond
+
+// addedFlash is how long a newly added task stays highlighted, and
+// addedNotice what's shown meanwhile, when flashAdded is on
+const (
+	addedFlash  = 600 * time.Millisecond
+	addedNotice = "Added ✓"
+)
+
+// filteredAddModes are the settings filteredAdd takes: say a new task is
+// hidden by the filters, or lift them to show it
+var filteredAddModes = []string{"notice", "clear"}
+
+// clearWindow is how long a first press of X waits for the confirming one
+const clearWindow = 2 * time.Second
+
+// jumpPause is how long after the last digit the cursor jumps
+const jumpPause = 800 * time.Millisecond
+
+// clearArmedNotice is shown while a clear-all is waiting for confirmation
+const clearArmedNotice = "Press X again to clear ALL tasks"
+
+// initialModel creates the initial model state, loading any saved lists
+// from path. Problems worth telling the user about, including the ones in
+// warnings, are shown as the first notice.
+func initialModel(cfg config, path string, warnings []string) model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter task name..."
-	ti.CharLimit = 100
+	ti.CharLimit = cfg.CharLimit
+	// Content wider than this scrolls horizontally inside the field
 	ti.Width = 40
 
-	return model{
-		tasks:  []string{},
-		cursor: 0,
-		state:  browsing,
-		input:  ti,
+	layout, _ := dateLayout(cfg.DateFormat)
+	newPriority, _ := priorityNamed(cfg.DefaultPriority)
+	quiet, _ := parseQuietHours(cfg.QuietHours)
+
+	pi := textinput.New()
+	pi.Width = 40
+
+	si := textinput.New()
+	si.Placeholder = "Search all lists..."
+	si.Width = 40
+
+	fi := textinput.New()
+	fi.Placeholder = "Filter this list..."
+	fi.Width = 40
+
+	saved, err := loadTasks(path)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	fixes := repairData(&saved)
+	if len(fixes) > 0 {
+		kept := "original not kept"
+		if backup, err := backupTasks(path); err != nil {
+			kept += ": " + err.Error()
+		} else {
+			kept = "original kept as " + backup
+		}
+		warnings = append(warnings, repairNotice(fixes, kept))
+	}
+	lists := saved.Lists
+	if len(lists) == 0 {
+		lists = []taskList{newTaskList("To-Do", 0)}
+	}
+	active := saved.Active
+	if active < 0 || active >= len(lists) {
+		active = 0
+	}
+
+	m := model{
+		state:   browsing,
+		input:   ti,
+		notice:  strings.Join(warnings, "; "),
+		logFile: cfg.LogFile,
+
+		dateLayout: layout,
+		picked:     -1,
+		summary:    true,
+
+		autoAdvance: cfg.AutoAdvance,
+		flashAdded:  cfg.FlashAdded,
+		clearOnAdd:  cfg.FilteredAdd == "clear",
+		tieBreak:    tieBreaks[cfg.SortTieBreak],
+		keepDrafts:  cfg.KeepDrafts,
+		hideHints:   !cfg.ShowHints,
+		showIDs:     cfg.ShowIDs,
+		showAge:     cfg.ShowAge,
+		hintsOff:    !cfg.ShowHints,
+
+		relativeDue:     cfg.RelativeDue,
+		relativeDefault: cfg.RelativeDue,
+
+		dailyGoal:    cfg.DailyGoal,
+		weeklyGoal:   cfg.WeeklyGoal,
+		goalDefaults: [2]int{cfg.DailyGoal, cfg.WeeklyGoal},
+
+		frogBanner:  cfg.FrogOnStart,
+		carryCount:  cfg.CarryOverCount,
+		wrapCursor:  cfg.WrapCursor,
+		enterAction: cfg.EnterAction,
+		newPriority: newPriority,
+		wrap:        cfg.WrapTasks,
+		zebra:       cfg.ZebraRows,
+		foldDone:    cfg.FoldCompleted,
+		split:       cfg.SplitPane,
+		sinkFuture:  cfg.SinkFuture,
+		sinkDefault: cfg.SinkFuture,
+		sinkDays:    max(cfg.SinkAfterDays, 0),
+
+		highlightCursor: cfg.CursorStyle == "highlight",
+		cursorColor:     cfg.CursorColor,
+
+		themeColors: cfg.Colors,
+
+		strikeDone:     cfg.StrikeDone,
+		checkboxes:     cfg.Checkboxes,
+		priorityColors: cfg.PriorityColors,
+		dueColors:      cfg.DueColors,
+		confirmDelete:  cfg.ConfirmDelete,
+
+		waitingInline: cfg.WaitingInline,
+		progressDone:  cfg.ProgressDone,
+		focusLength:   time.Duration(max(cfg.FocusMinutes, 0)) * time.Minute,
+
+		dueReminders: cfg.DueReminders,
+		dueBell:      cfg.DueBell,
+		quietHours:   quiet,
+		remindLead:   max(cfg.ReminderLeadDays, 0),
+
+		bumpOverdueOn: cfg.BumpOverdue,
+
+		textRules: textRules{trim: cfg.TrimSpace, collapse: cfg.CollapseSpaces, capitalize: cfg.Capitalize},
+		icons:     cfg.Icons,
+
+		csvColumns: cfg.CSVColumns,
+		tagColors:  cfg.TagColors,
+		quick:      cfg.QuickActions,
+		hooks:      cfg.Hooks,
+
+		keys: defaultKeyMap(),
+
+		archiveOnDone: cfg.ArchiveOnDone,
+		archiveDelay:  time.Duration(cfg.ArchiveDelaySeconds) * time.Second,
+
+		ageWarn:  time.Duration(max(cfg.AgeWarnDays, 0)) * 24 * time.Hour,
+		ageAlert: time.Duration(cfg.AgeAlertDays) * 24 * time.Hour,
+
+		idleLock: time.Duration(max(cfg.IdleLockMinutes, 0)) * time.Minute,
+		lastKey:  time.Now(),
+
+		lists:       lists,
+		active:      active,
+		prevList:    -1,
+		snoozeHour:  cfg.SnoozeHour,
+		listCap:     cfg.ListCap,
+		listCaps:    cfg.ListCaps,
+		folded:      map[string]bool{},
+		promptInput: pi,
+		searchInput: si,
+		filterInput: fi,
+		notesArea:   newNotesArea(),
+		sortedBy:    -1,
+
+		dataFile: path,
+		backups:  max(cfg.Backups, 0),
+		dirty:    len(fixes) > 0, // Save the repairs straight away
+	}
+	for _, path := range saved.Folded {
+		m.folded[path] = true
+	}
+	detectColors()
+	m.setColors(colorsNamed(saved.Colors))
+	m.themeDefault, _ = themeNamed(cfg.Theme)
+	current, ok := themeNamed(saved.Theme)
+	if !ok {
+		current = m.themeDefault
+	}
+	m.setTheme(current)
+	if saved.HideHints != nil {
+		m.hideHints = *saved.HideHints
 	}
+	if saved.RelativeDue != nil {
+		m.relativeDue = *saved.RelativeDue
+	}
+	if saved.Goals != nil {
+		m.dailyGoal, m.weeklyGoal = saved.Goals[0], saved.Goals[1]
+	}
+	m.lastRun = saved.LastRun
+	m.doneOpen = saved.DoneOpen
+	if archived, err := loadArchive(archivePath(path)); err == nil {
+		m.archivedDone = doneStamps(archived)
+	}
+	if m.highlightCursor {
+		m.icons.Cursor = "" // The highlight takes the glyph's place
+	}
+	m.openList()
+	if m.assignIDs() {
+		m.dirty = true // Keep the new IDs from the start
+	}
+	return m
 }
 
 // Init implements tea.Model - called once when the program starts
 func (m model) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{tickClock(), tickAutosave(), checkDueNow}
+	if m.state == inputting || m.state == prompting {
+		cmds = append(cmds, textinput.Blink)
+	}
+	// A stopwatch left running when the app last closed carries on
+	if m.runningTimer() != nil {
+		cmds = append(cmds, tickTimer(m.timerID))
+	}
+	if m.idleLock > 0 {
+		cmds = append(cmds, tickIdle(m.idleLock))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update implements tea.Model - handles all messages and user input
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	nm, ok := next.(model)
+	if !ok {
+		return next, cmd
+	}
+
+	// Archive the tasks just finished, at once or after a pause
+	if len(nm.pendingArchive) > 0 {
+		cmd = tea.Batch(cmd, nm.archiveFinished())
+	}
+
+	// Keep the cursor on screen after it moves or the layout changes
+	nm.scroll()
+
+	// Persist whatever just changed, and where each list was left on quit,
+	// unless quitting was to throw the changes away
+	if (nm.dirty || nm.quitting) && !nm.discard {
+		nm.save()
+	}
+
+	// Run the hooks on what just changed, waiting for them only on the way out
+	if nm.quitting {
+		nm.runHooksNow()
+	} else if len(nm.pendingHooks) > 0 {
+		cmd = tea.Batch(cmd, nm.flushHooks())
+	}
+
+	// Keep the HTTP view in step with whatever just changed
+	if nm.server != nil {
+		nm.server.publish(nm.tasks)
+	}
+	return nm, cmd
+}
+
+// update dispatches a message to the handler for the current state
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Notices and highlights only last until the next key press
+		m.notice = ""
+		m.picked = -1
+
+		// While locked, a key press only unlocks, and restarts the idle count
+		m.lastKey = time.Now()
+		if m.locked {
+			m.locked = false
+			m.notice = "Unlocked"
+			return m, nil
+		}
+
 		// Handle key presses based on current state
 		switch m.state {
 		case browsing:
@@ -95,156 +725,2673 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateInputting(msg)
 		case helping:
 			return m.updateHelping(msg)
+		case viewingHistory:
+			return m.updateViewingHistory(msg)
+		case pickingList:
+			return m.updatePickingList(msg)
+		case prompting:
+			return m.updatePrompting(msg)
+		case pickingDate:
+			return m.updatePickingDate(msg)
+		case viewingTags:
+			return m.updateViewingTags(msg)
+		case reviewing:
+			return m.updateReviewing(msg)
+		case searching:
+			return m.updateSearching(msg)
+		case filtering:
+			return m.updateFiltering(msg)
+		case viewingDetail:
+			return m.updateViewingDetail(msg)
+		case previewingImport:
+			return m.updatePreviewingImport(msg)
+		case replacing:
+			return m.updateReplacing(msg)
+		case viewingAgenda:
+			return m.updateViewingAgenda(msg)
+		case pickingEmoji:
+			return m.updatePickingEmoji(msg)
+		case viewingDashboard:
+			return m.updateViewingDashboard(msg)
+		case viewingNext:
+			return m.updateViewingNext(msg)
+		case confirmingDelete:
+			return m.updateConfirmingDelete(msg)
+		case editingNotes:
+			return m.updateEditingNotes(msg)
+		case confirmingQuit:
+			return m.updateConfirmingQuit(msg)
+		case viewingArchive:
+			return m.updateViewingArchive(msg)
 		}
-	}
-	return m, nil
-}
-
-// updateBrowsing handles key input when in browse mode
-func (m model) updateBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	// Quit commands
-	case "q", "esc", "ctrl+c":
-		m.quitting = true
-		return m, tea.Quit
 
-	// Navigation: move up
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
+	// Clicks and the wheel count as key presses do, but only the list
+	// answers them
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
 		}
-
-	// Navigation: move down
-	case "down", "j":
-		if m.cursor < len(m.tasks)-1 {
-			m.cursor++
+		m.lastKey = time.Now()
+		if m.locked {
+			m.locked = false
+			m.notice = "Unlocked"
+			return m, nil
+		}
+		if m.state == browsing {
+			m.notice, m.picked, m.pendingG, m.jumpDigits = "", -1, false, ""
+			m.handleMouse(msg)
 		}
+		return m, nil
 
-	// Add new task: switch to input mode
-	case "n", "a":
-		m.state = inputting
-		m.input.Focus()
-		return m, textinput.Blink
+	// Pause the cursor blink while the terminal is in the background
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, m.input.Cursor.SetMode(cursor.CursorStatic)
 
-	// Toggle help
-	case "?", "h":
-		m.state = helping
+	case tea.FocusMsg:
+		m.blurred = false
+		return m, m.input.Cursor.SetMode(cursor.CursorBlink)
 
-	// Delete/Complete task
-	case "x", "backspace", "d":
-		if len(m.tasks) > 0 {
-			// Remove the selected task
-			m.tasks = append(m.tasks[:m.cursor], m.tasks[m.cursor+1:]...)
-			// Adjust cursor if needed
-			if m.cursor >= len(m.tasks) && m.cursor > 0 {
-				m.cursor--
-			}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case archiveDueMsg:
+		m.autoArchive(msg, true)
+		return m, nil
+
+	case pickFadeMsg:
+		m.picked = -1
+		if m.notice == addedNotice {
+			m.notice = ""
 		}
-	}
-	return m, nil
-}
+		return m, nil
 
-// updateHelping handles key input when in help mode
-func (m model) updateHelping(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "q", "?", "h", "enter":
-		m.state = browsing
-	}
-	return m, nil
-}
+	// Keep the stopwatch display ticking while it runs
+	case timerTickMsg:
+		if int(msg) == m.timerID && m.runningTimer() != nil {
+			if m.focusLength > 0 && time.Since(m.runningTimer().TimerStarted) >= m.focusLength {
+				return m.endFocus()
+			}
+			return m, tickTimer(m.timerID)
+		}
+		return m, nil
 
-// updateInputting handles key input when in input mode
-func (m model) updateInputting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+	case countdownTickMsg:
+		if int(msg) == m.countdownID && m.countdown {
+			return m, tickCountdown(m.countdownID)
+		}
+		return m, nil
 
-	switch msg.String() {
-	// Cancel input and return to browse mode
-	case "esc":
-		m.state = browsing
-		m.input.Reset()
+	case jumpMsg:
+		if int(msg) == m.jumpID && m.jumpDigits != "" && m.state == browsing {
+			m.jumpTo(m.jumpDigits)
+			m.jumpDigits = ""
+		}
 		return m, nil
 
-	// Submit the new task
-	case "enter":
-		value := m.input.Value()
-		if value != "" {
-			m.tasks = append(m.tasks, value)
-			m.cursor = len(m.tasks) - 1 // Move cursor to new task
+	case hookDoneMsg:
+		if msg.err != nil {
+			m.hookFailed(msg.event, msg.err)
 		}
-		m.state = browsing
-		m.input.Reset()
 		return m, nil
-	}
 
-	// Update the text input component
-	m.input, cmd = m.input.Update(msg)
-	return m, cmd
-}
+	case clearDisarmMsg:
+		if m.clearArmed && int(msg) == m.clearArmID {
+			m.clearArmed = false
+			if m.notice == clearArmedNotice {
+				m.notice = ""
+			}
+		}
+		return m, nil
 
-// View implements tea.Model - renders the UI
-func (m model) View() string {
-	if m.quitting {
-		return "Goodbye! ✨\n"
-	}
+	// The re-render picks up the new time; a new day, or the end of quiet
+	// hours, brings a reminder of what's due
+	case clockMsg:
+		m.bumpOverdue(time.Time(msg))
+		remind := m.remindDue(time.Time(msg))
+		return m, tea.Batch(tickClock(), remind)
 
-	var s string
+	case dueCheckMsg:
+		m.bumpOverdue(time.Time(msg))
+		remind := m.remindDue(time.Time(msg))
+		return m, remind
 
-	// Title
-	s += titleStyle.Render("📝 To-Do") + "\n\n"
+	case idleCheckMsg:
+		return m, m.checkIdle(time.Now())
 
-	// Render task list
-	if len(m.tasks) == 0 {
-		s += normalStyle.Render("No tasks yet. Press 'n' to add one.") + "\n"
-	} else {
-		for i, task := range m.tasks {
-			if i == m.cursor {
-				// Selected item with cursor indicator
-				s += cursorStyle.Render("→ ") + selectedStyle.Render(task) + "\n"
-			} else {
-				// Unselected items
-				s += normalStyle.Render(task) + "\n"
-			}
+	// Changes are saved as they're made, so this only writes when one
+	// couldn't be; either way the next tick is scheduled
+	case autosaveMsg:
+		if m.dirty && !m.discard {
+			m.save()
 		}
+		return m, tickAutosave()
 	}
 
-	// Render input field when in input mode
-	if m.state == inputting {
-		s += "\n" + inputPromptStyle.Render("New Task:") + "\n"
-		s += "  " + m.input.View() + "\n"
+	// Anything else (such as cursor blinks) belongs to the text input
+	var cmd tea.Cmd
+	switch m.state {
+	case inputting:
+		m.input, cmd = m.input.Update(msg)
+	case prompting:
+		m.promptInput, cmd = m.promptInput.Update(msg)
+	case searching:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+	case filtering:
+		m.filterInput, cmd = m.filterInput.Update(msg)
+	case editingNotes:
+		m.notesArea, cmd = m.notesArea.Update(msg)
 	}
+	return m, cmd
+}
 
-	// Render help text or help view
-	s += "\n"
-	if m.state == browsing {
-		s += helpStyle.Render("↑/↓: navigate • n: add • x: delete • ?: help • q: quit")
-	} else if m.state == inputting {
-		s += helpStyle.Render("enter: save • esc: cancel")
-	} else if m.state == helping {
-		s = titleStyle.Render("📖 Help & Commands") + "\n\n"
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("Navigation:") + "\n"
-		s += normalStyle.Render("↑ / k      - Move selection up") + "\n"
-		s += normalStyle.Render("↓ / j      - Move selection down") + "\n\n"
+// updateBrowsing handles key input when in browse mode
+func (m model) updateBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.visible()
 
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("Tasks:") + "\n"
-		s += normalStyle.Render("n / a      - Add a new task (New/Add)") + "\n"
-		s += normalStyle.Render("x / d / bk - Remove selected task (Delete)") + "\n"
-		s += normalStyle.Render("Enter      - Confirm new task (In input mode)") + "\n\n"
+	// Any key other than a confirming X disarms a pending clear-all
+	armed := m.clearArmed
+	m.clearArmed = false
 
-		s += lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("Application:") + "\n"
-		s += normalStyle.Render("? / h      - Toggle this help view") + "\n"
-		s += normalStyle.Render("q / Esc    - Return to list or Quit") + "\n"
-		s += normalStyle.Render("Ctrl+C     - Force quit") + "\n\n"
+	// Likewise any key other than another digit ends a task number
+	digits := m.jumpDigits
+	m.jumpDigits = ""
 
-		s += helpStyle.Render("Press any key to return...")
+	// And any key but a second g drops a pending gg
+	gPressed := m.pendingG
+	m.pendingG = false
+
+	// Keys the config binds to quick actions never clash with those below
+	if q, ok := m.quickAction(msg.String()); ok {
+		m.runQuick(q)
+		return m, nil
 	}
 
-	return s + "\n"
-}
+	// Keys from the key map come before the rest, and each ends here
+	switch {
+	// Quit commands
+	case key.Matches(msg, m.keys.Quit, m.keys.ForceQuit):
+		// Esc drops any marks, then the filter, then the frog banner,
+		// before it quits
+		if msg.String() == "esc" && len(m.marked()) > 0 {
+			m.clearMarks()
+			m.notice = "Unmarked all tasks"
+			return m, nil
+		}
+		if msg.String() == "esc" && m.filterQuery != "" {
+			m.setFilter("")
+			m.notice = "Filter cleared"
+			return m, nil
+		}
+		if _, ok := m.frog(); msg.String() == "esc" && m.frogBanner && ok {
+			m.frogBanner = false
+			return m, nil
+		}
+		// Changes the last save couldn't write are asked about first,
+		// unless a force quit insists
+		if m.dirty && !key.Matches(msg, m.keys.ForceQuit) {
+			m.state = confirmingQuit
+			return m, nil
+		}
+		return m.quit()
 
-func main() {
-	p := tea.NewProgram(initialModel())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+	// Navigation: move up
+	case key.Matches(msg, m.keys.Up):
+		m.step(rows, -1)
+		return m, nil
+
+	// Navigation: move down
+	case key.Matches(msg, m.keys.Down):
+		m.step(rows, 1)
+		return m, nil
+
+	// Add new task: switch to input mode
+	case key.Matches(msg, m.keys.Add):
+		m.state = inputting
+		m.input.Focus()
+		return m, textinput.Blink
+
+	// Edit the selected task's text
+	case key.Matches(msg, m.keys.Edit):
+		if i, ok := m.selected(); ok {
+			return m.startEdit(i)
+		}
+		return m, nil
+
+	// Cycle the selected task's status: todo → doing → done
+	case key.Matches(msg, m.keys.Status):
+		if i, ok := m.selected(); ok {
+			m.setStatus(i, m.tasks[i].Status.next())
+		}
+		return m, nil
+
+	// Delete task, or every marked one, once confirmed when confirmDelete
+	// is on
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.marked()) > 0 {
+			if m.confirmDelete {
+				m.pendingClear, m.pendingMarked = false, true
+				m.state = confirmingDelete
+				return m, nil
+			}
+			m.deleteMarked()
+			return m, nil
+		}
+		if i, ok := m.selected(); ok {
+			if m.confirmDelete {
+				m.pendingDelete, m.pendingClear, m.pendingMarked = i, false, false
+				m.state = confirmingDelete
+				return m, nil
+			}
+			m.deleteTask(i)
+		}
+		return m, nil
+
+	// Undo or redo the most recent change
+	case key.Matches(msg, m.keys.Undo):
+		if !m.undoChange() {
+			m.notice = "Nothing to undo"
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Redo):
+		if !m.redoChange() {
+			m.notice = "Nothing to redo"
+		}
+		return m, nil
+
+	// Search every list
+	case key.Matches(msg, m.keys.Search):
+		return m.startSearch()
+
+	// Narrow the open list to the tasks matching a query
+	case key.Matches(msg, m.keys.Filter):
+		return m.startFilter()
+
+	// Open the list switcher
+	case key.Matches(msg, m.keys.Lists):
+		if !m.soloBlocks() {
+			m.listCursor, m.onFolder = m.active, ""
+			m.state = pickingList
+		}
+		return m, nil
+
+	// Toggle help
+	case key.Matches(msg, m.keys.Help):
+		m.state = helping
+		return m, nil
+	}
+
+	switch msg.String() {
+	// Type a task number to jump to it once typing pauses
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if digits == "" && msg.String() == "0" {
+			break
+		}
+		m.jumpDigits = digits + msg.String()
+		m.jumpID++
+		m.notice = "Go to task " + m.jumpDigits + "…"
+		id := m.jumpID
+		return m, tea.Tick(jumpPause, func(time.Time) tea.Msg { return jumpMsg(id) })
+
+	// Jump to the typed task number straight away, or to the last task
+	case "G":
+		if digits == "" && len(rows) == 0 {
+			break
+		}
+		if digits == "" {
+			digits = strconv.Itoa(len(rows))
+		}
+		m.jumpTo(digits)
+
+	// gg jumps to the first task
+	case "g":
+		if !gPressed {
+			m.pendingG = true
+		} else if len(rows) > 0 {
+			m.cursor = 0
+		}
+
+	// Move half a screen up or down
+	case "ctrl+u":
+		m.halfPage(rows, -1)
+	case "ctrl+d":
+		m.halfPage(rows, 1)
+
+	// Capture a task straight into the inbox without leaving this list
+	case "A":
+		m.capturing = m.inbox() >= 0 && !m.solo
+		m.state = inputting
+		m.input.Focus()
+		return m, textinput.Blink
+
+	// Sort the inbox into the other lists
+	case "i":
+		if m.soloBlocks() {
+			break
+		}
+		return m.startProcessing()
+
+	// Replace text across the open list's tasks
+	case "%":
+		return m.startReplace()
+
+	// Pin the selected task above the rest, or unpin it
+	case "^":
+		i, ok := m.selected()
+		if !ok {
+			break
+		}
+		if m.tasks[i].Depth > 0 {
+			m.notice = "Only top-level tasks can be pinned"
+			break
+		}
+		m.checkpoint()
+		m.tasks[i].Pinned = !m.tasks[i].Pinned
+		if m.tasks[i].Pinned {
+			m.record("pin", m.tasks[i].Text)
+		} else {
+			m.record("unpin", m.tasks[i].Text)
+		}
+		m.moveCursorTo(i)
+
+	// Open or close the fold holding finished tasks
+	case "ctrl+o":
+		if !m.foldDone {
+			m.notice = "Set foldCompleted in the config to gather finished tasks in a fold"
+			break
+		}
+		real, ok := m.selected()
+		m.doneOpen = !m.doneOpen
+		m.dirty = true
+		if ok {
+			m.moveCursorTo(real)
+		}
+
+	// Keep the selected task above the rest for a few days
+	case "ctrl+t":
+		return m.startBoost()
+
+	// Sink tasks that aren't due for a while below the rest, or stop
+	case "F":
+		real, ok := m.selected()
+		m.sinkFuture = !m.sinkFuture
+		if ok {
+			m.moveCursorTo(real)
+		}
+		if m.sinkFuture {
+			m.notice = fmt.Sprintf("Tasks due more than %d days out sink to the bottom", m.sinkDays)
+		} else {
+			m.notice = "Showing tasks in list order"
+		}
+
+	// Show or hide the key panel beside the list
+	case "K":
+		m.cheatSheet = !m.cheatSheet
+
+	// Strip the list down to the tasks' text, or bring everything back
+	case "Z":
+		m.zen = !m.zen
+		if m.zen {
+			m.notice = "Zen view • Z to leave"
+		}
+
+	// Show or hide the selected task's details beside the list
+	case "|":
+		m.split = !m.split
+		switch {
+		case !m.split:
+			m.notice = "Detail pane off • | brings it back"
+		case m.paneWidth() == 0:
+			m.notice = fmt.Sprintf("The detail pane shows once the terminal is %d columns wide", splitWidth)
+		}
+
+	// Show the history of changes
+	case "H":
+		m.state = viewingHistory
+
+	// Cycle through lists
+	case "tab":
+		if !m.soloBlocks() {
+			m.switchList((m.active + 1) % len(m.lists))
+		}
+	case "shift+tab":
+		if !m.soloBlocks() {
+			m.switchList((m.active + len(m.lists) - 1) % len(m.lists))
+		}
+
+	// Put the selected task off until tomorrow morning, or bring it back
+	case "ctrl+n":
+		m.snoozeTillMorning()
+
+	// Flip back to the list open before this one
+	case "`":
+		if !m.soloBlocks() {
+			m.switchBack()
+		}
+
+	// Hide every other list, or bring them back
+	case "o":
+		m.solo = !m.solo
+		if m.solo {
+			m.notice = "Only " + m.lists[m.active].label() + " • o to show every list"
+		} else {
+			m.notice = "Showing every list"
+		}
+
+	// Switch between wrapping and truncating long tasks
+	case "z":
+		m.wrap = !m.wrap
+		if m.wrap {
+			m.notice = "Wrapping long tasks"
+		} else {
+			m.notice = "Truncating long tasks"
+		}
+
+	// Choose another list to move the selected task to
+	case "M":
+		if _, ok := m.selected(); ok && !m.soloBlocks() {
+			m.moving = true
+			m.listCursor, m.onFolder = m.active, ""
+			m.state = pickingList
+		}
+
+	// Hide or show the key hints under the list
+	case "b":
+		m.hideHints = !m.hideHints
+		m.dirty = true
+		if m.hideHints {
+			m.notice = "Key hints hidden • b brings them back, ? shows every key"
+		}
+
+	// Switch due dates between dates and how far off they are
+	case "@":
+		m.relativeDue = !m.relativeDue
+		m.dirty = true
+		if m.relativeDue {
+			m.notice = "Due dates relative to today • @ shows them as dates"
+		} else {
+			m.notice = "Due dates as dates • @ shows how far off they are"
+		}
+
+	// Show or hide each task's ID at the end of its row
+	case "ctrl+g":
+		m.showIDs = !m.showIDs
+		if m.showIDs {
+			m.notice = "Showing task IDs, as exports use them • ctrl+g hides them"
+		}
+
+	// Show or hide how long ago each task was added
+	case "ctrl+w":
+		m.showAge = !m.showAge
+		if m.showAge {
+			m.notice = "Showing how long ago each task was added • ctrl+w hides it"
+		}
+
+	// Try the next color mode, for terminals that misreport what they support
+	case "ctrl+p":
+		m.cycleColors()
+
+	// Switch to the next built-in theme, as for a light background
+	case "ctrl+b":
+		m.cycleTheme()
+
+	// Toggle metadata between trailing the text and its own line
+	case "I":
+		m.detailed = !m.detailed
+
+	// Export what was finished, day by day, for a report
+	case "J":
+		return m.startDoneLog()
+
+	// Export the list's dated tasks as calendar events
+	case "E":
+		m.exportActive(icsExporter{now: time.Now()}, m.activeList(), "tasks with due dates")
+
+	// Export the list as a spreadsheet
+	case "C":
+		m.exportActive(csvExporter{columns: m.csvColumns}, m.activeList(), "tasks")
+
+	// Export the list as a Markdown checklist, as it's shown
+	case "ctrl+e":
+		m.exportActive(mdExporter{}, m.shownList(), "tasks")
+
+	// Start or stop the stopwatch on the selected task
+	case "t":
+		if i, ok := m.selected(); ok {
+			return m.toggleTimer(i)
+		}
+
+	// Go through unfinished tasks one at a time
+	case "R":
+		return m.startReview()
+
+	// Mark the selected task as waiting on someone
+	case "w":
+		if i, ok := m.selected(); ok {
+			return m.startPrompt(promptWaitingOn, "Waiting on:", m.tasks[i].WaitingOn)
+		}
+
+	// Set how long before the selected task is due to be reminded
+	case "B":
+		if i, ok := m.selected(); ok {
+			label := fmt.Sprintf("Remind how many days before it's due? (0: the default, %d)", m.remindLead)
+			return m.startPrompt(promptLead, label, strconv.Itoa(m.tasks[i].Lead))
+		}
+
+	// Attach a file to the selected task, or open those attached
+	case "l":
+		return m.startAttach()
+	case "m":
+		m.openAttachments()
+
+	// Switch between active work and the tasks that are waiting
+	case "W":
+		m.waitingView = !m.waitingView
+		m.cursor = 0
+
+	// Go to the task to do first
+	case "f":
+		m.eatFrog()
+
+	// Sum up every list, to pick one to open
+	case "s":
+		m.dashCursor = m.active
+		m.state = viewingDashboard
+
+	// Just the next thing to do in each list
+	case "ctrl+a":
+		m.nextCursor = m.active
+		m.state = viewingNext
+
+	// Lay out the week ahead, day by day
+	case "N":
+		m.agendaCursor = 0
+		m.state = viewingAgenda
+
+	// Open the tag overview, highlighting the current tag filter
+	case "T":
+		m.tagCursor = 0
+		for i, c := range m.tagCounts() {
+			if c.Tag == m.tagFilter {
+				m.tagCursor = i + 1
+			}
+		}
+		m.state = viewingTags
+
+	// Push every overdue task forward, asking by how much
+	case "S":
+		n := len(m.overdueTasks(time.Now()))
+		if n == 0 {
+			m.notice = "Nothing is overdue"
+			break
+		}
+		return m.startPrompt(promptSnoozeOverdue, fmt.Sprintf("Reschedule %d overdue tasks to (1d, 2w, fri, tomorrow…):", n), "1d")
+
+	// Pick the selected task's due date from a calendar
+	case "D":
+		if i, ok := m.selected(); ok {
+			return m.startDatePicker(i)
+		}
+
+	// Pick an emoji to lead the selected task with
+	case ":":
+		if i, ok := m.selected(); ok {
+			return m.startEmojiPicker(i)
+		}
+
+	// Cycle the selected task's priority
+	case "p":
+		if i, ok := m.selected(); ok {
+			m.checkpoint()
+			t := &m.tasks[i]
+			t.Priority = t.Priority.next()
+			m.record("priority "+t.Priority.String(), t.Text)
+		}
+
+	// Merge tasks with the same text into one
+	case "U":
+		return m.startMergeDupes()
+
+	// Count down to the selected task's deadline, or stop
+	case "c":
+		return m.toggleCountdown()
+
+	// Mark or unmark the selected task for a bulk action, moving on to the
+	// next so runs of tasks are quick to mark. Marks aren't saved, so like
+	// the cursor they're no change for undo or the history.
+	case "v":
+		if i, ok := m.selected(); ok {
+			m.tasks[i].Marked = !m.tasks[i].Marked
+			m.step(rows, 1)
+		}
+
+	// Make the selected task repeat daily or weekly, or stop
+	case ".":
+		m.cycleRecurrence()
+
+	// Mark every task shown, or unmark them all
+	case "*":
+		m.markAll()
+
+	// Add a tag to, or remove one from, every marked task
+	case "V":
+		return m.startBulkTag()
+
+	// Tag the selected task with today's date, or untag it
+	case "#":
+		if i, ok := m.selected(); ok {
+			m.toggleTodayTag(i, time.Now())
+		}
+
+	// Step the selected task's progress up or down
+	case "+", "=":
+		if i, ok := m.selected(); ok {
+			m.addProgress(i, progressStep)
+		}
+	case "-":
+		if i, ok := m.selected(); ok {
+			m.addProgress(i, -progressStep)
+		}
+
+	// Narrow the list by priority: all → medium and up → high only
+	case "!":
+		real, _ := m.selected()
+		switch m.minPriority {
+		case priorityNone:
+			m.minPriority = priorityMedium
+		case priorityMedium:
+			m.minPriority = priorityHigh
+		default:
+			m.minPriority = priorityNone
+		}
+		m.moveCursorTo(real)
+
+	// Show every task, then only those left to do, then only finished ones
+	case "~":
+		real, _ := m.selected()
+		m.doneFilter = (m.doneFilter + 1) % 3
+		m.moveCursorTo(real)
+		switch m.doneFilter {
+		case showActive:
+			m.notice = "Showing tasks left to do • ~ for finished ones"
+		case showDone:
+			m.notice = "Showing finished tasks • ~ for everything"
+		}
+
+	// Jump to a random task that still needs doing
+	case "r":
+		var pool []int
+		for row, i := range rows {
+			if m.tasks[i].Status != statusDone && !m.skipped(rows, row) {
+				pool = append(pool, row)
+			}
+		}
+		if len(pool) == 0 {
+			m.notice = "Nothing left to pick from"
+			break
+		}
+		m.cursor = pool[rand.Intn(len(pool))]
+		m.picked = m.cursor
+		return m, tea.Tick(pickHighlight, func(time.Time) tea.Msg { return pickFadeMsg{} })
+
+	// Whatever enterAction is set to
+	case "enter":
+		if i, ok := m.selected(); ok {
+			return m.pressEnter(i)
+		}
+
+	// Delete every finished task, once confirmed when confirmDelete is on
+	case "ctrl+k":
+		if len(finishedSubtrees(m.tasks)) == 0 {
+			m.notice = "Nothing finished to clear"
+			break
+		}
+		if m.confirmDelete {
+			m.pendingClear, m.pendingMarked = true, false
+			m.state = confirmingDelete
+			break
+		}
+		m.clearDone()
+
+	// Move every finished task into the archive file, or look through it
+	case "ctrl+f":
+		m.archiveDone()
+	case "ctrl+l":
+		return m.startArchive()
+
+	// Clear every task in the list: the first press arms, a second press
+	// within clearWindow confirms
+	case "X":
+		if len(m.tasks) == 0 {
+			m.notice = "Nothing to clear"
+			break
+		}
+		if !armed {
+			m.clearArmed = true
+			m.clearArmID++
+			m.notice = clearArmedNotice
+			id := m.clearArmID
+			return m, tea.Tick(clearWindow, func(time.Time) tea.Msg { return clearDisarmMsg(id) })
+		}
+		m.checkpoint()
+		for _, t := range m.tasks {
+			m.record("delete", t.Text)
+		}
+		m.notice = fmt.Sprintf("Cleared %d tasks (u to undo)", len(m.tasks))
+		m.tasks = []task{}
+		m.cursor = 0
+
+	// Nest the selected task under the one above it
+	case ">":
+		if i, ok := m.selected(); ok && i > 0 && m.tasks[i].Depth <= m.tasks[i-1].Depth {
+			m.checkpoint()
+			end := subtreeEnd(m.tasks, i)
+			for j := i; j < end; j++ {
+				m.tasks[j].Depth++
+			}
+			// Keep the task in view if its new parent was collapsed
+			for p := i - 1; p >= 0; p-- {
+				if m.tasks[p].Depth < m.tasks[i].Depth {
+					m.tasks[p].Collapsed = false
+					break
+				}
+			}
+			m.moveCursorTo(i)
+		}
+
+	// Move the selected task out one level
+	case "<":
+		if i, ok := m.selected(); ok && m.tasks[i].Depth > 0 {
+			m.checkpoint()
+			end := subtreeEnd(m.tasks, i)
+			for j := i; j < end; j++ {
+				m.tasks[j].Depth--
+			}
+		}
+
+	// Move the selected task, with its subtasks, above or below its neighbor
+	case "shift+up":
+		m.shiftTask(-1)
+	case "shift+down":
+		m.shiftTask(1)
+
+	// Copy the selected task, with its subtasks, to just below them
+	case "y":
+		m.duplicateTask()
+
+	// Sort the list in the next order: date added, name, status, priority, due
+	case ",":
+		m.sortList()
+
+	// Write or edit the selected task's notes
+	case ";":
+		if i, ok := m.selected(); ok {
+			return m.startNotes(i)
+		}
+
+	// Collapse or expand the selected task's subtasks
+	case "left", "right":
+		if i, ok := m.selected(); ok && hasChildren(m.tasks, i) && m.tasks[i].Collapsed != (msg.String() == "left") {
+			m.checkpoint()
+			m.tasks[i].Collapsed = msg.String() == "left"
+		}
+
+	// Collapse every parent, or expand them all if none are expanded
+	case "O":
+		collapse := false
+		for i := range m.tasks {
+			if hasChildren(m.tasks, i) && !m.tasks[i].Collapsed {
+				collapse = true
+				break
+			}
+		}
+		real, _ := m.selected()
+		m.checkpoint()
+		for i := range m.tasks {
+			m.tasks[i].Collapsed = collapse && hasChildren(m.tasks, i)
+		}
+		// Land on the selected task, or the top-level task hiding it
+		for real > 0 && m.tasks[real].Depth > 0 && collapse {
+			real--
+		}
+		m.moveCursorTo(real)
+
+	// Copy the selected task (with any hidden subtasks) into the register
+	case "Y":
+		if i, ok := m.selected(); ok {
+			m.register = m.block(i)
+			m.registerCut = false
+			m.notice = "Copied to register"
+		}
+
+	// Copy everything about the selected task to the system clipboard
+	case "ctrl+y":
+		m.copyDetails()
+
+	// Cut the selected task into the register
+	case "ctrl+x":
+		if i, ok := m.selected(); ok {
+			m.checkpoint()
+			m.register = m.removeTask(i)
+			m.registerCut = true
+			for _, t := range m.register {
+				m.record("cut", t.Text)
+			}
+			m.notice = "Cut to register"
+		}
+
+	// Import each line on the system clipboard as a new task
+	case "ctrl+v":
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			m.notice = "Could not read clipboard: " + err.Error()
+			break
+		}
+		var pasted []task
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				pasted = append(pasted, task{Text: truncateRunes(line, m.input.CharLimit)})
+			}
+		}
+		if m.importReplace && len(m.tasks) > 0 && len(pasted) > 0 {
+			return m.startImportPreview("clipboard", pasted, nil), nil
+		}
+		if len(pasted) > 0 {
+			m.checkpoint()
+		}
+		for _, t := range pasted {
+			m.addTask(t)
+		}
+		if len(pasted) > 0 {
+			m.moveCursorTo(len(m.tasks) - 1)
+		}
+		m.notice = fmt.Sprintf("Imported %d tasks from clipboard", len(pasted))
+
+	// Paste the register below the selected task, as its sibling
+	case "P":
+		if m.register == nil {
+			m.notice = "Register is empty"
+			break
+		}
+		at, depth := 0, 0
+		if i, ok := m.selected(); ok {
+			at, depth = i+1, m.tasks[i].Depth
+			if m.tasks[i].Collapsed {
+				at = subtreeEnd(m.tasks, i)
+			}
+		}
+		m.checkpoint()
+
+		// Re-root the pasted block at the sibling depth
+		block := make([]task, len(m.register))
+		for j, t := range m.register {
+			t.Depth += depth - m.register[0].Depth
+			t.TimerStarted = time.Time{} // Only the original keeps timing
+			block[j] = t
+		}
+		m.tasks = append(m.tasks[:at], append(block, m.tasks[at:]...)...)
+		m.moveCursorTo(at)
+		for _, t := range block {
+			m.record("paste", t.Text)
+		}
+		if m.registerCut {
+			m.register = nil
+		}
+	}
+	return m, nil
+}
+
+// halfPage moves the cursor half as many rows as fit on screen in
+// direction dir, stopping at either end of the list
+func (m *model) halfPage(rows []int, dir int) {
+	if len(rows) == 0 {
+		return
+	}
+	budget, _ := m.rowBudget(rows, m.viewTasksHeader(), m.viewTasksFooter())
+	half := max(min(budget, len(rows))/2, 1)
+	m.cursor = max(min(m.cursor+dir*half, len(rows)-1), 0)
+	if m.skipped(rows, m.cursor) {
+		m.step(rows, dir)
+	}
+}
+
+// jumpTo moves the cursor to task number n of the visible rows, counting
+// from one, or says so when there's no such task
+func (m *model) jumpTo(n string) {
+	rows := m.visible()
+	row, err := strconv.Atoi(n)
+	if err != nil || row < 1 || row > len(rows) {
+		m.notice = fmt.Sprintf("No task %s; there are %d", n, len(rows))
+		return
+	}
+	m.cursor = row - 1
+	m.notice = ""
+}
+
+// addTask appends t to the end of the active list, stamping when it was
+// created unless it already says
+func (m *model) addTask(t task) {
+	if t.Created.IsZero() {
+		t.Created = time.Now()
+	}
+	m.record("add", t.Text)
+	m.tasks = append(m.tasks, t)
+}
+
+// showAdded deals with task i, just added, when the filters hide it:
+// filteredAdd either lifts them or leaves a notice saying where it went
+func (m *model) showAdded(i int) {
+	if slices.Contains(m.visible(), i) {
+		return
+	}
+	if m.clearOnAdd {
+		m.reveal(i)
+		m.notice = "Added; filters cleared to show it"
+		return
+	}
+	m.notice = "Added, but hidden by the current filter"
+}
+
+// startEdit opens a prompt to edit the text of task i
+func (m model) startEdit(i int) (tea.Model, tea.Cmd) {
+	m.editing = i
+	return m.startPrompt(promptEditTask, "Edit task:", m.tasks[i].Text)
+}
+
+// parseInput turns a line typed for a task into the task, with its inline
+// attributes pulled out and its text tidied up. The task has priority p
+// unless the line sets one.
+func (m model) parseInput(value string, p priority) task {
+	t := parseTask(value, time.Now(), p)
+	t.Text = truncateRunes(m.textRules.apply(t.Text), m.input.CharLimit)
+	return t
+}
+
+// editTask replaces the text of the task being edited. Inline attributes in
+// the new text set its priority or due date or add tags, as when adding.
+func (m *model) editTask(value string) {
+	edited := m.parseInput(value, m.tasks[m.editing].Priority)
+	if strings.TrimSpace(edited.Text) == "" {
+		return
+	}
+	m.checkpoint()
+	t := &m.tasks[m.editing]
+	t.Text = edited.Text
+	t.Priority = edited.Priority
+	if !edited.Due.IsZero() {
+		t.Due = edited.Due
+	}
+	if edited.Recur != recurNone {
+		t.Recur, t.RecurDays = edited.Recur, edited.RecurDays
+	}
+	for _, tag := range edited.Tags {
+		if !t.hasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	m.record("edit", t.Text)
+	m.notice = unreadNotice(t.Text)
+}
+
+// overdueTasks returns the indices of the open list's overdue tasks
+func (m model) overdueTasks(now time.Time) []int {
+	var overdue []int
+	for i, t := range m.tasks {
+		if t.overdue(now) {
+			overdue = append(overdue, i)
+		}
+	}
+	return overdue
+}
+
+// snoozeOverdue reschedules every overdue task to the day when describes,
+// in any form an @when token accepts
+func (m *model) snoozeOverdue(when string) {
+	now := time.Now()
+	due, ok := parseDay(when, now)
+	if !ok {
+		m.notice = fmt.Sprintf("%q isn't a date; try 1d, 2w, fri or tomorrow", when)
+		return
+	}
+	overdue := m.overdueTasks(now)
+	if len(overdue) == 0 {
+		return
+	}
+	m.checkpoint()
+	for _, i := range overdue {
+		m.tasks[i].Due = due
+		m.record("snooze", m.tasks[i].Text)
+	}
+	m.notice = fmt.Sprintf("Rescheduled %d overdue tasks to %s", len(overdue), m.formatDue(due))
+}
+
+// setWaiting marks the selected task as waiting on who
+func (m *model) setWaiting(who string) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	m.checkpoint()
+	t := &m.tasks[i]
+	t.Status = statusWaiting
+	t.WaitingOn = who
+	m.record("waiting", t.Text)
+	m.trackCompletion(t)
+	m.moveCursorTo(i)
+}
+
+// setStatus moves task i to status s, taking it off waiting and moving on
+// to the next unfinished task if it's done and autoAdvance is set
+func (m *model) setStatus(i int, s taskStatus) {
+	m.checkpoint()
+	m.markStatus(i, s)
+}
+
+// markStatus is setStatus without the checkpoint, for changes that take one
+// of their own
+func (m *model) markStatus(i int, s taskStatus) {
+	t := &m.tasks[i]
+	t.Status = s
+	t.WaitingOn = ""
+	m.record(t.Status.String(), t.Text)
+	m.trackCompletion(t)
+	done := t.Status == statusDone
+	if done && m.archiveOnDone {
+		if t.ID == "" {
+			m.assignIDs()
+		}
+		m.pendingArchive = append(m.pendingArchive, t.ID)
+	}
+	m.renew(i)
+	if done && m.autoAdvance {
+		m.advanceCursor()
+	}
+}
+
+// progressStep is how far + and - move a task's progress
+const progressStep = 10
+
+// addProgress moves task i's progress by delta percent, keeping it within
+// 0-100. Reaching 100 marks the task done when progressDone is set.
+func (m *model) addProgress(i, delta int) {
+	t := &m.tasks[i]
+	p := max(min(t.Progress+delta, 100), 0)
+	if p == t.Progress {
+		return
+	}
+	m.checkpoint()
+	t.Progress = p
+	if p == 100 && m.progressDone && t.Status != statusDone {
+		m.markStatus(i, statusDone)
+	}
+}
+
+// trackCompletion stamps t with when it was finished once it's marked done
+// and notes it for the session summary, undoing both when it's reopened
+func (m *model) trackCompletion(t *task) {
+	if t.Status == statusDone {
+		t.Completed = time.Now()
+		if !slices.Contains(m.completed, t.Text) {
+			m.completed = append(m.completed, t.Text)
+		}
+		return
+	}
+	t.Completed = time.Time{}
+	if i := slices.Index(m.completed, t.Text); i >= 0 {
+		m.completed = slices.Delete(m.completed, i, i+1)
+	}
+}
+
+// viewSummary recaps what was finished this session, or renders nothing
+// when there's nothing to show
+func (m model) viewSummary() string {
+	if !m.summary || len(m.completed) == 0 {
+		return ""
+	}
+	noun := "tasks"
+	if len(m.completed) == 1 {
+		noun = "task"
+	}
+	s := doneStyle.Render(fmt.Sprintf("✅ Completed %d %s this session:", len(m.completed), noun)) + "\n"
+	for _, text := range m.completed {
+		s += "  • " + text + "\n"
+	}
+	return s + "\n"
+}
+
+// advanceCursor moves to the next unfinished row after the cursor, wrapping
+// around to the top, and stays put when everything is done
+func (m *model) advanceCursor() {
+	rows := m.visible()
+	for step := 1; step < len(rows); step++ {
+		row := (m.cursor + step) % len(rows)
+		if m.tasks[rows[row]].Status != statusDone {
+			m.cursor = row
+			return
+		}
+	}
+	m.notice = "All tasks done 🎉"
+}
+
+// visible returns the indices into m.tasks of the rows currently shown, in
+// display order. The cursor indexes this slice rather than m.tasks.
+func (m model) visible() []int {
+	rows, _ := m.layout()
+	return rows
+}
+
+// layout works out the rows visible shows, along with how many finished
+// top-level tasks the closed completed fold holds back
+func (m model) layout() (shown []int, folded int) {
+	rows := make([]int, 0, len(m.tasks))
+	var pinned []int // Rows kept above the rest
+	var later []int  // Rows sunk to the bottom, in their usual order
+	var done []int   // Finished rows gathered under the completed fold
+	now := time.Now()
+	horizon, root := startOfDay(now).AddDate(0, 0, m.sinkDays+1), 0
+	for i := 0; i < len(m.tasks); i++ {
+		t := m.tasks[i]
+		if t.Depth == 0 {
+			root = i
+		}
+		if t.Priority >= m.minPriority && t.hasTag(m.tagFilter) && m.doneFilter.matches(t.Status) && t.matches(m.filterQuery) && (m.waitingInline && !m.waitingView || (t.Status == statusWaiting) == m.waitingView) {
+			// Subtasks move with the top-level task they belong to
+			switch due := m.tasks[root].Due; {
+			case m.tasks[root].snoozed(now):
+				later = append(later, i)
+			case m.tasks[root].onTop(now):
+				pinned = append(pinned, i)
+			case m.foldDone && m.doneFilter != showDone && m.tasks[root].Status == statusDone:
+				done = append(done, i)
+				if i == root {
+					folded++
+				}
+			case m.sinkFuture && !due.Before(horizon):
+				later = append(later, i)
+			default:
+				rows = append(rows, i)
+			}
+		}
+		if m.tasks[i].Collapsed {
+			i = subtreeEnd(m.tasks, i) - 1
+		}
+	}
+	shown = append(append(pinned, rows...), later...)
+	if m.doneOpen {
+		return append(shown, done...), folded
+	}
+	return shown, folded
+}
+
+// skipped reports whether moving the cursor, or the random picker, passes
+// over row. Waiting tasks shown in the main view are there to be seen
+// rather than worked on; the waiting view is where to pick them up.
+// Snoozed tasks are out of the way until they come back.
+func (m model) skipped(rows []int, row int) bool {
+	t := m.tasks[rows[row]]
+	return !m.waitingView && t.Status == statusWaiting || t.snoozed(time.Now())
+}
+
+// step moves the cursor dir rows at a time until it lands on one that isn't
+// skipped, staying put when there's none that way. With wrapCursor set it
+// carries on round from the other end of the rows instead.
+func (m *model) step(rows []int, dir int) {
+	for n, row := 1, m.cursor+dir; n < len(rows); n, row = n+1, row+dir {
+		if row < 0 || row >= len(rows) {
+			if !m.wrapCursor {
+				return
+			}
+			row = (row + len(rows)) % len(rows)
+		}
+		if !m.skipped(rows, row) {
+			m.cursor = row
+			return
+		}
+	}
+}
+
+// selected returns the index into m.tasks of the task under the cursor
+func (m model) selected() (int, bool) {
+	rows := m.visible()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return 0, false
+	}
+	return rows[m.cursor], true
+}
+
+// moveCursorTo places the cursor on the row showing m.tasks[i], or clamps
+// it to the visible rows when that task is hidden
+func (m *model) moveCursorTo(i int) {
+	rows := m.visible()
+	if row := slices.Index(rows, i); row >= 0 {
+		m.cursor = row
+		return
+	}
+	for row, real := range rows {
+		if real >= i {
+			m.cursor = row
+			return
+		}
+	}
+	m.cursor = len(rows) - 1
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// block returns a copy of the task at i along with its subtasks when they
+// are collapsed, since hidden subtasks travel with their parent
+func (m model) block(i int) []task {
+	end := i + 1
+	if m.tasks[i].Collapsed {
+		end = subtreeEnd(m.tasks, i)
+	}
+	return append([]task(nil), m.tasks[i:end]...)
+}
+
+// shiftTask swaps the selected task, and its subtasks, with the sibling
+// shown above it (dir -1) or below it (dir 1), passing over any siblings a
+// filter hides. At either end of its level it stays where it is.
+func (m *model) shiftTask(dir int) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	sibling, action := prevSibling, "move up"
+	if dir > 0 {
+		sibling, action = nextSibling, "move down"
+	}
+	rows := m.visible()
+	to := sibling(m.tasks, i)
+	for to >= 0 && !slices.Contains(rows, to) {
+		to = sibling(m.tasks, to)
+	}
+	if to < 0 {
+		return
+	}
+	m.checkpoint()
+	m.record(action, m.tasks[i].Text)
+	m.moveCursorTo(moveSubtree(m.tasks, i, to))
+}
+
+// duplicateTask puts a copy of the selected task, and of its subtasks, just
+// after them, moving the cursor onto it. The copies keep everything but the
+// ID, which the next save gives them, and a running stopwatch; they count
+// as added now.
+func (m *model) duplicateTask() {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	end := subtreeEnd(m.tasks, i)
+	copies := cloneTasks(m.tasks[i:end])
+	now := time.Now()
+	for n := range copies {
+		copies[n].ID, copies[n].TimerStarted, copies[n].Marked = "", time.Time{}, false
+		copies[n].Created = now
+	}
+	m.checkpoint()
+	m.record("duplicate", m.tasks[i].Text)
+	m.tasks = slices.Insert(m.tasks, end, copies...)
+	m.moveCursorTo(end)
+	m.notice = "Copied " + truncateRunes(m.tasks[i].Text, 40)
+	switch len(copies) {
+	case 1:
+	case 2:
+		m.notice += " with its subtask"
+	default:
+		m.notice += fmt.Sprintf(" with its %d subtasks", len(copies)-1)
+	}
+}
+
+// deleteTask deletes the task at i as a change undo can take back
+func (m *model) deleteTask(i int) {
+	m.checkpoint()
+	for _, t := range m.removeTask(i) {
+		m.record("delete", t.Text)
+	}
+}
+
+// clearDone deletes every finished task, with its subtasks when they're
+// finished too, as one change undo can take back
+func (m *model) clearDone() {
+	starts := finishedSubtrees(m.tasks)
+	if len(starts) == 0 {
+		return
+	}
+	m.checkpoint()
+	n := m.finishedTotal()
+	m.describeChange("clearing " + finishedCount(n))
+	for s := len(starts) - 1; s >= 0; s-- {
+		end := subtreeEnd(m.tasks, starts[s])
+		for _, t := range m.tasks[starts[s]:end] {
+			m.record("delete", t.Text)
+		}
+		m.tasks = slices.Delete(m.tasks, starts[s], end)
+	}
+	m.moveCursorTo(starts[0])
+	m.notice = fmt.Sprintf("Cleared %s (u to undo)", finishedCount(n))
+}
+
+// finishedTotal counts the tasks clearDone would delete
+func (m model) finishedTotal() int {
+	n := 0
+	for _, s := range finishedSubtrees(m.tasks) {
+		n += subtreeEnd(m.tasks, s) - s
+	}
+	return n
+}
+
+// finishedCount spells out n finished tasks, as in "1 finished task"
+func finishedCount(n int) string {
+	if n == 1 {
+		return "1 finished task"
+	}
+	return fmt.Sprintf("%d finished tasks", n)
+}
+
+// updateConfirmingDelete handles the answer to whether to delete the task
+// x was pressed on, or every finished one: y deletes, and n or esc keeps
+func (m model) updateConfirmingDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "x", "ctrl+k":
+		if m.pendingClear {
+			m.clearDone()
+		} else if m.pendingMarked {
+			m.deleteMarked()
+		} else {
+			m.deleteTask(m.pendingDelete)
+		}
+		m.state = browsing
+	case "n", "N", "esc", "q":
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// quit stops the app, and the stopwatch with it
+func (m model) quit() (tea.Model, tea.Cmd) {
+	m.stopTimer()
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// updateConfirmingQuit handles the answer to whether to save changes that
+// couldn't be saved before quitting: y tries again, staying put if it still
+// fails, n quits without them, and c or esc goes back to the list
+func (m model) updateConfirmingQuit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		if m.save(); m.dirty {
+			break // The notice says why
+		}
+		return m.quit()
+	case "n", "N":
+		m.discard = true
+		return m.quit()
+	case "c", "C", "esc":
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// deleteQuestion asks whether to delete the pending task, counting the
+// hidden subtasks that would go with it, or how many finished tasks to clear
+func (m model) deleteQuestion() string {
+	if m.pendingClear {
+		return fmt.Sprintf("Clear %s? (y/n)", finishedCount(m.finishedTotal()))
+	}
+	if m.pendingMarked {
+		return fmt.Sprintf("Delete %s? (y/n)", markedCount(len(m.marked())))
+	}
+	block := m.block(m.pendingDelete)
+	q := fmt.Sprintf("Delete %q", truncateRunes(block[0].Text, 40))
+	switch len(block) {
+	case 1:
+	case 2:
+		q += " and its subtask"
+	default:
+		q += fmt.Sprintf(" and its %d subtasks", len(block)-1)
+	}
+	return q + "? (y/n)"
+}
+
+// removeTask deletes the task at i and returns what was removed. A collapsed
+// parent takes its hidden subtasks with it; visible subtasks stay and move
+// up a level to take the parent's place.
+func (m *model) removeTask(i int) []task {
+	removed := m.block(i)
+	if len(removed) == 1 {
+		end := subtreeEnd(m.tasks, i)
+		for j := i + 1; j < end; j++ {
+			m.tasks[j].Depth--
+		}
+	}
+	m.tasks = append(m.tasks[:i], m.tasks[i+len(removed):]...)
+	m.moveCursorTo(i)
+	return removed
+}
+
+// updateHelping handles key input when in help mode
+func (m model) updateHelping(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Help taller than the window scrolls; help that fits has nothing to
+	// scroll, so these keys do nothing there
+	if last := m.helpLast(); last > 0 {
+		page := m.helpRows()
+		switch msg.String() {
+		case "up", "k":
+			m.helpOffset = max(m.helpOffset-1, 0)
+		case "down", "j":
+			m.helpOffset = min(m.helpOffset+1, last)
+		case "pgup", "ctrl+u":
+			m.helpOffset = max(m.helpOffset-page, 0)
+		case "pgdown", "ctrl+d", " ":
+			m.helpOffset = min(m.helpOffset+page, last)
+		case "home", "g":
+			m.helpOffset = 0
+		case "end", "G":
+			m.helpOffset = last
+		}
+	}
+	if key.Matches(msg, m.keys.Close) {
+		m.state = browsing
+		m.helpOffset = 0
+	}
+	return m, nil
+}
+
+// updateViewingHistory handles key input on the history screen
+func (m model) updateViewingHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "H", "enter":
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// updateInputting handles key input when in input mode
+func (m model) updateInputting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	// Cancel input and return to browse mode, keeping what was typed for
+	// next time if drafts are kept
+	case key.Matches(msg, m.keys.Cancel):
+		m.state = browsing
+		m.capturing = false
+		if m.keepDrafts && strings.TrimSpace(m.input.Value()) != "" {
+			m.notice = "Draft kept for next time • ctrl+x in the field discards it"
+			return m, nil
+		}
+		m.input.Reset()
+		return m, nil
+
+	// Cancel input and throw away what was typed
+	case msg.String() == "ctrl+x":
+		m.state = browsing
+		m.capturing = false
+		m.input.Reset()
+		return m, nil
+
+	// Submit the new task
+	case key.Matches(msg, m.keys.Save):
+		if t := m.parseInput(m.input.Value(), m.newPriority); strings.TrimSpace(t.Text) != "" {
+			m.checkpoint()
+			if !m.capturing {
+				m.addTask(t)
+				m.showAdded(len(m.tasks) - 1)
+				m.moveCursorTo(len(m.tasks) - 1) // Move cursor to new task
+				if m.flashAdded && m.notice == "" {
+					m.picked, m.notice = m.cursor, addedNotice
+					cmd = tea.Tick(addedFlash, func(time.Time) tea.Msg { return pickFadeMsg{} })
+				}
+			} else if i := m.captureTask(t); i != m.active {
+				m.notice = "Captured to " + m.lists[i].label()
+			}
+			if notice := unreadNotice(t.Text); notice != "" {
+				m.notice = notice
+			}
+		}
+		m.state = browsing
+		m.capturing = false
+		m.input.Reset()
+		return m, cmd
+
+	// Paste from the system clipboard through the same path as typed input
+	case msg.String() == "ctrl+v":
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			m.notice = "Could not read clipboard: " + err.Error()
+			return m, nil
+		}
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+	}
+
+	// Warn when input won't fit; the text input itself cuts the runes down
+	if msg.Type == tea.KeyRunes && m.input.CharLimit > 0 {
+		free := m.input.CharLimit - utf8.RuneCountInString(m.input.Value())
+		if len(msg.Runes) > free {
+			m.notice = fmt.Sprintf("Truncated to %d chars", m.input.CharLimit)
+		}
+	}
+
+	// Update the text input component
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model - renders the UI
+func (m model) View() string {
+	if m.quitting {
+		return m.viewSummary() + "Goodbye! ✨\n"
+	}
+
+	// Prompts draw over whichever screen opened them
+	screen := m.state
+	if screen == prompting {
+		screen = m.promptReturn
+	}
+
+	var b strings.Builder
+	switch screen {
+	case helping:
+		b.WriteString(m.viewHelp())
+	case viewingHistory:
+		b.WriteString(m.viewHistory())
+	case viewingArchive:
+		b.WriteString(m.viewArchive())
+	case pickingDate:
+		b.WriteString(m.viewPickingDate())
+	case viewingTags:
+		b.WriteString(m.viewTags())
+	case searching:
+		b.WriteString(m.viewSearch())
+	case viewingDetail:
+		b.WriteString(m.viewDetail())
+	case previewingImport:
+		b.WriteString(m.viewImportPreview())
+	case replacing:
+		b.WriteString(m.viewReplace())
+	case viewingAgenda:
+		b.WriteString(m.viewAgenda())
+	case viewingNext:
+		b.WriteString(m.viewNext())
+	case editingNotes:
+		b.WriteString(m.viewNotes())
+	case pickingEmoji:
+		b.WriteString(m.viewPickingEmoji())
+	case viewingDashboard:
+		b.WriteString(m.viewDashboard())
+		if m.state == prompting {
+			b.WriteString(m.viewPrompt())
+		} else {
+			b.WriteString("\n" + helpStyle.Render("↑/↓: choose • enter: open list • g: set goals • esc: back"))
+		}
+	case reviewing:
+		b.WriteString(m.viewReview())
+		if m.state == prompting {
+			b.WriteString(m.viewPrompt())
+		} else {
+			b.WriteString(helpStyle.Render("k/enter: keep • d: done • x: delete • f: defer to tomorrow • e: edit • esc: stop"))
+		}
+	case pickingList:
+		b.WriteString(m.viewPickingList())
+		if m.state == prompting {
+			b.WriteString(m.viewPrompt())
+		} else if m.merging {
+			b.WriteString(helpStyle.Render("enter: merge into this list • esc: cancel"))
+		} else if m.processing {
+			b.WriteString(helpStyle.Render("enter: move here (inbox keeps it) • n: new list • esc: stop"))
+		} else if m.moving {
+			b.WriteString(helpStyle.Render("enter: move here • n: new list • esc: cancel"))
+		} else {
+			b.WriteString(helpStyle.Render("enter: open • n: new • d: clone • r: rename • i: icon • c: color • I: inbox • m: merge • f: folder • ←/→: fold • u: undo • esc: back"))
+		}
+	default:
+		if m.zen {
+			b.WriteString(m.viewTasks())
+		} else if m.paneWidth() > 0 {
+			b.WriteString(m.viewSplit())
+		} else if m.cheatSheet {
+			b.WriteString(m.viewTasksWithCheatSheet())
+		} else {
+			b.WriteString(m.viewTasks())
+		}
+	}
+
+	b.WriteString("\n")
+	return b.String()
+}
+
+// viewTasks renders the task list screen used for browsing and adding
+// tasks. Only the rows that fit between the header and footer are drawn.
+func (m model) viewTasks() string {
+	header, footer := m.viewTasksHeader(), m.viewTasksFooter()
+
+	var b strings.Builder
+	b.WriteString(header)
+	rows, folded := m.layout()
+	switch {
+	case len(m.tasks) == 0:
+		b.WriteString(normalStyle.Render("No tasks yet. Press 'n' to add one.") + "\n")
+	case len(rows) == 0 && folded > 0:
+		b.WriteString(normalStyle.Render("Everything here is done 🎉") + "\n")
+	case len(rows) == 0 && m.filterQuery != "":
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Nothing here matches %q. Press esc to show everything.", m.filterQuery)) + "\n")
+	case len(rows) == 0 && m.doneFilter == showDone && m.tagFilter == "" && m.minPriority == priorityNone && !m.waitingView:
+		b.WriteString(normalStyle.Render("Nothing here is done yet. Press '~' to show everything.") + "\n")
+	case len(rows) == 0 && m.doneFilter == showActive && m.tagFilter == "" && m.minPriority == priorityNone && !m.waitingView:
+		b.WriteString(normalStyle.Render("Everything here is done 🎉 Press '~' to show it.") + "\n")
+	case len(rows) == 0 && m.waitingView:
+		b.WriteString(normalStyle.Render("Nothing is waiting. Press 'W' to go back.") + "\n")
+	case len(rows) == 0:
+		b.WriteString(normalStyle.Render("No tasks match the filter. Press '!', 'T' or '~' to change it.") + "\n")
+	default:
+		folds := m.hasFolds()
+		now := time.Now()
+		budget, overflow := m.rowBudget(rows, header, footer)
+		row := m.offset
+		for ; row < len(rows); row++ {
+			lines := m.rowHeight(rows, row, folds)
+			if lines > budget && row > m.offset {
+				break
+			}
+			budget -= lines
+			if label := m.dividerBefore(rows, row); label != "" {
+				b.WriteString(m.viewDivider(label) + "\n")
+			}
+			m.writeRow(&b, row, rows[row], folds, now)
+		}
+
+		// Say where the viewport is once the list doesn't fit
+		if overflow {
+			b.WriteString(m.viewScrollGauge(len(rows), row) + "\n")
+		}
+	}
+	b.WriteString(footer)
+	return b.String()
+}
+
+// viewTasksHeader renders the title, with a badge counting what's due, and
+// tabs for the other lists
+func (m model) viewTasksHeader() string {
+	list := m.lists[m.active]
+	title := titleStyle.Foreground(lipgloss.Color(list.Color))
+	if m.blurred {
+		title = title.Foreground(blurredStyle.GetForeground())
+	}
+	label := list.label()
+	if m.zen {
+		return title.Render(label) + "\n\n"
+	}
+	if m.waitingView {
+		label += " ⏳ waiting"
+	}
+	tabs := m.viewListTabs()
+	if m.solo {
+		tabs = ""
+	}
+	return title.Render(label+m.dueBadge(time.Now())) + "\n\n" + tabs + m.viewFrogBanner()
+}
+
+// rowParts lays out the task at i: the indent and markers before its text,
+// the text itself split into display lines, and the metadata after it.
+// Text that doesn't fit the terminal is wrapped or truncated.
+func (m model) rowParts(i int, folds bool) (prefix string, text []string, suffix string) {
+	t := m.tasks[i]
+
+	// Subtasks indent under their parent, which shows a fold marker
+	prefix = strings.Repeat("  ", t.Depth)
+	switch {
+	case m.zen:
+		// No fold markers, just the indent
+	case t.Collapsed && hasChildren(m.tasks, i):
+		prefix += foldStyle.Render(m.icons.Collapsed + " ")
+	case hasChildren(m.tasks, i):
+		prefix += foldStyle.Render(m.icons.Expanded + " ")
+	case folds:
+		prefix += strings.Repeat(" ", max(lipgloss.Width(m.icons.Expanded), lipgloss.Width(m.icons.Collapsed))+1)
+	}
+	if !m.zen {
+		prefix += m.statusMarker(t.Status) + " "
+	}
+	if m.checkboxes && !m.zen {
+		prefix += statusStyle(t.Status).Render(checkbox(t.Status)) + " "
+	}
+	if t.Emoji != "" {
+		prefix += t.Emoji + " "
+	}
+	if t.Marked {
+		prefix = markStyle.Render(markGlyph+" ") + prefix
+	}
+	indent := strings.Repeat(" ", lipgloss.Width(m.gutter())+lipgloss.Width(prefix))
+
+	// Metadata trails the text, or sits on a dim line of its own
+	if meta := m.taskMeta(t); len(meta) > 0 && !m.zen {
+		if m.detailed {
+			suffix = "\n" + indent + strings.Join(meta, metaStyle.Render(" • "))
+		} else {
+			suffix = " " + strings.Join(meta, " ")
+		}
+	}
+
+	// The status's decoration, if it has one, follows the text directly
+	var after string
+	if decoration := m.icons.after(t.Status); decoration != "" && !m.zen {
+		after = statusStyle(t.Status).Render(decoration)
+	}
+
+	text = []string{t.Text}
+	if m.width > 0 {
+		room := m.width - len(indent) - lipgloss.Width(after)
+		if !m.detailed {
+			room -= lipgloss.Width(suffix)
+		}
+		room = max(room, minTextWidth)
+		if m.wrap {
+			text = strings.Split(ansi.Wrap(t.Text, room, ""), "\n")
+		} else {
+			text[0] = ansi.Truncate(t.Text, room, "…")
+		}
+	}
+	return prefix, text, after + suffix
+}
+
+// writeRow renders the task at i, shown on visible row row, into b
+func (m model) writeRow(b *strings.Builder, row, i int, folds bool, now time.Time) {
+	t := m.tasks[i]
+	prefix, text, suffix := m.rowParts(i, folds)
+
+	// Accents fade out while the terminal is unfocused
+	cursorMark, selected, highlight := cursorStyle, selectedStyle, cursorRowStyle
+	if m.cursorColor != "" {
+		cursorMark = cursorMark.Foreground(lipgloss.Color(m.cursorColor))
+		highlight = highlight.Background(lipgloss.Color(m.cursorColor))
+	}
+	if m.blurred {
+		cursorMark = blurredStyle
+		selected = selected.Foreground(blurredStyle.GetForeground())
+	}
+
+	lead, style := m.gutter(), m.rowStyle(t, now)
+	switch row {
+	case m.picked:
+		// Freshly picked item stands out until the highlight fades
+		lead, style = cursorMark.Render(m.icons.Cursor+" "), pickedStyle
+	case m.cursor:
+		// Selected item with cursor indicator
+		lead, style = cursorMark.Render(m.icons.Cursor+" "), selected
+	}
+	if m.strikeDone && t.Status == statusDone {
+		style = style.Strikethrough(true)
+	}
+
+	// Wrapped lines continue under the start of the text
+	var r strings.Builder
+	r.WriteString(lead + prefix + highlightMatches(text[0], m.filterQuery, style))
+	indent := strings.Repeat(" ", lipgloss.Width(lead)+lipgloss.Width(prefix))
+	for _, line := range text[1:] {
+		r.WriteString("\n" + indent + highlightMatches(line, m.filterQuery, style))
+	}
+	r.WriteString(suffix)
+
+	// Stripes follow the row's place in the list rather than on screen, so
+	// they stay put while scrolling; the cursor row keeps its own look
+	switch {
+	case m.highlightCursor && (row == m.cursor || row == m.picked):
+		b.WriteString(m.stripe(r.String(), highlight) + "\n")
+		return
+	case m.zebra && !m.zen && row%2 == 1 && row != m.cursor:
+		b.WriteString(m.stripe(r.String(), stripeStyle) + "\n")
+		return
+	}
+	b.WriteString(r.String() + "\n")
+}
+
+// checkbox returns the box drawn before a task's text: ticked once it's done
+func checkbox(s taskStatus) string {
+	if s == statusDone {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// stripe shades the full width of each line in s with shade's background.
+// The row is made of separately styled pieces whose resets would end the
+// shading early, so the background is switched back on after each one.
+func (m model) stripe(s string, shade lipgloss.Style) string {
+	on, _, _ := strings.Cut(shade.Render(" "), " ")
+	if on == "" {
+		return s // No color support
+	}
+	reshade := strings.NewReplacer("\x1b[0m", "\x1b[0m"+on, ansi.ResetStyle, ansi.ResetStyle+on)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		pad := strings.Repeat(" ", max(m.width-lipgloss.Width(line), 0))
+		lines[i] = on + reshade.Replace(line) + pad + ansi.ResetStyle
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewTasksFooter renders everything below the rows: the status bar, any
+// open input or prompt, the notice and the help line
+func (m model) viewTasksFooter() string {
+	var b strings.Builder
+
+	// The closed completed fold stands in for the tasks it holds
+	if _, folded := m.layout(); folded > 0 && !m.doneOpen && !m.zen {
+		b.WriteString(m.viewDivider(fmt.Sprintf("▸ completed (%d) • ctrl+o to show", folded)) + "\n")
+	}
+
+	// Status bar with the number of tasks and a count per status
+	if m.state == browsing && !m.zen {
+		var counts [4]int
+		for _, t := range m.tasks {
+			counts[t.Status]++
+		}
+		bar := taskCount(len(m.tasks))
+		if len(m.tasks) > 0 {
+			bar += fmt.Sprintf(" • %d todo • %d doing • %d done", counts[statusTodo], counts[statusDoing], counts[statusDone])
+		}
+		if p, ok := m.overallProgress(); ok {
+			bar += fmt.Sprintf(" • %d%% complete", p)
+		}
+		if counts[statusWaiting] > 0 {
+			bar += fmt.Sprintf(" • %d waiting", counts[statusWaiting])
+		}
+		if m.minPriority != priorityNone {
+			bar += fmt.Sprintf(" • showing %s", m.minPriority)
+			if m.minPriority < priorityHigh {
+				bar += " and up"
+			}
+		}
+		if m.doneFilter != showAll {
+			bar += " • showing " + m.doneFilter.String()
+		}
+		if by := m.sortedLabel(); by != "" {
+			bar += " • sorted by " + by
+		}
+		if m.tagFilter != "" {
+			bar += " • tagged #" + m.tagFilter
+		}
+		if m.filterQuery != "" {
+			bar += fmt.Sprintf(" • matching %q", m.filterQuery)
+		}
+		if m.sinkFuture {
+			bar += fmt.Sprintf(" • due after %dd sunk", m.sinkDays)
+		}
+		if marked := len(m.marked()); marked > 0 {
+			bar += fmt.Sprintf(" • %d marked", marked)
+		}
+		if t := m.runningTimer(); t != nil {
+			bar += fmt.Sprintf(" • ⏱ %s %s", formatDuration(t.spent(time.Now())), truncateRunes(t.Text, 20))
+		}
+		if i, ok := m.selected(); ok && m.countdown && !m.tasks[i].Due.IsZero() {
+			bar += " • ⌛ " + formatCountdown(m.tasks[i].deadline(), time.Now())
+		}
+		b.WriteString("\n" + statusBarStyle.Render(bar) + m.goalTracker(time.Now()) + m.capWarning() + "\n")
+	}
+
+	// Render input field when in input mode
+	if m.state == inputting {
+		label := "New Task:"
+		if i := m.inbox(); m.capturing && i != m.active {
+			label = "Capture to " + m.lists[i].label() + ":"
+		}
+		b.WriteString("\n" + inputPromptStyle.Render(label) + "\n")
+		b.WriteString("  " + m.input.View() + "\n")
+
+		// Preview what the inline attributes will set, spelled out in full
+		preview := m
+		preview.detailed = true
+		if meta := preview.taskMeta(m.parseInput(m.input.Value(), m.newPriority)); len(meta) > 0 {
+			b.WriteString("  " + strings.Join(meta, metaStyle.Render(" • ")) + "\n")
+		}
+	}
+	if m.state == filtering {
+		b.WriteString("\n" + inputPromptStyle.Render("Filter:") + "\n")
+		b.WriteString("  " + m.filterInput.View() + "\n")
+	}
+	if m.state == prompting {
+		b.WriteString(m.viewPrompt())
+	}
+	if m.state == confirmingDelete {
+		b.WriteString("\n" + inputPromptStyle.Render(m.deleteQuestion()) + "\n")
+	}
+	if m.state == confirmingQuit {
+		b.WriteString("\n" + inputPromptStyle.Render("Unsaved changes — save and quit? (y/n/c)") + "\n")
+	}
+
+	// Render any pending notice
+	if m.notice != "" {
+		b.WriteString("\n" + noticeStyle.Render(m.notice) + "\n")
+	}
+
+	// Render the quick actions bound in the config
+	if len(m.quick) > 0 && m.state == browsing && !m.zen {
+		b.WriteString("\n" + m.viewQuickBar() + "\n")
+	}
+
+	// Render help text, unless it's been hidden from the list
+	if m.zen || m.hideHints && m.state == browsing {
+		return b.String()
+	}
+	b.WriteString("\n")
+	if m.state == browsing {
+		b.WriteString(helpStyle.Render(m.keys.hints()))
+	} else if m.state == filtering {
+		b.WriteString(helpStyle.Render("↑/↓: move • enter: keep the filter • esc: clear it"))
+	} else if m.state == confirmingDelete {
+		b.WriteString(helpStyle.Render("y/x: delete • n/esc: keep it"))
+	} else if m.state == confirmingQuit {
+		b.WriteString(helpStyle.Render("y: save and quit • n: quit without saving • c/esc: cancel"))
+	} else if m.state == inputting && m.keepDrafts {
+		b.WriteString(helpStyle.Render("enter: save • esc: keep as draft • ctrl+x: discard"))
+	} else {
+		b.WriteString(helpStyle.Render("enter: save • esc: cancel"))
+	}
+	return b.String()
+}
+
+// overallProgress averages how complete the open list's tasks are, counting
+// finished ones as 100% and the rest by their progress. It's only reported
+// once some unfinished task has progress of its own, since the done count
+// already says the rest.
+func (m model) overallProgress() (int, bool) {
+	total, partial := 0, false
+	for _, t := range m.tasks {
+		if t.Status == statusDone {
+			total += 100
+		} else {
+			total += t.Progress
+			partial = partial || t.Progress > 0
+		}
+	}
+	if !partial {
+		return 0, false
+	}
+	return total / len(m.tasks), true
+}
+
+// listHeight returns how many lines are left for task rows once header and
+// footer are drawn, or no limit until the terminal size is known
+func (m model) listHeight(header, footer string) int {
+	if m.height <= 0 {
+		return math.MaxInt
+	}
+	// View adds a final newline, leaving one more line below the footer
+	used := strings.Count(header, "\n") + strings.Count(footer, "\n") + 2
+	return max(m.height-used, 1)
+}
+
+// rowBudget returns how many lines rows may use and whether they overflow
+// them, in which case a line is held back for the scroll gauge
+func (m model) rowBudget(rows []int, header, footer string) (int, bool) {
+	budget := m.listHeight(header, footer)
+	if m.zen {
+		return budget, false // No gauge to leave room for
+	}
+	if len(rows) <= budget {
+		lines, folds := 0, m.hasFolds()
+		for row := range rows {
+			lines += m.rowHeight(rows, row, folds)
+		}
+		if lines <= budget {
+			return budget, false
+		}
+	}
+	return max(budget-1, 1), true
+}
+
+// viewScrollGauge renders the cursor's position among all rows, with arrows
+// counting the rows hidden above and below the viewport. end is the row
+// just past the last one drawn.
+func (m model) viewScrollGauge(total, end int) string {
+	parts := []string{}
+	if m.offset > 0 {
+		parts = append(parts, fmt.Sprintf("↑ %d above", m.offset))
+	}
+	parts = append(parts, fmt.Sprintf("%d/%d", m.cursor+1, total))
+	if end < total {
+		parts = append(parts, fmt.Sprintf("↓ %d below", total-end))
+	}
+	return statusBarStyle.Render(strings.Join(parts, " • "))
+}
+
+// rowLines returns how many lines the task at i takes up on screen
+func (m model) rowLines(i int, folds bool) int {
+	_, text, suffix := m.rowParts(i, folds)
+	return len(text) + strings.Count(suffix, "\n")
+}
+
+// rowHeight returns how many lines visible row row takes up on screen,
+// counting any divider drawn above it
+func (m model) rowHeight(rows []int, row int, folds bool) int {
+	lines := m.rowLines(rows[row], folds)
+	if m.dividerBefore(rows, row) != "" {
+		lines++
+	}
+	return lines
+}
+
+// dividerBefore names the divider drawn above visible row row, if any: one
+// under the pinned tasks, and one over the open completed fold
+func (m model) dividerBefore(rows []int, row int) string {
+	if m.zen {
+		return ""
+	}
+	now := time.Now()
+	t := m.tasks[rootOf(m.tasks, rows[row])]
+	var above task
+	if row > 0 {
+		above = m.tasks[rootOf(m.tasks, rows[row-1])]
+	}
+	switch {
+	case m.foldDone && t.Status == statusDone && !t.onTop(now) &&
+		(row == 0 || above.onTop(now) || above.Status != statusDone):
+		_, folded := m.layout()
+		return fmt.Sprintf("▾ completed (%d)", folded)
+	case row > 0 && above.onTop(now) && !t.onTop(now):
+		return "pinned"
+	}
+	return ""
+}
+
+// viewDivider renders a rule across the list, as wide as the terminal,
+// with label at its start
+func (m model) viewDivider(label string) string {
+	width := m.width
+	if width <= 0 {
+		width = 40
+	}
+	label = "── " + label + " "
+	return metaStyle.Render("  " + label + strings.Repeat("─", max(width-lipgloss.Width(label)-4, 2)))
+}
+
+// hasFolds reports whether any task has subtasks, in which case every row
+// reserves a column for fold markers
+func (m model) hasFolds() bool {
+	for i := range m.tasks {
+		if hasChildren(m.tasks, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// scroll moves the viewport as little as possible to keep the cursor's row
+// on screen
+func (m *model) scroll() {
+	// The detail pane and key panel take some of the room the rows would
+	// have had
+	if m.paneWidth() > 0 {
+		area := m.listPane()
+		area.scroll()
+		m.offset = area.offset
+		return
+	}
+	if m.cheatSheet && !m.zen {
+		area := m.listArea()
+		area.scroll()
+		m.offset = area.offset
+		return
+	}
+
+	rows := m.visible()
+	m.offset = max(min(m.offset, m.cursor, len(rows)-1), 0)
+	if len(rows) == 0 {
+		return
+	}
+
+	// Walk back from the cursor to find the earliest row that still fits
+	budget, _ := m.rowBudget(rows, m.viewTasksHeader(), m.viewTasksFooter())
+	first, lines, folds := m.cursor, 0, m.hasFolds()
+	for first >= 0 {
+		lines += m.rowHeight(rows, first, folds)
+		if lines > budget {
+			break
+		}
+		first--
+	}
+	m.offset = max(m.offset, min(first+1, m.cursor))
+}
+
+// viewHelp renders the help screen, or as much of it as fits the window
+// from helpOffset on, with a hint to scroll
+func (m model) viewHelp() string {
+	lines := m.helpLines()
+	rows := m.helpRows()
+	if rows >= len(lines) {
+		return strings.Join(lines, "\n") + "\n" + helpStyle.Render("Press any key to return...")
+	}
+	from := min(m.helpOffset, len(lines)-rows)
+	hint := "↑/↓: scroll • pgup/pgdn: page • esc: back"
+	if from+rows < len(lines) {
+		hint = "(more below) • " + hint
+	}
+	return strings.Join(lines[from:from+rows], "\n") + "\n" + helpStyle.Render(hint)
+}
+
+// helpRows is how many lines of help fit above its key hint, which takes
+// two lines with the margin above it, and the blank line View ends with
+func (m model) helpRows() int {
+	if m.height <= 0 {
+		return math.MaxInt
+	}
+	return max(m.height-3, 1)
+}
+
+// helpLast is the furthest the help scrolls down, or zero when it fits
+func (m model) helpLast() int {
+	return max(len(m.helpLines())-m.helpRows(), 0)
+}
+
+// helpLines returns the help a line each, without its key hint
+func (m model) helpLines() []string {
+	return strings.Split(strings.TrimSuffix(m.helpText(), "\n"), "\n")
+}
+
+// helpText renders every line of the help, which viewHelp shows as much of
+// as fits
+func (m model) helpText() string {
+	s := titleStyle.Render("📖 Help & Commands") + "\n\n"
+	s += headingStyle.Render("Navigation:") + "\n"
+	s += helpLine(m.keys.Up)
+	s += helpLine(m.keys.Down)
+	s += normalStyle.Render("r          - Jump to a random unfinished task") + "\n"
+	s += helpLine(m.keys.Filter)
+	s += helpLine(m.keys.Search)
+	s += normalStyle.Render("%          - Replace text in every task of this list") + "\n"
+	s += normalStyle.Render("12 / 12G   - Jump to task 12 (G alone: the last task)") + "\n"
+	s += normalStyle.Render("gg         - Jump to the first task") + "\n"
+	s += normalStyle.Render("Ctrl+U/D   - Move half a screen up / down") + "\n"
+	s += normalStyle.Render("I          - Show details on a line beneath each task") + "\n"
+	s += normalStyle.Render("z          - Wrap or truncate long tasks") + "\n"
+	s += normalStyle.Render("F          - Sink tasks not due for a while to the bottom") + "\n\n"
+
+	s += headingStyle.Render("Tasks:") + "\n"
+	s += helpLine(m.keys.Add)
+	s += normalStyle.Render("A          - Capture a task into the inbox") + "\n"
+	s += helpEntry(m.keys.Status.Help().Key, fmt.Sprintf("Cycle status: todo %s → doing %s → done %s", m.icons.Todo, m.icons.Doing, m.icons.Done))
+	s += normalStyle.Render("Enter      - "+enterActions[m.enterAction]) + "\n"
+	s += helpLine(m.keys.Edit)
+	s += normalStyle.Render("t          - Start / stop the stopwatch on selected task") + "\n"
+	s += normalStyle.Render("R          - Review unfinished tasks one at a time") + "\n"
+	s += normalStyle.Render("w          - Mark as waiting on someone (Space picks it back up)") + "\n"
+	s += normalStyle.Render("W          - Switch to / from the waiting view") + "\n"
+	s += normalStyle.Render("U          - Merge duplicate tasks into one") + "\n"
+	s += normalStyle.Render("c          - Count down to selected task's deadline") + "\n"
+	s += normalStyle.Render("B          - Remind days before selected task is due") + "\n"
+	s += normalStyle.Render("l          - Attach a file to selected task, or take one off") + "\n"
+	s += normalStyle.Render("m          - Open selected task's attached files") + "\n"
+	s += normalStyle.Render("v          - Mark / unmark selected task (esc: unmark all)") + "\n"
+	s += normalStyle.Render("*          - Mark every task shown, or unmark them all") + "\n"
+	s += normalStyle.Render("V          - Add or remove a tag on every marked task") + "\n"
+	s += normalStyle.Render("#          - Tag / untag selected task with today's date") + "\n"
+	s += normalStyle.Render("+ / -      - Step the selected task's progress by 10%") + "\n"
+	s += normalStyle.Render("p          - Cycle priority: none → low → medium → high") + "\n"
+	s += normalStyle.Render(".          - Repeat: none → daily → weekly → a day after done; done adds the next") + "\n"
+	s += normalStyle.Render(":          - Pick an emoji to show before the selected task") + "\n"
+	s += normalStyle.Render("^          - Pin / unpin selected task above the rest") + "\n"
+	s += normalStyle.Render("Ctrl+T     - Boost selected task to the top for a few days") + "\n"
+	s += normalStyle.Render("D          - Pick a due date from a calendar") + "\n"
+	s += normalStyle.Render("S          - Reschedule every overdue task at once") + "\n"
+	s += normalStyle.Render("Ctrl+N     - Snooze selected task until tomorrow morning") + "\n"
+	s += normalStyle.Render("!          - Filter: all → medium and up → high only") + "\n"
+	s += normalStyle.Render("~          - Filter: all → left to do → finished") + "\n"
+	s += normalStyle.Render("T          - Tag overview: counts per tag, enter to filter") + "\n"
+	s += normalStyle.Render("N          - Week ahead: unfinished tasks under each day they're due") + "\n"
+	s += normalStyle.Render("Ctrl+A     - Next actions: the first task to do in every list") + "\n"
+	s += normalStyle.Render("s          - Overview: what's open, due, overdue and done today in every list") + "\n"
+	s += normalStyle.Render("             g there sets how many tasks to finish a day and a week") + "\n"
+	s += normalStyle.Render("f          - Eat the frog: go to the top-priority unfinished task") + "\n"
+	s += helpLine(m.keys.Delete)
+	s += normalStyle.Render("Ctrl+K     - Clear every finished task") + "\n"
+	s += normalStyle.Render("Ctrl+F     - Archive every finished task to archive.json") + "\n"
+	s += normalStyle.Render("Ctrl+L     - Look through the archive: restore (r, R) or delete for good (x, X)") + "\n"
+	s += normalStyle.Render("X X        - Clear all tasks in the list (press twice)") + "\n"
+	s += normalStyle.Render("> / <      - Nest under task above / move out a level") + "\n"
+	s += normalStyle.Render("Shift+↑/↓  - Move task up / down past its neighbor") + "\n"
+	s += normalStyle.Render("y          - Duplicate the task, with its subtasks, below it") + "\n"
+	s += normalStyle.Render(",          - Sort by date added, name, status, priority, due date") + "\n"
+	s += normalStyle.Render("← / →      - Collapse / expand subtasks") + "\n"
+	s += normalStyle.Render("O          - Collapse or expand all subtasks") + "\n"
+	s += normalStyle.Render("Ctrl+O     - Open or close the fold of completed tasks") + "\n"
+	s += helpEntry(m.keys.Undo.Help().Key+" / "+m.keys.Redo.Help().Key, "Undo / redo the last change")
+	s += normalStyle.Render("Y          - Copy selected task to register") + "\n"
+	s += normalStyle.Render("Ctrl+Y     - Copy selected task's details to the clipboard") + "\n"
+	s += normalStyle.Render(";          - Write or edit the selected task's notes") + "\n"
+	s += normalStyle.Render("Ctrl+X     - Cut selected task to register") + "\n"
+	s += normalStyle.Render("P          - Paste register below selection") + "\n"
+	s += normalStyle.Render("Ctrl+V     - Import clipboard lines as tasks") + "\n"
+	s += helpLine(m.keys.Save)
+	s += normalStyle.Render("due:DATE   - Set a due date, e.g. due:2024-06-01 (In input mode)") + "\n"
+	s += normalStyle.Render("@when      - Due @today, @tomorrow, @fri, @+3d, @2w (In input mode)") + "\n"
+	s += normalStyle.Render("!1 !2 !3   - Set low / medium / high priority (In input mode)") + "\n"
+	s += normalStyle.Render("#tag       - Tag the task, e.g. #work (In input mode)") + "\n"
+	s += normalStyle.Render("every:daily - Repeat daily or weekly once done (In input mode)") + "\n"
+	s += normalStyle.Render("after:3d   - Repeat 3 days (or 2w, weeks) after it's done (In input mode)") + "\n"
+	s += normalStyle.Render("Ctrl+V     - Paste from clipboard (In input mode)") + "\n"
+	s += normalStyle.Render("Ctrl+X     - Discard what's typed (In input mode)") + "\n\n"
+
+	s += headingStyle.Render("Lists:") + "\n"
+	s += normalStyle.Render("Tab / S-Tab - Switch to the next / previous list") + "\n"
+	s += normalStyle.Render("`          - Flip back to the list open before this one") + "\n"
+	s += helpLine(m.keys.Lists)
+	s += normalStyle.Render("M          - Move selected task to another list") + "\n"
+	s += normalStyle.Render("i          - Process the inbox, moving each task to a list") + "\n"
+	s += normalStyle.Render("I          - Make the highlighted list the inbox (In list switcher)") + "\n\n"
+
+	s += headingStyle.Render("Application:") + "\n"
+	s += helpLine(m.keys.Help)
+	s += normalStyle.Render("K          - Show / hide a panel of common keys beside the list") + "\n"
+	s += normalStyle.Render("|          - Hide or show the task details beside the list (120+ columns)") + "\n"
+	s += normalStyle.Render("Z          - Zen view: only the tasks' text, nothing else") + "\n"
+	s += normalStyle.Render("Ctrl+P     - Cycle color modes: detected, true color, 256, 16, none") + "\n"
+	s += normalStyle.Render("Ctrl+B     - Switch theme: dark or light") + "\n"
+	s += normalStyle.Render("b          - Hide or show the key hints under the list") + "\n"
+	s += normalStyle.Render("Ctrl+G     - Show or hide each task's ID, as exports use it") + "\n"
+	s += normalStyle.Render("Ctrl+W     - Show or hide how long ago each task was added") + "\n"
+	s += normalStyle.Render("@          - Show due dates as dates or relative to today") + "\n"
+	s += normalStyle.Render("o          - Only this list: hide the others and stop switching") + "\n"
+	s += normalStyle.Render("H          - Show history of changes") + "\n"
+	s += normalStyle.Render("E          - Export dated tasks to a calendar (.ics) file") + "\n"
+	s += normalStyle.Render("C          - Export the list to a spreadsheet (.csv) file") + "\n"
+	s += normalStyle.Render("Ctrl+E     - Export the list as a Markdown checklist (.md)") + "\n"
+	s += normalStyle.Render("J          - Export a done log of finished tasks by day (.md)") + "\n"
+	s += helpLine(m.keys.Quit)
+	s += helpLine(m.keys.ForceQuit) + "\n"
+
+	if len(m.quick) > 0 {
+		s += headingStyle.Render("Quick actions (from the config):") + "\n"
+		for _, q := range m.quick {
+			s += normalStyle.Render(fmt.Sprintf("%-10s - %s", q.Key, q.Action)) + "\n"
+		}
+		s += "\n"
+	}
+	return s
+}
+
+// viewHistory renders the history screen
+func (m model) viewHistory() string {
+	s := titleStyle.Render("🕘 History") + "\n\n"
+	if len(m.history) == 0 {
+		s += normalStyle.Render("Nothing has changed yet.") + "\n"
+	}
+	// Newest first, limited to the most recent entries
+	for i := len(m.history) - 1; i >= 0 && i >= len(m.history)-historyScreenRows; i-- {
+		e := m.history[i]
+		line := fmt.Sprintf("%s %s  %-7s %s", m.formatDate(e.At), e.At.Format("15:04"), e.Action, e.Task)
+		s += normalStyle.Render(line) + "\n"
+	}
+	if older := len(m.history) - historyScreenRows; older > 0 {
+		s += normalStyle.Render(fmt.Sprintf("… %d older", older)) + "\n"
+	}
+	s += helpStyle.Render("esc: back")
+	return s
+}
+
+// truncateRunes cuts s down to at most limit characters, never splitting a
+// multibyte character. A limit of zero or less leaves s untouched.
+func truncateRunes(s string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+// formatDate renders a date using the configured layout
+func (m model) formatDate(t time.Time) string {
+	return t.Format(m.dateLayout)
+}
+
+// dueBadge summarizes unfinished tasks due today and overdue, returning
+// nothing when neither applies
+func (m model) dueBadge(now time.Time) string {
+	today, overdue := 0, 0
+	for _, t := range m.tasks {
+		if t.dueToday(now) {
+			today++
+		} else if t.overdue(now) {
+			overdue++
+		}
+	}
+
+	var parts []string
+	if today > 0 {
+		parts = append(parts, fmt.Sprintf("%d due today", today))
+	}
+	if overdue > 0 {
+		parts = append(parts, fmt.Sprintf("%d overdue", overdue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + badgeStyle.Render("("+strings.Join(parts, ", ")+")")
+}
+
+// taskMeta renders the pieces of metadata shown alongside a task's text
+func (m model) taskMeta(t task) []string {
+	var meta []string
+	if t.Priority != priorityNone {
+		badge := m.priorityBadge(t.Priority)
+		if m.detailed {
+			badge = priorityStyle(t.Priority).Render(t.Priority.String() + " priority")
+		}
+		meta = append(meta, badge)
+	}
+	if t.boosted(time.Now()) {
+		meta = append(meta, m.boostBadge(t))
+	}
+	if now := time.Now(); t.snoozed(now) {
+		meta = append(meta, metaStyle.Render("💤 until "+m.snoozeTime(t.Snoozed, now)))
+	}
+	if now := time.Now(); !t.Due.IsZero() {
+		style, due := dueStyle, "📅 "+m.formatDue(t.Due)
+		switch {
+		case t.pastDue(now):
+			style, due = dueStyle.Foreground(overdueColor), due+" (overdue)"
+		case t.dueToday(now):
+			style = dueStyle.Foreground(dueTodayColor)
+		case t.dueSoon(now, m.leadFor(t)):
+			style = dueSoonStyle
+		}
+		meta = append(meta, style.Render(due))
+	}
+	if t.Recur != recurNone {
+		meta = append(meta, m.recurBadge(t))
+	}
+	if t.Carried > 0 {
+		carried := fmt.Sprintf("↻%d", t.Carried)
+		if m.detailed {
+			carried = fmt.Sprintf("carried over %d times", t.Carried)
+		}
+		meta = append(meta, metaStyle.Render(carried))
+	}
+	if t.Status == statusWaiting {
+		meta = append(meta, waitingStyle.Render("⏳ waiting on "+t.WaitingOn))
+	}
+	if t.Progress > 0 {
+		meta = append(meta, progressStyle.Render(progressBar(t.Progress)+fmt.Sprintf(" %d%%", t.Progress)))
+	}
+	if spent := t.spent(time.Now()); spent > 0 {
+		meta = append(meta, metaStyle.Render("⏱ "+formatDuration(spent)))
+	}
+	if len(t.Attachments) > 0 {
+		meta = append(meta, m.attachmentBadge(t))
+	}
+	if t.Notes != "" {
+		meta = append(meta, m.notesBadge(t))
+	}
+	for _, tag := range t.Tags {
+		style := tagStyle
+		if color, ok := m.tagColors[tag]; ok {
+			style = style.Foreground(lipgloss.Color(color))
+		}
+		meta = append(meta, style.Render("#"+tag))
+	}
+	if m.showAge && !t.Created.IsZero() {
+		meta = append(meta, metaStyle.Render("("+shortAge(time.Since(t.Created))+" ago)"))
+	}
+	if m.showIDs && t.ID != "" {
+		meta = append(meta, metaStyle.Render("["+t.ID+"]"))
+	}
+	return meta
+}
+
+// progressBar draws percent p as a bar five cells wide
+func progressBar(p int) string {
+	const cells = 5
+	filled := (p*cells + 50) / 100
+	return strings.Repeat("▰", filled) + strings.Repeat("▱", cells-filled)
+}
+
+// ageStyle colors an unfinished task by how long it has been around: the
+// usual color while fresh, shifting toward a warning as it passes ageWarn
+// and the alert color once it passes ageAlert
+func (m model) ageStyle(t task, now time.Time) lipgloss.Style {
+	if t.Status == statusWaiting {
+		return waitingStyle
+	}
+	age := t.age(now)
+	if m.ageWarn <= 0 || t.Status == statusDone || age < m.ageWarn {
+		return taskStyle
+	}
+	if age >= m.ageAlert {
+		return taskStyle.Foreground(ageAlertColor)
+	}
+	step := int(int64(len(ageRamp)) * int64(age-m.ageWarn) / int64(m.ageAlert-m.ageWarn))
+	return taskStyle.Foreground(ageRamp[step])
+}
+
+// priorityBadge renders the priority icon once per level (!, !!, !!!), in
+// the priority's color
+func (m model) priorityBadge(p priority) string {
+	if p == priorityNone {
+		return ""
+	}
+	return priorityStyle(p).Render(strings.Repeat(m.icons.Priority, int(p)))
+}
+
+// priorityStyle returns the color a priority's badge is drawn in
+func priorityStyle(p priority) lipgloss.Style {
+	switch p {
+	case priorityHigh:
+		return priorityBadgeStyle.Foreground(priorityHighColor)
+	case priorityMedium:
+		return priorityBadgeStyle.Foreground(priorityMedColor)
+	default:
+		return priorityBadgeStyle
+	}
+}
+
+// statusMarker renders the colored icon for a task status, padded to the
+// widest of them
+func (m model) statusMarker(s taskStatus) string {
+	marker := m.icons.status(s)
+	pad := strings.Repeat(" ", m.icons.markerWidth()-lipgloss.Width(marker))
+	return statusStyle(s).Render(marker) + pad
+}
+
+// statusStyle returns the color a task status is drawn in
+func statusStyle(s taskStatus) lipgloss.Style {
+	switch s {
+	case statusDoing:
+		return doingStyle
+	case statusDone:
+		return doneStyle
+	case statusWaiting:
+		return waitingStyle
+	default:
+		return todoStyle
+	}
+}
+
+// gutter returns blank space as wide as the cursor, so rows without it line
+// up with the selected one
+func (m model) gutter() string {
+	return strings.Repeat(" ", lipgloss.Width(m.icons.Cursor)+1)
+}
+
+func main() {
+	file := flag.String("file", "", "keep the lists in `path` instead of dataFile from the config, ~/.todotui/tasks.json by default")
+	serve := flag.String("serve", "", "also serve a read-only HTML view of the tasks on `addr`, e.g. :8080")
+	add := flag.Bool("add", false, "start in the new-task field, or with text arguments (or - to read lines from stdin) add them as tasks to the inbox and exit")
+	importTW := flag.String("import-taskwarrior", "", "preview merging the tasks from a Taskwarrior JSON export at `file` into the open list, then confirm or cancel")
+	importTxt := flag.String("import-txt", "", "add each non-empty line of the text `file` to the open list as a task, [x] marking it done, before starting")
+	csvCols := flag.String("csv-columns", "", "comma-separated `columns` for the CSV export, overriding csvColumns in the config")
+	noSummary := flag.Bool("no-summary", false, "don't list the tasks completed this session when quitting")
+	listName := flag.String("list", "", "open the list called `name` instead of the one last open, and have --add capture into it")
+	createList := flag.Bool("create-list", false, "start the list named by --list when there isn't one called that yet")
+	plainOut := flag.Bool("plain", false, "print each screen as plain text, with no colors or cursor movement, as is done anyway when TERM is dumb or unset")
+	replace := flag.Bool("replace", false, "make --import-txt, --import-taskwarrior, --add - and Ctrl+V replace the list's tasks instead of adding to them, once confirmed")
+	flag.Parse()
+
+	cfg, warnings, err := loadConfig()
+	if err != nil {
+		warnings = append(warnings, "Using default settings: "+err.Error())
+	}
+	warnings = append(warnings, cfg.applyEnv(os.Getenv)...)
+	warnings = append(warnings, cfg.validate()...)
+	if *csvCols != "" {
+		var bad []string
+		if cfg.CSVColumns, bad = checkCSVColumns(strings.Split(*csvCols, ",")); len(bad) > 0 {
+			warnings = append(warnings, "--csv-columns: "+strings.Join(bad, "; "))
+		}
+	}
+
+	path := cfg.DataFile
+	if *file != "" {
+		path = expandHome(*file)
+	}
+	if path == "" {
+		path = dataPath()
+	}
+	if err := checkDataPath(path); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	plain := *plainOut || plainTerminal(os.Getenv)
+	if plain {
+		// Asking the terminal what its background is would only garble a
+		// dumb one
+		lipgloss.SetHasDarkBackground(true)
+		cfg.Icons = plainIcons(cfg.Icons)
+	}
+	m := initialModel(cfg, path, warnings)
+	if plain {
+		m.goPlain()
+	}
+	m.summary = !*noSummary
+	m.importReplace = *replace
+
+	if *listName != "" {
+		if err := m.openNamedList(*listName, *createList); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		m.captureHere = true
+	}
+
+	if *add {
+		lines := []string{strings.Join(flag.Args(), " ")}
+		piped := len(flag.Args()) == 1 && flag.Arg(0) == "-"
+		if piped {
+			raw, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error reading stdin:", err)
+				os.Exit(1)
+			}
+			lines = strings.Split(string(raw), "\n")
+		}
+		var captured []task
+		for _, line := range lines {
+			if t := m.parseInput(line, m.newPriority); strings.TrimSpace(t.Text) != "" {
+				captured = append(captured, t)
+			}
+		}
+
+		if len(captured) == 0 {
+			// No text given: open straight into the new-task field
+			m.capturing = m.inbox() >= 0 && !m.captureHere
+			m.state = inputting
+			m.input.Focus()
+		} else {
+			// Quick capture: add the tasks and exit without starting the TUI
+			if m.notice != "" {
+				fmt.Fprintln(os.Stderr, "Warning:", m.notice)
+			}
+			if *replace && piped {
+				proceed, err := m.clearForCapture()
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "Error importing:", err)
+					os.Exit(1)
+				}
+				if !proceed {
+					fmt.Fprintln(os.Stderr, "Import cancelled; nothing changed")
+					os.Exit(1)
+				}
+			}
+			into := make([]int, len(captured))
+			for i, t := range captured {
+				into[i] = m.captureTask(t)
+			}
+			m.save()
+			if m.dirty {
+				fmt.Fprintln(os.Stderr, m.notice)
+				os.Exit(1)
+			}
+			for i, t := range captured {
+				fmt.Printf("Added to %s: %s\n", m.lists[into[i]].label(), t.Text)
+			}
+			for _, err := range m.runHooksNow() {
+				fmt.Fprintln(os.Stderr, "Warning:", err)
+			}
+			return
+		}
+	}
+
+	if *importTxt != "" {
+		tasks, err := m.readTextTasks(*importTxt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error importing:", err)
+			os.Exit(1)
+		}
+		m.importText(filepath.Base(*importTxt), tasks)
+	}
+
+	if *importTW != "" {
+		records, err := readTaskwarrior(*importTW)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error importing:", err)
+			os.Exit(1)
+		}
+		tasks, result := fromTaskwarrior(records)
+		m = m.startImportPreview(filepath.Base(*importTW), tasks, result.Skipped)
+	}
+
+	if *serve != "" {
+		server, err := startTaskServer(*serve)
+		if err != nil {
+			fmt.Printf("Error starting server: %v\n", err)
+			os.Exit(1)
+		}
+		server.publish(m.tasks)
+		m.server = server
+		defer server.shutdown()
+	}
+
+	if cfg.DashboardOnStart && m.state == browsing && *listName == "" {
+		m.dashCursor = m.active
+		m.state = viewingDashboard
+	}
+	m = m.rollUp(cfg.CarryOver)
+
+	var app tea.Model = m
+	opts := []tea.ProgramOption{tea.WithReportFocus()}
+	restore := func() {}
+	if plain {
+		var raw bool
+		raw, restore = rawInput()
+		app = newPlainModel(m, os.Stdout, raw && term.IsTerminal(os.Stdout.Fd()))
+		opts = []tea.ProgramOption{tea.WithoutRenderer()}
+	}
+	mouse := cfg.Mouse && !plain
+	if mouse {
+		opts = append(opts, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(app, opts...)
+	final, err := p.Run()
+	restore()
+	if err != nil {
+		fmt.Printf("Error running program: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The goodbye went with the whole screen, so it's printed again below
+	if fm, ok := final.(model); ok && mouse {
+		fmt.Print(fm.View())
 	}
 }
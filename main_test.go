@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns the app as it starts with cfg, keeping its files in
+// a directory of the test's own
+func newTestModel(t testing.TB, cfg config) model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	return initialModel(cfg, filepath.Join(dir, "tasks.json"), nil)
+}
+
+// press sends each key to m in turn, returning the model it ends up as
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, k := range keys {
+		next, _ := m.Update(k)
+		m = next.(model)
+	}
+	return m
+}
+
+// runes is a key press typing s, as a paste arrives
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// addTasks types each text in as a new task
+func addTasks(m model, texts ...string) model {
+	for _, text := range texts {
+		m = press(m, runes("a"), runes(text), tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	return m
+}
+
+func TestTruncateRunesKeepsMultibyteWhole(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"héllo", 2, "hé"},
+		{"日本語のテキスト", 3, "日本語"},
+		{"👍🏽ok", 1, "👍"},
+		{"short", 10, "short"},
+		{"anything", 0, "anything"},
+	} {
+		got := truncateRunes(tc.in, tc.limit)
+		if got != tc.want || !utf8.ValidString(got) {
+			t.Errorf("truncateRunes(%q, %d) = %q, want %q", tc.in, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestPasteOverLimitTruncatesAtRuneBoundary(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CharLimit = 5
+	m := press(newTestModel(t, cfg), runes("a"), runes("abcd日本語"))
+
+	if got := m.input.Value(); got != "abcd日" || !utf8.ValidString(got) {
+		t.Fatalf("input holds %q, want %q", got, "abcd日")
+	}
+	if m.notice != "Truncated to 5 chars" {
+		t.Errorf("notice = %q, want the truncation notice", m.notice)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.tasks) != 1 || m.tasks[0].Text != "abcd日" {
+		t.Errorf("saved %+v, want one task reading %q", m.tasks, "abcd日")
+	}
+}
+
+func TestPasteWithinLimitHasNoNotice(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.CharLimit = 5
+	m := press(newTestModel(t, cfg), runes("a"), runes("日本語"))
+	if m.input.Value() != "日本語" || m.notice != "" {
+		t.Errorf("input %q with notice %q, want all of it and no notice", m.input.Value(), m.notice)
+	}
+}
+
+func TestPriorityChangeIsRecorded(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "file taxes")
+	m = press(m, runes("p"))
+	last := m.history[len(m.history)-1]
+	if last.Action != "priority "+m.tasks[0].Priority.String() || last.Task != "file taxes" {
+		t.Fatalf("last history entry is %+v, want the priority change", last)
+	}
+	if what := m.undo[len(m.undo)-1].describe(); !strings.Contains(what, "priority") {
+		t.Errorf("undo describes the change as %q, want the priority change", what)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listDrawn returns the model as the list's rows are drawn from, as View
+// picks it: narrowed for the detail pane or the key panel when either is
+// open
+func (m model) listDrawn() model {
+	switch {
+	case m.zen:
+		return m
+	case m.paneWidth() > 0:
+		return m.listPane().listDrawn()
+	case m.cheatSheet:
+		return m.listArea()
+	}
+	return m
+}
+
+// rowAt returns the visible row drawn on line y of the screen, laying the
+// rows out below the header as viewTasks does. Clicks on the header, a
+// divider, the footer, or at column x beside the list miss.
+func (m model) rowAt(x, y int) (int, bool) {
+	list := m.listDrawn()
+	if list.width > 0 && x >= list.width {
+		return 0, false
+	}
+	rows := list.visible()
+	if len(rows) == 0 {
+		return 0, false
+	}
+	header, footer := list.viewTasksHeader(), list.viewTasksFooter()
+	folds := list.hasFolds()
+	budget, _ := list.rowBudget(rows, header, footer)
+	line := strings.Count(header, "\n")
+	for row := list.offset; row < len(rows); row++ {
+		lines := list.rowHeight(rows, row, folds)
+		if lines > budget && row > list.offset {
+			break
+		}
+		budget -= lines
+		top := line + lines - list.rowLines(rows[row], folds) // Below any divider
+		if y >= top && y < line+lines {
+			return row, true
+		}
+		line += lines
+	}
+	return 0, false
+}
+
+// handleMouse deals with a mouse press while browsing: a left click selects
+// the row under it, and the wheel moves the cursor as ↑ and ↓ do
+func (m *model) handleMouse(msg tea.MouseMsg) {
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		if row, ok := m.rowAt(msg.X, msg.Y); ok {
+			m.cursor = row
+		}
+	case tea.MouseButtonWheelUp:
+		m.step(m.visible(), -1)
+	case tea.MouseButtonWheelDown:
+		m.step(m.visible(), 1)
+	}
+}
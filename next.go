@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// nextAction returns the index of the first task in tasks still to do,
+// going into its subtasks for the first of those still to do, or -1 when
+// there's none. Waiting tasks are passed over, as nothing can be done on
+// them yet.
+func nextAction(tasks []task) int {
+	next := -1
+	for i, t := range tasks {
+		if t.Status == statusDone || t.Status == statusWaiting {
+			continue
+		}
+		if next >= 0 && i >= subtreeEnd(tasks, next) {
+			break
+		}
+		next = i // A subtask still to do comes before the task it's part of
+	}
+	return next
+}
+
+// nextActions returns each list's next action, in list order, -1 for a list
+// with nothing left to do
+func (m model) nextActions() []int {
+	all := m.allLists()
+	next := make([]int, len(all))
+	for l, list := range all {
+		next[l] = nextAction(list.Tasks)
+	}
+	return next
+}
+
+// completeNext marks the next action of list l done without leaving the
+// open list, so the one after it takes its place
+func (m *model) completeNext(l int) {
+	i := m.nextActions()[l]
+	if i < 0 {
+		return
+	}
+	open, prev := m.active, m.prevList
+	m.switchList(l)
+	m.setStatus(i, statusDone)
+	m.notice = "Done: " + truncateRunes(m.tasks[i].Text, 40)
+	m.switchList(open)
+	m.prevList = prev
+}
+
+// updateViewingNext handles key input in the next actions view: space marks
+// the highlighted action done and enter goes to it in its list
+func (m model) updateViewingNext(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "ctrl+a":
+		m.state = browsing
+
+	case "up", "k":
+		if m.nextCursor > 0 {
+			m.nextCursor--
+		}
+
+	case "down", "j":
+		if m.nextCursor < len(m.lists)-1 {
+			m.nextCursor++
+		}
+
+	case " ", "x":
+		m.completeNext(m.nextCursor)
+
+	case "enter":
+		if i := m.nextActions()[m.nextCursor]; i >= 0 {
+			m.switchList(m.nextCursor)
+			m.reveal(i)
+			m.moveCursorTo(i)
+			m.state = browsing
+		}
+	}
+	return m, nil
+}
+
+// viewNext renders the next action of every list, a row each
+func (m model) viewNext() string {
+	s := titleStyle.Render("👉 Next actions") + "\n\n"
+	next := m.nextActions()
+	width := 0
+	for _, l := range m.lists {
+		width = max(width, lipgloss.Width(l.label()))
+	}
+	left := 0
+	for l, list := range m.allLists() {
+		label := list.label()
+		row := list.style().Render(label + strings.Repeat(" ", width-lipgloss.Width(label)))
+		if i := next[l]; i >= 0 {
+			t := list.Tasks[i]
+			text := taskStyle.Render(t.Text)
+			if l == m.nextCursor {
+				text = selectedStyle.Render(t.Text)
+			}
+			row += "  " + m.statusMarker(t.Status) + " " + text
+			left++
+		} else {
+			row += "  " + blurredStyle.Render("nothing left to do")
+		}
+		if l == m.nextCursor {
+			s += cursorStyle.Render(m.icons.Cursor+" ") + row + "\n"
+		} else {
+			s += m.gutter() + row + "\n"
+		}
+	}
+	s += "\n" + metaStyle.Render(fmt.Sprintf("  %d of %d lists have something to do", left, len(m.lists))) + "\n"
+	if m.notice != "" {
+		s += "\n" + noticeStyle.Render(m.notice) + "\n"
+	}
+	return s + "\n" + helpStyle.Render("↑/↓: choose • space: done • enter: go to task • esc: back")
+}
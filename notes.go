@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// notesGlyph marks a task with notes, and notesStyle draws it
+const notesGlyph = "🗒"
+
+var notesStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("180"))
+
+// notesHeight is how many lines of notes show at once while editing; more
+// scroll
+const notesHeight = 8
+
+// newNotesArea returns the field notes are typed in, with no limit on how
+// much they hold
+func newNotesArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Anything more to say about this task..."
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetHeight(notesHeight)
+	return ta
+}
+
+// startNotes opens the notes of task i for editing
+func (m model) startNotes(i int) (tea.Model, tea.Cmd) {
+	m.notesTask, m.notesReturn = i, m.state
+	m.notesArea.SetWidth(60)
+	if m.width > 0 {
+		m.notesArea.SetWidth(max(min(m.width-6, 100), 20))
+	}
+	m.notesArea.SetValue(m.tasks[i].Notes)
+	m.state = editingNotes
+	return m, m.notesArea.Focus()
+}
+
+// updateEditingNotes handles key input while editing notes: ctrl+s keeps
+// them and esc throws the edit away. Enter starts a new line.
+func (m model) updateEditingNotes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.notesArea.Blur()
+		m.state = m.notesReturn
+		return m, nil
+
+	case "ctrl+s":
+		m.notesArea.Blur()
+		m.state = m.notesReturn
+		m.saveNotes(m.notesArea.Value())
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.notesArea, cmd = m.notesArea.Update(msg)
+	return m, cmd
+}
+
+// saveNotes replaces the notes of the task being edited, trimming blank
+// lines off the end, as a change undo can take back
+func (m *model) saveNotes(notes string) {
+	notes = strings.TrimRight(notes, " \t\n")
+	t := &m.tasks[m.notesTask]
+	if notes == t.Notes {
+		return
+	}
+	m.checkpoint()
+	action := "edit notes"
+	switch {
+	case notes == "":
+		action, m.notice = "clear notes", "Cleared the notes"
+	case t.Notes == "":
+		action, m.notice = "add notes", "Added notes"
+	default:
+		m.notice = "Saved the notes"
+	}
+	t.Notes = notes
+	m.record(action, t.Text)
+}
+
+// viewNotes renders the task with its notes being edited below it
+func (m model) viewNotes() string {
+	t := m.tasks[m.notesTask]
+	s := titleStyle.Render(notesGlyph+" Notes") + "\n\n"
+	s += "    " + m.statusMarker(t.Status) + " " + selectedStyle.Render(t.Text) + "\n\n"
+	s += m.notesArea.View() + "\n"
+	return s + "\n" + helpStyle.Render("enter: new line • ctrl+s: save • esc: discard changes")
+}
+
+// notesBadge renders the mark of a task with notes, or with details on,
+// the start of them
+func (m model) notesBadge(t task) string {
+	if !m.detailed {
+		return notesStyle.Render(notesGlyph)
+	}
+	first, _, more := strings.Cut(t.Notes, "\n")
+	first = m.linkRefs(first)
+	if short := truncateRunes(first, 40); more || short != first {
+		first = short + "…"
+	}
+	return notesStyle.Render(notesGlyph + " " + first)
+}
+
+// notesLines renders a task's notes for the detail view and pane, a blank
+// line then each line of them with the tasks they refer to named, or
+// nothing when there are none
+func (m model) notesLines(t task) []string {
+	if t.Notes == "" {
+		return nil
+	}
+	lines := []string{""}
+	for _, line := range strings.Split(t.Notes, "\n") {
+		lines = append(lines, m.linkRefs(line))
+	}
+	return lines
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dueCheckMsg asks for the due reminder to be checked straight away rather
+// than at the next clock tick
+type dueCheckMsg time.Time
+
+// checkDueNow delivers a dueCheckMsg for the current time
+func checkDueNow() tea.Msg {
+	return dueCheckMsg(time.Now())
+}
+
+// remindSoon finds the tasks, in any list, that have come within their lead
+// time of falling due since last checked, marking each as reminded for its
+// current due date, and describes them
+func (m *model) remindSoon(now time.Time) []string {
+	var soon []string
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := range tasks {
+			t := &tasks[i]
+			if !t.dueSoon(now, m.leadFor(*t)) || t.Reminded.Equal(t.Due) {
+				continue
+			}
+			t.Reminded = t.Due
+			m.dirty = true
+			days := int(startOfDay(t.Due).Sub(startOfDay(now)).Hours()+12) / 24
+			when := "in " + dayCount(days)
+			if days == 1 {
+				when = "tomorrow"
+			}
+			soon = append(soon, fmt.Sprintf("%q due %s", truncateRunes(t.Text, 30), when))
+		}
+	}
+	return soon
+}
+
+// remindAt finds the tasks, in any list, whose time of day has come since
+// last checked, marking each as alerted for its current due time, and
+// describes them. Times that went by more than a day ago are let go.
+func (m *model) remindAt(now time.Time) []string {
+	var ringing []string
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := range tasks {
+			t := &tasks[i]
+			if !hasClock(t.Due) || t.Status == statusDone || t.Due.After(now) || t.Alerted.Equal(t.Due) {
+				continue
+			}
+			t.Alerted = t.Due
+			m.dirty = true
+			if now.Sub(t.Due) < 24*time.Hour {
+				ringing = append(ringing, fmt.Sprintf("%q due at %s", truncateRunes(t.Text, 30), t.Due.Format("15:04")))
+			}
+		}
+	}
+	return ringing
+}
+
+// leadFor returns how many days ahead of its due date t is reminded
+func (m model) leadFor(t task) int {
+	if t.Lead > 0 {
+		return t.Lead
+	}
+	return m.remindLead
+}
+
+// setLead sets how many days ahead of its due date the selected task is
+// reminded, from the answer to the lead prompt
+func (m *model) setLead(answer string) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	days, err := strconv.Atoi(answer)
+	if err != nil || days < 0 {
+		m.notice = fmt.Sprintf("%q isn't a number of days", answer)
+		return
+	}
+	m.checkpoint()
+	t := &m.tasks[i]
+	t.Lead = days
+	t.Reminded = time.Time{} // Remind again under the new lead
+	m.notice = "Reminding " + dayCount(m.leadFor(*t)) + " before it's due"
+	if m.leadFor(*t) == 0 {
+		m.notice = "No reminder before it's due"
+	}
+}
+
+// dayCount spells out n days, as in "1 day" or "3 days"
+func dayCount(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// ringBell sounds the terminal bell
+func ringBell() tea.Msg {
+	os.Stdout.WriteString("\a")
+	return nil
+}
+
+// quietHours is a daily window when reminders hold off. The zero value is
+// no window at all.
+type quietHours struct {
+	start, end int // Minutes after midnight; end before start wraps past midnight
+	set        bool
+}
+
+// parseQuietHours reads a window written as HH:MM-HH:MM, such as
+// 22:00-08:00. An empty string means no quiet hours.
+func parseQuietHours(s string) (quietHours, error) {
+	if s == "" {
+		return quietHours{}, nil
+	}
+	var h1, m1, h2, m2 int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &h1, &m1, &h2, &m2); err != nil ||
+		h1 > 23 || h2 > 23 || m1 > 59 || m2 > 59 || h1 < 0 || h2 < 0 || m1 < 0 || m2 < 0 {
+		return quietHours{}, fmt.Errorf("%q isn't a window like 22:00-08:00", s)
+	}
+	return quietHours{start: h1*60 + m1, end: h2*60 + m2, set: true}, nil
+}
+
+// contains reports whether t falls inside the window. The start minute is
+// inside and the end minute is not.
+func (q quietHours) contains(t time.Time) bool {
+	if !q.set || q.start == q.end {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// remindDue tells the user once a day what's due across every list, once
+// per task when it comes within its lead time of falling due, and once more
+// when the time of day it's due at comes, with a bell if dueBell is set. During quiet hours reminders wait until they end;
+// the due badge keeps highlighting either way.
+func (m *model) remindDue(now time.Time) tea.Cmd {
+	if !m.dueReminders || m.quietHours.contains(now) {
+		return nil
+	}
+
+	var parts []string
+	if !startOfDay(now).Equal(m.remindedDay) {
+		m.remindedDay = startOfDay(now)
+		today, overdue := 0, 0
+		for _, l := range m.allLists() {
+			for _, t := range l.Tasks {
+				if t.dueToday(now) {
+					today++
+				} else if t.overdue(now) {
+					overdue++
+				}
+			}
+		}
+		if today > 0 {
+			parts = append(parts, fmt.Sprintf("%d due today", today))
+		}
+		if overdue > 0 {
+			parts = append(parts, fmt.Sprintf("%d overdue", overdue))
+		}
+	}
+	parts = append(parts, m.remindAt(now)...)
+	parts = append(parts, m.remindSoon(now)...)
+	if len(parts) == 0 {
+		return nil
+	}
+	reminder := "⏰ " + strings.Join(parts, ", ")
+	if m.notice != "" {
+		reminder = m.notice + "; " + reminder
+	}
+	m.notice = reminder
+	if m.dueBell {
+		return ringBell
+	}
+	return nil
+}
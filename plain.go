@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// plainTerminal reports whether output can't be trusted with escape codes
+// to move the cursor and style text: TERM is dumb or unset, as on serial
+// consoles, or the output isn't a terminal at all, as in CI logs
+func plainTerminal(getenv func(string) string) bool {
+	switch getenv("TERM") {
+	case "", "dumb", "unknown":
+		return true
+	}
+	return !term.IsTerminal(os.Stdout.Fd())
+}
+
+// plainIcons swaps each icon still at its default for an ASCII one, leaving
+// those the config set alone
+func plainIcons(i iconSet) iconSet {
+	defaults := defaultIcons()
+	for _, icon := range []struct {
+		value      *string
+		def, ascii string
+	}{
+		{&i.Cursor, defaults.Cursor, ">"},
+		{&i.Todo, defaults.Todo, "[ ]"},
+		{&i.Doing, defaults.Doing, "[~]"},
+		{&i.Done, defaults.Done, "[x]"},
+		{&i.Waiting, defaults.Waiting, "[?]"},
+		{&i.Expanded, defaults.Expanded, "v"},
+		{&i.Collapsed, defaults.Collapsed, ">"},
+	} {
+		if *icon.value == icon.def {
+			*icon.value = icon.ascii
+		}
+	}
+	return i
+}
+
+// goPlain drops every style, and with them the cursor highlight, which
+// gives way to a glyph
+func (m *model) goPlain() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	if m.highlightCursor {
+		m.highlightCursor, m.icons.Cursor = false, ">"
+	}
+}
+
+// rawInput puts the terminal typed into, if there is one, into raw mode, so
+// keys reach the app as they're pressed, as Bubble Tea does itself when it
+// renders. It returns whether it did, and how to put the terminal back.
+func rawInput() (bool, func()) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		return false, func() {}
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return false, func() {}
+	}
+	return true, func() { term.Restore(fd, state) }
+}
+
+// plainModel runs the app without Bubble Tea's renderer, which redraws in
+// place with escape codes. Instead each screen is printed whole below the
+// one before, once it changes, with anything styled left plain.
+type plainModel struct {
+	model
+	out  io.Writer
+	crlf bool    // Whether out is a terminal in raw mode, where \n only moves down
+	last *string // The screen printed last, shared by every copy of the model
+}
+
+// newPlainModel wraps m to print its screens to out, ending lines with \r\n
+// when crlf is set
+func newPlainModel(m model, out io.Writer, crlf bool) plainModel {
+	return plainModel{model: m, out: out, crlf: crlf, last: new(string)}
+}
+
+// Init prints the first screen before starting the app as usual
+func (p plainModel) Init() tea.Cmd {
+	p.print()
+	return p.model.Init()
+}
+
+// Update passes msg on to the app, printing the screen if that changed it
+func (p plainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := p.model.Update(msg)
+	if m, ok := next.(model); ok {
+		p.model = m
+	}
+	p.print()
+	return p, cmd
+}
+
+// plainGlyphs spells the arrows, bullets and lines the app draws with in
+// ASCII. Anything else outside it, emoji included, is left as it is.
+var plainGlyphs = strings.NewReplacer("•", "*", "↑", "up", "↓", "down", "←", "left", "→", "right", "…", "...", "—", "-", "─", "-", "│", "|")
+
+// print writes the current screen, with a rule above it, unless it's the
+// same as the last one
+func (p plainModel) print() {
+	screen := plainGlyphs.Replace(ansi.Strip(p.model.View()))
+	if screen == *p.last {
+		return
+	}
+	*p.last = screen
+	out := strings.Repeat("-", 40) + "\n" + strings.TrimRight(screen, "\n") + "\n"
+	if p.crlf {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	fmt.Fprint(p.out, out)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// colorProfile is one of the color modes ctrl+p steps through
+type colorProfile struct {
+	name    string // As saved in the tasks file
+	label   string // As shown in the notice
+	profile termenv.Profile
+}
+
+// colorProfiles lists the color modes in the order ctrl+p cycles them. The
+// first, auto, uses whatever the terminal was detected to support; its
+// profile is filled in at startup.
+var colorProfiles = []colorProfile{
+	{name: "auto", label: "detected colors"},
+	{name: "truecolor", label: "true color", profile: termenv.TrueColor},
+	{name: "256", label: "256 colors", profile: termenv.ANSI256},
+	{name: "16", label: "16 colors", profile: termenv.ANSI},
+	{name: "none", label: "no color", profile: termenv.Ascii},
+}
+
+// detectColors records the profile the terminal was detected with, for
+// auto to return to
+func detectColors() {
+	colorProfiles[0].profile = lipgloss.ColorProfile()
+	colorProfiles[0].label = "detected colors (" + colorProfiles[profileIndex(lipgloss.ColorProfile())].label + ")"
+}
+
+// profileIndex finds the first color mode after auto using p
+func profileIndex(p termenv.Profile) int {
+	return max(slices.IndexFunc(colorProfiles[1:], func(c colorProfile) bool { return c.profile == p })+1, 1)
+}
+
+// setColors switches every style over to the color mode at index i of
+// colorProfiles. Styles resolve their colors as they render, so the next
+// frame already uses it.
+func (m *model) setColors(i int) {
+	m.colors = i
+	lipgloss.SetColorProfile(colorProfiles[i].profile)
+}
+
+// colorsNamed finds a color mode by its saved name, falling back to auto
+func colorsNamed(name string) int {
+	return max(slices.IndexFunc(colorProfiles, func(c colorProfile) bool { return c.name == name }), 0)
+}
+
+// cycleColors steps on to the next color mode and saves the choice
+func (m *model) cycleColors() {
+	m.setColors((m.colors + 1) % len(colorProfiles))
+	m.dirty = true
+	m.notice = "Showing " + colorProfiles[m.colors].label + " • ctrl+p for the next"
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptKind identifies what a one-line prompt's answer is for
+type promptKind int
+
+const (
+	promptNewList promptKind = iota
+	promptRenameList
+	promptListIcon
+	promptListColor
+	promptWaitingOn
+	promptEditTask
+	promptSnoozeOverdue
+	promptMergeLists
+	promptFocusDone
+	promptLead
+	promptBulkTag
+	promptMergeDupes
+	promptFileList
+	promptRenameFolder
+	promptReplaceFind
+	promptReplaceWith
+	promptDoneLog
+	promptBoost
+	promptCarryOver
+	promptAttach
+	promptGoal
+	promptCloneList
+)
+
+// startPrompt opens a one-line prompt over the current screen, returning to
+// it once the prompt is submitted or cancelled
+func (m model) startPrompt(kind promptKind, label, initial string) (tea.Model, tea.Cmd) {
+	m.prompt = kind
+	m.promptLabel = label
+	m.promptReturn = m.state
+	m.state = prompting
+	m.promptInput.SetValue(initial)
+	m.promptInput.CursorEnd()
+	m.promptInput.Focus()
+	return m, textinput.Blink
+}
+
+// updatePrompting handles key input while a prompt is open
+func (m model) updatePrompting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Cancel the prompt, changing nothing
+	case "esc":
+		m.state = m.promptReturn
+		m.promptInput.Reset()
+		return m, nil
+
+	// Submit the answer; an empty answer counts as cancelling
+	case "enter":
+		value := strings.TrimSpace(m.promptInput.Value())
+		m.state = m.promptReturn
+		m.promptInput.Reset()
+		if value == "" {
+			return m, nil
+		}
+		switch m.prompt {
+		case promptNewList, promptRenameList, promptListIcon, promptListColor, promptCloneList:
+			m.applyListPrompt(m.prompt, value)
+		case promptWaitingOn:
+			m.setWaiting(value)
+		case promptEditTask:
+			m.editTask(value)
+		case promptSnoozeOverdue:
+			m.snoozeOverdue(value)
+		case promptReplaceFind:
+			return m.startReplaceWith(value)
+		case promptReplaceWith:
+			return m.previewReplace(value)
+		case promptDoneLog:
+			m.exportDoneLog(value)
+		case promptFileList:
+			m.fileList(value)
+		case promptRenameFolder:
+			m.renameFolder(value)
+		case promptMergeDupes:
+			switch strings.ToLower(value) {
+			case "d":
+				m.mergeDupes(true)
+			case "o":
+				m.mergeDupes(false)
+			}
+		case promptBulkTag:
+			m.bulkTag(value)
+		case promptLead:
+			m.setLead(value)
+		case promptBoost:
+			m.boostTask(value)
+		case promptCarryOver:
+			m.answerCarryOver(value)
+		case promptAttach:
+			m.attachFile(value)
+		case promptGoal:
+			m.setGoal(value)
+		case promptFocusDone:
+			m.finishFocus(value)
+		case promptMergeLists:
+			switch strings.ToLower(value) {
+			case "y", "yes":
+				m.mergeLists(m.mergeFrom, m.mergeInto, false)
+			case "d":
+				m.mergeLists(m.mergeFrom, m.mergeInto, true)
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.promptInput, cmd = m.promptInput.Update(msg)
+	return m, cmd
+}
+
+// viewPrompt renders the open prompt
+func (m model) viewPrompt() string {
+	return "\n" + inputPromptStyle.Render(m.promptLabel) + "\n" +
+		"  " + m.promptInput.View() + "\n"
+}
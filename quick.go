@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quickAction is a user-defined shortcut from the config: one key that does
+// one thing to the selected task
+type quickAction struct {
+	Key    string `json:"key"`    // As Bubble Tea names it, such as f1 or ctrl+g
+	Label  string `json:"label"`  // Shown in the quick bar; the action itself if empty
+	Action string `json:"action"` // Such as "priority high", "tag work" or "defer 1"
+}
+
+// builtinKeys are the keys browse mode already uses, which quick actions
+// can't take over. Keep it in step with updateBrowsing and defaultKeyMap.
+var builtinKeys = []string{
+	"q", "esc", "ctrl+c", "up", "k", "down", "j",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9",
+	"G", "n", "a", "A", "i", "/", "%", "^", "F", "K", "Z", "|", "?", "h", "H",
+	"L", "tab", "shift+tab", "o", "z", "M", "u", "ctrl+r", "b", "ctrl+p",
+	"I", "J", "E", "C", "t", "e", "R", "w", "B", "W", "f", "N", "T", "S", "D",
+	"p", "U", "c", "v", "V", "#", "+", "=", "-", "!", "r", " ", "enter",
+	"x", "backspace", "d", "X", ">", "<", "left", "right", "O", "Y",
+	"ctrl+x", "ctrl+v", "P", "ctrl+t", ":", "ctrl+o", "s", "`", "l", "m", "ctrl+n", "ctrl+y", "ctrl+g", "@",
+	"ctrl+a", "shift+up", "shift+down", "~", "ctrl+k", "ctrl+e", "g", "ctrl+u", "ctrl+d", "ctrl+w", "y", "ctrl+b", ";", ",",
+	"ctrl+f", "ctrl+l", "*", ".", "ctrl+s",
+}
+
+// quickBarStyle is the row of quick actions shown under the list
+var quickBarStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("109"))
+
+// checkQuickActions keeps the quick actions that can be used, returning a
+// warning for each one left out: a missing or taken key, or an action that
+// isn't understood
+func checkQuickActions(actions []quickAction) ([]quickAction, []string) {
+	var kept []quickAction
+	var warnings []string
+	for _, q := range actions {
+		q.Key = strings.TrimSpace(q.Key)
+		switch {
+		case q.Key == "":
+			warnings = append(warnings, fmt.Sprintf("ignoring quick action %q: it has no key", q.Action))
+		case slices.Contains(builtinKeys, q.Key):
+			warnings = append(warnings, fmt.Sprintf("ignoring quick action on %s: the key is already taken", q.Key))
+		case slices.ContainsFunc(kept, func(k quickAction) bool { return k.Key == q.Key }):
+			warnings = append(warnings, fmt.Sprintf("ignoring quick action on %s: an earlier one uses the key", q.Key))
+		default:
+			if err := parseQuickAction(q.Action); err != nil {
+				warnings = append(warnings, fmt.Sprintf("ignoring quick action on %s: %v", q.Key, err))
+				continue
+			}
+			kept = append(kept, q)
+		}
+	}
+	return kept, warnings
+}
+
+// parseQuickAction checks an action is one runQuick can carry out
+func parseQuickAction(action string) error {
+	verb, arg, _ := strings.Cut(strings.TrimSpace(action), " ")
+	arg = strings.TrimSpace(arg)
+	switch verb {
+	case "priority":
+		if _, ok := priorityNamed(arg); !ok {
+			return fmt.Errorf("%q isn't a priority; use none, low, medium or high", arg)
+		}
+	case "status":
+		if _, ok := statusNamed(arg); !ok {
+			return fmt.Errorf("%q isn't a status; use todo, doing or done", arg)
+		}
+	case "tag", "untag":
+		if !tagPattern.MatchString("#" + strings.TrimPrefix(arg, "#")) {
+			return fmt.Errorf("%q isn't a tag", arg)
+		}
+	case "due":
+		if _, ok := parseDay(arg, time.Now()); !ok && arg != "none" {
+			return fmt.Errorf("%q isn't a date; try tomorrow, fri, 3d or none", arg)
+		}
+	case "defer":
+		if n, err := strconv.Atoi(arg); err != nil || n < 1 {
+			return fmt.Errorf("%q isn't a number of days", arg)
+		}
+	case "pin":
+	default:
+		return fmt.Errorf("unknown action %q; use priority, status, tag, untag, due, defer or pin", verb)
+	}
+	return nil
+}
+
+// statusNamed looks up a status a task can be set to directly by name.
+// Waiting isn't one, since it needs someone to wait on.
+func statusNamed(name string) (taskStatus, bool) {
+	for _, s := range []taskStatus{statusTodo, statusDoing, statusDone} {
+		if strings.EqualFold(name, s.String()) {
+			return s, true
+		}
+	}
+	return statusTodo, false
+}
+
+// quickAction finds the quick action bound to key
+func (m model) quickAction(key string) (quickAction, bool) {
+	i := slices.IndexFunc(m.quick, func(q quickAction) bool { return q.Key == key })
+	if i < 0 {
+		return quickAction{}, false
+	}
+	return m.quick[i], true
+}
+
+// runQuick carries out q on the selected task, as one change for undo
+func (m *model) runQuick(q quickAction) {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	verb, arg, _ := strings.Cut(strings.TrimSpace(q.Action), " ")
+	arg = strings.TrimSpace(arg)
+	m.checkpoint()
+	t := &m.tasks[i]
+	switch verb {
+	case "priority":
+		t.Priority, _ = priorityNamed(arg)
+	case "status":
+		// markStatus records the change and may move on to the next task
+		s, _ := statusNamed(arg)
+		m.markStatus(i, s)
+		m.notice = q.label() + ": " + truncateRunes(t.Text, 40)
+		return
+	case "tag":
+		if tag := strings.ToLower(strings.TrimPrefix(arg, "#")); !t.hasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	case "untag":
+		tag := strings.ToLower(strings.TrimPrefix(arg, "#"))
+		t.Tags = slices.DeleteFunc(t.Tags, func(s string) bool { return s == tag })
+	case "due":
+		t.Due, _ = parseDay(arg, time.Now())
+	case "defer":
+		// From the due date, or from today for tasks undated or overdue
+		days, _ := strconv.Atoi(arg)
+		from := startOfDay(time.Now())
+		if t.Due.After(from) {
+			from = t.Due
+		}
+		t.Due = from.AddDate(0, 0, days)
+	case "pin":
+		if t.Depth > 0 {
+			m.notice = "Only top-level tasks can be pinned"
+			return
+		}
+		t.Pinned = !t.Pinned
+	}
+	m.record(q.Action, t.Text)
+	m.notice = q.label() + ": " + truncateRunes(t.Text, 40)
+	m.moveCursorTo(i)
+}
+
+// label is what the quick bar calls the action
+func (q quickAction) label() string {
+	if q.Label != "" {
+		return q.Label
+	}
+	return q.Action
+}
+
+// viewQuickBar renders the quick actions as one compact row
+func (m model) viewQuickBar() string {
+	parts := make([]string, len(m.quick))
+	for n, q := range m.quick {
+		parts[n] = strings.ToUpper(q.Key[:1]) + q.Key[1:] + " " + q.label()
+	}
+	return quickBarStyle.Render("⚡ " + strings.Join(parts, " • "))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrence is how often a task comes round again once it's finished
+type recurrence int
+
+const (
+	recurNone recurrence = iota
+	recurDaily
+	recurWeekly
+	recurAfterDone // Due RecurDays after the day it's finished, whenever that is
+)
+
+// String returns the lowercase name of the recurrence, as every: takes it
+func (r recurrence) String() string {
+	switch r {
+	case recurDaily:
+		return "daily"
+	case recurWeekly:
+		return "weekly"
+	case recurAfterDone:
+		return "after done"
+	default:
+		return "none"
+	}
+}
+
+// next returns the recurrence that follows r, wrapping from after done to
+// none
+func (r recurrence) next() recurrence {
+	return (r + 1) % 4
+}
+
+// days is how many days apart the repeats of a task on a fixed schedule
+// fall
+func (r recurrence) days() int {
+	if r == recurWeekly {
+		return 7
+	}
+	return 1
+}
+
+// repeats describes how t repeats, as in "weekly" or "3 days after done"
+func (t task) repeats() string {
+	if t.Recur == recurAfterDone {
+		return dayCount(t.RecurDays) + " after done"
+	}
+	return t.Recur.String()
+}
+
+// nextDue returns when the next repeat of t, finished at now, falls. On a
+// fixed schedule that's one interval on from its due date, and further
+// until it's after today; after done it's RecurDays on from today. Either
+// way it keeps any time of day it was due at. A task with no due date
+// repeats one interval from today.
+func (t task) nextDue(now time.Time) time.Time {
+	today := startOfDay(now)
+	if t.Recur == recurAfterDone {
+		next := today.AddDate(0, 0, t.RecurDays)
+		if !t.Due.IsZero() {
+			next = next.Add(t.Due.Sub(startOfDay(t.Due)))
+		}
+		return next
+	}
+	due := t.Due
+	if due.IsZero() {
+		due = today
+	}
+	next := due.AddDate(0, 0, t.Recur.days())
+	for !startOfDay(next).After(today) {
+		next = next.AddDate(0, 0, t.Recur.days())
+	}
+	return next
+}
+
+// parseRecurrence reads an every:daily or every:weekly token, also taken as
+// every:day and every:week, or an after:3d or after:2w token repeating that
+// many days or weeks after the task's done. It returns the recurrence and,
+// after done, the days it waits.
+func parseRecurrence(word string) (recurrence, int, bool) {
+	word = strings.ToLower(word)
+	if after, ok := strings.CutPrefix(word, "after:"); ok {
+		unit := 1
+		if n, ok := strings.CutSuffix(after, "w"); ok {
+			after, unit = n, 7
+		} else {
+			after = strings.TrimSuffix(after, "d")
+		}
+		n, err := strconv.Atoi(after)
+		if err != nil || n < 1 || n > maxRecurDays/unit {
+			return recurNone, 0, false
+		}
+		return recurAfterDone, n * unit, true
+	}
+	every, ok := strings.CutPrefix(word, "every:")
+	if !ok {
+		return recurNone, 0, false
+	}
+	switch every {
+	case "daily", "day":
+		return recurDaily, 0, true
+	case "weekly", "week":
+		return recurWeekly, 0, true
+	}
+	return recurNone, 0, false
+}
+
+// maxRecurDays is the longest a task can wait after it's done to repeat,
+// about a year
+const maxRecurDays = 366
+
+// recurBadge renders the mark of a repeating task
+func (m model) recurBadge(t task) string {
+	if m.detailed {
+		return dueStyle.Render("🔁 repeats " + t.repeats())
+	}
+	if t.Recur == recurAfterDone {
+		return dueStyle.Render("🔁 " + strconv.Itoa(t.RecurDays) + "d after done")
+	}
+	return dueStyle.Render("🔁 " + t.Recur.String())
+}
+
+// cycleRecurrence steps the selected task through none → daily → weekly →
+// a day after done, as a change undo can take back. Repeating some other
+// number of days after done takes an after: token.
+func (m *model) cycleRecurrence() {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	m.checkpoint()
+	t := &m.tasks[i]
+	t.Recur, t.RecurDays = t.Recur.next(), 0
+	if t.Recur == recurAfterDone {
+		t.RecurDays = 1
+	}
+	m.record("repeat "+t.repeats(), t.Text)
+	if t.Recur == recurNone {
+		m.notice = "No longer repeats"
+	} else {
+		m.notice = "Repeats " + t.repeats() + " • done adds the next one"
+	}
+}
+
+// renew adds the next repeat of task i once it's finished: a fresh copy,
+// without its subtasks, below it and them, due an interval on, or after
+// done, that many days from now. The finished
+// task stops repeating, so reopening and finishing it again adds no more.
+// It returns where the repeat went, or -1 when task i doesn't repeat.
+func (m *model) renew(i int) int {
+	t := m.tasks[i]
+	if t.Recur == recurNone || t.Status != statusDone {
+		return -1
+	}
+	m.tasks[i].Recur = recurNone
+
+	now := time.Now()
+	next := task{
+		Text: t.Text, Priority: t.Priority, Depth: t.Depth, Pinned: t.Pinned,
+		Due: t.nextDue(now), Lead: t.Lead, Created: now,
+		Tags: slices.Clone(t.Tags), Emoji: t.Emoji, Notes: t.Notes,
+		Attachments: slices.Clone(t.Attachments), Recur: t.Recur, RecurDays: t.RecurDays,
+	}
+	at := subtreeEnd(m.tasks, i)
+	m.tasks = slices.Insert(m.tasks, at, next)
+	m.record("repeat", next.Text)
+	m.notice = "Next one due " + m.formatDue(next.Due)
+	return at
+}
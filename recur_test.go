@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	for _, tc := range []struct {
+		word string
+		want recurrence
+		days int
+		ok   bool
+	}{
+		{"every:daily", recurDaily, 0, true},
+		{"every:week", recurWeekly, 0, true},
+		{"after:3d", recurAfterDone, 3, true},
+		{"after:5", recurAfterDone, 5, true},
+		{"After:2W", recurAfterDone, 14, true},
+		{"after:0d", recurNone, 0, false},
+		{"after:400d", recurNone, 0, false},
+		{"after:soon", recurNone, 0, false},
+		{"every:month", recurNone, 0, false},
+	} {
+		r, days, ok := parseRecurrence(tc.word)
+		if r != tc.want || days != tc.days || ok != tc.ok {
+			t.Errorf("parseRecurrence(%q) = %v, %d, %v; want %v, %d, %v", tc.word, r, days, ok, tc.want, tc.days, tc.ok)
+		}
+	}
+}
+
+func TestAfterDoneRepeatsFromWhenFinished(t *testing.T) {
+	due := time.Date(2026, time.May, 1, 18, 30, 0, 0, time.Local)
+	finished := time.Date(2026, time.May, 9, 10, 0, 0, 0, time.Local)
+	water := task{Text: "water plants", Due: due, Recur: recurAfterDone, RecurDays: 3}
+	if got, want := water.nextDue(finished), time.Date(2026, time.May, 12, 18, 30, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("three days after done from %v: due %v, want %v", finished, got, want)
+	}
+
+	// On a fixed schedule the same lateness only steps past today
+	water.Recur, water.RecurDays = recurWeekly, 0
+	if got, want := water.nextDue(finished), time.Date(2026, time.May, 15, 18, 30, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("weekly from %v: due %v, want %v", due, got, want)
+	}
+}
+
+func TestFinishingAfterDoneTaskAddsTheNext(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "water plants after:3d")
+	if m.tasks[0].Text != "water plants" || m.tasks[0].Recur != recurAfterDone || m.tasks[0].RecurDays != 3 {
+		t.Fatalf("added %+v, want water plants repeating 3 days after done", m.tasks[0])
+	}
+	m.setStatus(0, statusDone)
+	if len(m.tasks) != 2 {
+		t.Fatalf("list holds %d tasks after finishing, want the next one added", len(m.tasks))
+	}
+	next := m.tasks[1]
+	if want := startOfDay(time.Now()).AddDate(0, 0, 3); !next.Due.Equal(want) || next.RecurDays != 3 || next.Status == statusDone {
+		t.Fatalf("next one is %+v, want it open, due %v and repeating the same way", next, want)
+	}
+}
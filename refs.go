@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// refPattern finds references to other tasks in notes: # and a task ID, as
+// in #3fa9c1. Notes aren't read for tags, so a tag that happens to be six
+// hex digits is only taken for a reference there.
+var refPattern = regexp.MustCompile(fmt.Sprintf(`#([0-9a-f]{%d})\b`, idLength))
+
+// findTask returns where the task with ID id is, in any list, and the task
+func (m model) findTask(id string) (searchHit, task, bool) {
+	for l, list := range m.allLists() {
+		for i, t := range list.Tasks {
+			if t.ID == id {
+				return searchHit{list: l, task: i}, t, true
+			}
+		}
+	}
+	return searchHit{}, task{}, false
+}
+
+// linkRefs renders line with each reference in it followed by the text of
+// the task it names, or by (missing) once that task is gone
+func (m model) linkRefs(line string) string {
+	return refPattern.ReplaceAllStringFunc(line, func(ref string) string {
+		if _, t, ok := m.findTask(ref[1:]); ok {
+			return ref + " (" + t.Text + ")"
+		}
+		return ref + " (missing)"
+	})
+}
+
+// noteRefs returns the IDs t's notes refer to, each once, in the order they
+// first appear
+func noteRefs(t task) []string {
+	var ids []string
+	for _, match := range refPattern.FindAllStringSubmatch(t.Notes, -1) {
+		if !slices.Contains(ids, match[1]) {
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
+
+// followRef goes to the nth task, counting from 1, that the notes of the
+// task in the detail view refer to, switching to its list
+func (m *model) followRef(n int) {
+	refs := noteRefs(m.tasks[m.detailTask])
+	switch {
+	case len(refs) == 0:
+		m.notice = "These notes link no other task"
+		return
+	case n > len(refs):
+		m.notice = "These notes link only " + taskCount(len(refs))
+		return
+	}
+	at, _, ok := m.findTask(refs[n-1])
+	if !ok {
+		m.notice = "#" + refs[n-1] + " is missing: no list has that task any more"
+		return
+	}
+	m.switchList(at.list)
+	m.reveal(at.task)
+	m.moveCursorTo(at.task)
+	m.state = browsing
+}
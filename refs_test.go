@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestNotesNameTheTasksTheyLink(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "write report", "send report")
+	m.tasks[0].ID, m.tasks[1].ID = "aaa111", "bbb222"
+	m.tasks[1].Notes = "after #aaa111, not #ccc333"
+
+	lines := m.notesLines(m.tasks[1])
+	if want := "after #aaa111 (write report), not #ccc333 (missing)"; len(lines) != 2 || lines[1] != want {
+		t.Fatalf("notesLines = %q, want a blank line then %q", lines, want)
+	}
+}
+
+func TestFollowingALinkGoesToTheTask(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "write report", "send report")
+	m.tasks[0].ID, m.tasks[1].ID = "aaa111", "bbb222"
+	m.tasks[1].Notes = "#ccc333 then #aaa111"
+	m.detailTask, m.state = 1, viewingDetail
+
+	m = press(m, runes("1"))
+	if m.state != viewingDetail || m.notice == "" {
+		t.Fatalf("following a missing task left state %v, notice %q; want the detail view and a notice", m.state, m.notice)
+	}
+	m = press(m, runes("2"))
+	if i, _ := m.selected(); m.state != browsing || i != 0 {
+		t.Fatalf("following #aaa111 selected %d in state %v, want task 0 while browsing", i, m.state)
+	}
+}
+
+func TestFollowingALinkSwitchesList(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "here")
+	m.tasks[0].ID, m.tasks[0].Notes = "aaa111", "see #bbb222"
+	m.lists = append(m.lists, taskList{Name: "Other", Tasks: []task{{ID: "ccc333", Text: "first"}, {ID: "bbb222", Text: "there"}}})
+	m.detailTask, m.state = 0, viewingDetail
+
+	m = press(m, runes("1"))
+	if i, _ := m.selected(); m.active != 1 || i != 1 {
+		t.Fatalf("following #bbb222 opened list %d at %d, want list 1 at 1", m.active, i)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// replaceHit is a task of the open list that the replace would change
+type replaceHit struct {
+	task  int    // Index into m.tasks
+	text  string // The task's text after replacing
+	count int    // How many times the search term occurs in it
+}
+
+// startReplace asks what text to look for in the open list's tasks
+func (m model) startReplace() (tea.Model, tea.Cmd) {
+	if len(m.tasks) == 0 {
+		m.notice = "No tasks to replace in"
+		return m, nil
+	}
+	return m.startPrompt(promptReplaceFind, "Replace in "+m.lists[m.active].label()+":", m.replaceFind)
+}
+
+// startReplaceWith asks what to put in place of find
+func (m model) startReplaceWith(find string) (tea.Model, tea.Cmd) {
+	m.replaceFind = find
+	return m.startPrompt(promptReplaceWith, fmt.Sprintf("Replace %q with:", find), "")
+}
+
+// previewReplace shows what replacing with would change, for confirming
+func (m model) previewReplace(with string) (tea.Model, tea.Cmd) {
+	m.replaceWith = with
+	m.replaceCursor = 0
+	m.state = replacing
+	return m, nil
+}
+
+// replaceHits works out every task in the open list whose text the replace
+// would change, matching the search term's case only if replaceCase is set
+func (m model) replaceHits() []replaceHit {
+	pattern := regexp.QuoteMeta(m.replaceFind)
+	if !m.replaceCase {
+		pattern = "(?i)" + pattern
+	}
+	find := regexp.MustCompile(pattern)
+	var hits []replaceHit
+	for i, t := range m.tasks {
+		if n := len(find.FindAllStringIndex(t.Text, -1)); n > 0 {
+			text := truncateRunes(find.ReplaceAllLiteralString(t.Text, m.replaceWith), m.input.CharLimit)
+			if text != t.Text {
+				hits = append(hits, replaceHit{task: i, text: text, count: n})
+			}
+		}
+	}
+	return hits
+}
+
+// updateReplacing handles key input in the replace preview: c switches
+// between matching case and ignoring it, and enter makes the change
+func (m model) updateReplacing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	hits := m.replaceHits()
+	switch msg.String() {
+	case "esc", "q", "n":
+		m.state = browsing
+		m.notice = "Replace cancelled"
+
+	case "up", "k":
+		if m.replaceCursor > 0 {
+			m.replaceCursor--
+		}
+
+	case "down", "j":
+		if m.replaceCursor < len(hits)-1 {
+			m.replaceCursor++
+		}
+
+	case "c":
+		m.replaceCase = !m.replaceCase
+		m.replaceCursor = 0
+
+	case "enter", "y":
+		m.applyReplace(hits)
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// applyReplace rewrites the text of every task in hits as previewed, as one
+// change for undo, and reports how many matches were replaced
+func (m *model) applyReplace(hits []replaceHit) {
+	if len(hits) == 0 {
+		m.notice = fmt.Sprintf("Nothing contains %q", m.replaceFind)
+		return
+	}
+	m.checkpoint()
+	for _, hit := range hits {
+		m.tasks[hit.task].Text = hit.text
+		m.record("replace", hit.text)
+	}
+	m.notice = "Replaced " + tally(hits)
+}
+
+// tally counts the matches in hits and the tasks they're in, as in
+// "3 matches in 2 tasks"
+func tally(hits []replaceHit) string {
+	count := 0
+	for _, hit := range hits {
+		count += hit.count
+	}
+	matches, tasks := "matches", "tasks"
+	if count == 1 {
+		matches = "match"
+	}
+	if len(hits) == 1 {
+		tasks = "task"
+	}
+	return fmt.Sprintf("%d %s in %d %s", count, matches, len(hits), tasks)
+}
+
+// viewReplace renders each task the replace would change, before and
+// after, drawing only the rows that fit around the highlighted one
+func (m model) viewReplace() string {
+	hits := m.replaceHits()
+	s := titleStyle.Render(fmt.Sprintf("🔁 Replace %q with %q", m.replaceFind, m.replaceWith)) + "\n\n"
+	matching := "ignoring case"
+	if m.replaceCase {
+		matching = "matching case"
+	}
+	s += statusBarStyle.Render(tally(hits)+" • "+matching) + "\n\n"
+
+	room := len(hits)
+	if m.height > 0 {
+		room = max((m.height-8)/2, 2)
+	}
+	start := max(min(m.replaceCursor-room/2, len(hits)-room), 0)
+	for n := start; n < min(start+room, len(hits)); n++ {
+		hit := hits[n]
+		lead, style := m.gutter(), taskStyle
+		if n == m.replaceCursor {
+			lead, style = cursorStyle.Render(m.icons.Cursor+" "), selectedStyle
+		}
+		s += lead + metaStyle.Render("- "+m.tasks[hit.task].Text) + "\n"
+		s += m.gutter() + style.Render("+ "+hit.text) + "\n"
+	}
+	if len(hits) == 0 {
+		s += normalStyle.Render(fmt.Sprintf("Nothing contains %q.", m.replaceFind)) + "\n"
+	}
+	return s + "\n" + helpStyle.Render("↑/↓: scroll • c: match case or not • enter: replace • esc: cancel")
+}
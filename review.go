@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startReview steps through the open list's unfinished tasks one at a time,
+// starting from the top
+func (m model) startReview() (tea.Model, tea.Cmd) {
+	m.reviewTotal, m.reviewed = 0, 0
+	for _, t := range m.tasks {
+		if t.Status != statusDone {
+			m.reviewTotal++
+		}
+	}
+	m.reviewAt = m.nextForReview(0)
+	if m.reviewAt < 0 {
+		m.notice = "Nothing to review"
+		return m, nil
+	}
+	m.state = reviewing
+	return m, nil
+}
+
+// nextForReview returns the index of the first unfinished task at or after
+// from, or -1 when the rest of the list is finished
+func (m model) nextForReview(from int) int {
+	for i := from; i < len(m.tasks); i++ {
+		if m.tasks[i].Status != statusDone {
+			return i
+		}
+	}
+	return -1
+}
+
+// advanceReview moves on to the next task to review, starting at from, and
+// ends the review once there are none left
+func (m *model) advanceReview(from int) {
+	m.reviewed++
+	m.reviewAt = m.nextForReview(from)
+	if m.reviewAt < 0 {
+		m.state = browsing
+		m.notice = fmt.Sprintf("Review finished: %d of %d tasks reviewed", m.reviewed, m.reviewTotal)
+		m.moveCursorTo(0)
+	}
+}
+
+// updateReviewing handles key input in review mode
+func (m model) updateReviewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	i := m.reviewAt
+	switch msg.String() {
+	// Leave the review with the cursor on the task being reviewed
+	case "esc", "q":
+		m.state = browsing
+		m.moveCursorTo(i)
+
+	// Keep the task as it is
+	case "k", "enter", "right":
+		m.advanceReview(i + 1)
+
+	case "d":
+		m.checkpoint()
+		m.tasks[i].Status = statusDone
+		m.record(statusDone.String(), m.tasks[i].Text)
+		m.trackCompletion(&m.tasks[i])
+		from := i + 1
+		if j := m.renew(i); j >= 0 && m.nextForReview(from) == j {
+			from = j + 1 // The next repeat isn't up for review
+		}
+		m.advanceReview(from)
+
+	// Removing the task shifts the next one into its place
+	case "x":
+		m.checkpoint()
+		for _, t := range m.removeTask(i) {
+			m.record("delete", t.Text)
+		}
+		m.advanceReview(i)
+
+	// Put the task off until tomorrow
+	case "f":
+		m.checkpoint()
+		m.tasks[i].Due = startOfDay(time.Now()).AddDate(0, 0, 1)
+		m.record("defer", m.tasks[i].Text)
+		m.advanceReview(i + 1)
+
+	// Edit the text, staying on the task to decide what to do with it
+	case "e":
+		return m.startEdit(i)
+	}
+	return m, nil
+}
+
+// viewReview renders the task under review on a screen of its own
+func (m model) viewReview() string {
+	t := m.tasks[m.reviewAt]
+	s := titleStyle.Render(fmt.Sprintf("🔍 Review %d/%d", m.reviewed+1, m.reviewTotal)) + "\n\n"
+	s += "    " + m.statusMarker(t.Status) + " " + selectedStyle.Render(t.Text) + "\n"
+	// Spell metadata out as the detailed layout does
+	m.detailed = true
+	if meta := m.taskMeta(t); len(meta) > 0 {
+		s += "      " + strings.Join(meta, metaStyle.Render(" • ")) + "\n"
+	}
+	return s + "\n"
+}
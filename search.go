@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchHit locates a task matching the search in one of the lists
+type searchHit struct {
+	list int // Index into m.lists
+	task int // Index into that list's tasks
+}
+
+// searchEntry is one task as the search sees it
+type searchEntry struct {
+	searchHit
+	text string // Lowercased text and #tags
+}
+
+// startSearch opens the search across every list with an empty query.
+// Nothing changes while searching, so each task's text is lowercased here
+// once rather than on every keystroke.
+func (m model) startSearch() (tea.Model, tea.Cmd) {
+	m.searchInput.Reset()
+	m.searchInput.Focus()
+	m.searchCursor = 0
+	m.searchIndex = m.searchIndex[:0]
+	for l, list := range m.allLists() {
+		if m.solo && l != m.active {
+			continue
+		}
+		for i, t := range list.Tasks {
+			m.searchIndex = append(m.searchIndex, searchEntry{searchHit{list: l, task: i}, searchText(t)})
+		}
+	}
+	m.searchQuery, m.searchFound = "", nil
+	m.state = searching
+	return m, textinput.Blink
+}
+
+// hit returns the nth search hit
+func (m model) hit(n int) searchHit {
+	return m.searchIndex[m.searchFound[n]].searchHit
+}
+
+// findHits finds every task whose text or tags contain the query, ignoring
+// case, in list order so hits from the same list stay together. Only the
+// open list is searched while it's the only one showing. A query that only
+// adds to the last one can only match fewer tasks, so then just the last
+// hits are checked again, in place.
+func (m *model) findHits() {
+	query := strings.ToLower(strings.TrimSpace(m.searchInput.Value()))
+	if query == m.searchQuery {
+		return
+	}
+	narrowing := m.searchQuery != "" && strings.Contains(query, m.searchQuery)
+	m.searchQuery = query
+	if query == "" {
+		m.searchFound = nil
+		return
+	}
+	if narrowing {
+		kept := m.searchFound[:0]
+		for _, n := range m.searchFound {
+			if strings.Contains(m.searchIndex[n].text, query) {
+				kept = append(kept, n)
+			}
+		}
+		m.searchFound = kept
+		return
+	}
+	m.searchFound = m.searchFound[:0]
+	for n, e := range m.searchIndex {
+		if strings.Contains(e.text, query) {
+			m.searchFound = append(m.searchFound, n)
+		}
+	}
+}
+
+// reveal makes task i of the open list visible, lifting any filter that
+// hides it and expanding the subtasks it sits under
+func (m *model) reveal(i int) {
+	if m.tasks[i].Priority < m.minPriority {
+		m.minPriority = priorityNone
+	}
+	if !m.tasks[i].hasTag(m.tagFilter) {
+		m.tagFilter = ""
+	}
+	if !m.tasks[i].matches(m.filterQuery) {
+		m.filterQuery = ""
+	}
+	if !m.doneFilter.matches(m.tasks[i].Status) {
+		m.doneFilter = showAll
+	}
+	m.waitingView = m.tasks[i].Status == statusWaiting
+	depth := m.tasks[i].Depth
+	for j := i - 1; j >= 0 && depth > 0; j-- {
+		if m.tasks[j].Depth < depth {
+			if m.tasks[j].Collapsed {
+				m.tasks[j].Collapsed = false
+				m.dirty = true
+			}
+			depth = m.tasks[j].Depth
+		}
+	}
+}
+
+// matchStyle picks out the part of a hit's text the query matched
+var matchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62"))
+
+// highlightMatches renders text in style with each place it contains query,
+// already lowercased, picked out. Text whose lowercase takes up a different
+// number of bytes, as a few letters do, is left unmarked rather than marked
+// in the wrong place.
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	lower := strings.ToLower(text)
+	if query == "" || len(lower) != len(text) {
+		return style.Render(text)
+	}
+	var b strings.Builder
+	for {
+		at := strings.Index(lower, query)
+		if at < 0 {
+			break
+		}
+		b.WriteString(style.Render(text[:at]) + matchStyle.Render(text[at:at+len(query)]))
+		text, lower = text[at+len(query):], lower[at+len(query):]
+	}
+	return b.String() + style.Render(text)
+}
+
+// updateSearching handles key input while searching. Nothing changes until
+// a hit is opened, so leaving the search returns to where it started.
+func (m model) updateSearching(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.state = browsing
+		m.searchInput.Blur()
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.searchCursor < len(m.searchFound)-1 {
+			m.searchCursor++
+		}
+		return m, nil
+
+	// Jump to the highlighted hit, switching to its list
+	case "enter":
+		if m.searchCursor >= len(m.searchFound) {
+			return m, nil
+		}
+		hit := m.hit(m.searchCursor)
+		m.switchList(hit.list)
+		m.reveal(hit.task)
+		m.moveCursorTo(hit.task)
+		m.state = browsing
+		m.searchInput.Blur()
+		return m, nil
+	}
+
+	// Anything else edits the query, which starts the hits over from the top
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.searchCursor = 0
+	m.findHits()
+	return m, cmd
+}
+
+// viewSearch renders the query and its hits, grouped under the list they
+// come from. Only the hits that fit the terminal are drawn, keeping the
+// highlighted one in view.
+func (m model) viewSearch() string {
+	title, scope := "🔎 Search all lists", "every list's"
+	if m.solo {
+		title, scope = "🔎 Search "+m.lists[m.active].label(), "this list's"
+	}
+	s := titleStyle.Render(title) + "\n\n"
+	s += "  " + m.searchInput.View() + "\n\n"
+
+	hits := len(m.searchFound)
+	query := strings.TrimSpace(m.searchInput.Value())
+	switch {
+	case query == "":
+		s += normalStyle.Render("Type to search "+scope+" tasks and tags.") + "\n"
+	case hits == 0:
+		s += normalStyle.Render(fmt.Sprintf("Nothing matches %q.", query)) + "\n"
+	default:
+		lists := m.allLists()
+		room := hits
+		if m.height > 0 {
+			room = max(m.height-10, 3)
+		}
+		start := max(min(m.searchCursor-room/2, hits-room), 0)
+		end := min(start+room, hits)
+		for n := start; n < end; n++ {
+			hit := m.hit(n)
+			list := lists[hit.list]
+			if n == start || m.hit(n-1).list != hit.list {
+				s += "  " + list.style().Bold(true).Render(list.label()) + "\n"
+			}
+			t := list.Tasks[hit.task]
+			if n == m.searchCursor {
+				s += cursorStyle.Render(m.icons.Cursor+" ") + m.statusMarker(t.Status) + " " + highlightMatches(t.Text, m.searchQuery, selectedStyle) + "\n"
+			} else {
+				s += m.gutter() + m.statusMarker(t.Status) + " " + highlightMatches(t.Text, m.searchQuery, taskStyle) + "\n"
+			}
+		}
+		noun := "matches"
+		if hits == 1 {
+			noun = "match"
+		}
+		s += "\n" + metaStyle.Render(fmt.Sprintf("  %d %s", hits, noun)) + "\n"
+	}
+	return s + helpStyle.Render("↑/↓: choose • enter: go to task • esc: back")
+}
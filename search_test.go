@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BenchmarkSearchTyping opens the search over 20,000 tasks and types a
+// query a key at a time, redrawing after each key as the app does
+func BenchmarkSearchTyping(b *testing.B) {
+	m := bigModel(b, 20000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		next, _ := m.startSearch()
+		s := next.(model)
+		for _, r := range "number 12" {
+			next, _ = s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			s = next.(model)
+			_ = s.View()
+		}
+	}
+}
+
+func TestSearchNarrowsAsTheQueryGrows(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "buy milk", "buy bread", "call mum")
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlS}, runes("b"))
+	if len(m.searchFound) != 2 {
+		t.Fatalf("%q found %d tasks, want 2", "b", len(m.searchFound))
+	}
+	m = press(m, runes("read"))
+	if len(m.searchFound) != 1 || m.hit(0).task != 1 {
+		t.Fatalf("%q found %v, want only task 1", "bread", m.searchFound)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace},
+		tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace})
+	if len(m.searchFound) != 2 {
+		t.Fatalf("back to %q found %d tasks, want 2", "b", len(m.searchFound))
+	}
+}
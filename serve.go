@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pageTemplate renders the read-only task page
+var pageTemplate = template.Must(template.New("tasks").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="10">
+<title>To-Do</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40em; margin: 2em auto; padding: 0 1em; color: #333; }
+h1 { color: #875fff; }
+ul { list-style: none; padding: 0; }
+li { padding: .3em 0; }
+.doing { color: #d78700; }
+.done { color: #999; text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h1>📝 To-Do</h1>
+{{if not .}}<p>No tasks yet.</p>{{end}}
+<ul>
+{{range .}}<li class="{{.Status}}" style="margin-left: {{.Indent}}em">{{.Marker}} {{.Text}}</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// taskServer publishes a read-only snapshot of the tasks over HTTP. The
+// model pushes a fresh copy after every update, so requests never touch the
+// model itself.
+type taskServer struct {
+	mu    sync.RWMutex
+	tasks []task
+	srv   *http.Server
+}
+
+// pageRow is a task prepared for the page template
+type pageRow struct {
+	Text   string
+	Status string
+	Marker string
+	Indent int
+}
+
+// startTaskServer begins serving on addr, failing fast if the address
+// can't be bound
+func startTaskServer(addr string) (*taskServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &taskServer{}
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// publish replaces the snapshot served to clients
+func (s *taskServer) publish(tasks []task) {
+	snapshot := append([]task(nil), tasks...)
+	s.mu.Lock()
+	s.tasks = snapshot
+	s.mu.Unlock()
+}
+
+// ServeHTTP renders the current snapshot as an HTML page
+func (s *taskServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "read-only", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	rows := make([]pageRow, len(s.tasks))
+	for i, t := range s.tasks {
+		rows[i] = pageRow{Text: t.Text, Status: t.Status.String(), Marker: t.Status.glyph(), Indent: t.Depth * 2}
+	}
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pageTemplate.Execute(w, rows)
+}
+
+// shutdown stops the server, giving in-flight requests a moment to finish
+func (s *taskServer) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	s.srv.Shutdown(ctx)
+}
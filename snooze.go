@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultSnoozeHour is the hour of the morning a snoozed task comes back
+const defaultSnoozeHour = 9
+
+// snoozed reports whether the task is still out of the way at the bottom
+func (t task) snoozed(now time.Time) bool {
+	return now.Before(t.Snoozed)
+}
+
+// nextMorning returns the next time the clock reads hour o'clock after now:
+// later today if it's still early, otherwise tomorrow
+func nextMorning(now time.Time, hour int) time.Time {
+	at := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// snoozeTillMorning sinks the selected top-level task to the bottom of the
+// list until the next morning, or brings it back if it's already snoozed
+func (m *model) snoozeTillMorning() {
+	i, ok := m.selected()
+	if !ok {
+		return
+	}
+	now := time.Now()
+	t := &m.tasks[i]
+	switch {
+	case t.Depth > 0:
+		m.notice = "Only top-level tasks can be snoozed"
+		return
+	case t.Status == statusDone:
+		m.notice = "It's already done"
+		return
+	}
+	m.checkpoint()
+	if t.snoozed(now) {
+		t.Snoozed = time.Time{}
+		m.record("wake", t.Text)
+		m.notice = "Back from snoozing: " + truncateRunes(t.Text, 40)
+	} else {
+		t.Snoozed = nextMorning(now, m.snoozeHour)
+		m.record("snooze", t.Text)
+		m.notice = "Snoozed until " + m.snoozeTime(t.Snoozed, now) + " (u to undo)"
+	}
+	m.moveCursorTo(i)
+}
+
+// snoozeTime renders when a snoozed task comes back, naming the day unless
+// it's today
+func (m model) snoozeTime(at, now time.Time) string {
+	switch startOfDay(at).Sub(startOfDay(now)).Hours() {
+	case 0:
+		return at.Format("15:04")
+	case 24:
+		return "tomorrow " + at.Format("15:04")
+	}
+	return fmt.Sprintf("%s %s", m.formatDate(at), at.Format("15:04"))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snoozedModel returns the app with three high priority tasks, the middle
+// one snoozed till tomorrow, and the cursor on the first
+func snoozedModel(t *testing.T) model {
+	t.Helper()
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "first !3", "sleepy !3", "last !3")
+	m.tasks[1].Snoozed = time.Now().Add(24 * time.Hour)
+	m.moveCursorTo(0)
+	return m
+}
+
+func TestFrogPassesOverSnoozed(t *testing.T) {
+	m := snoozedModel(t)
+	m.tasks[0].Status = statusDone
+	m.tasks[1].Priority = priorityHigh
+	m.tasks[2].Priority = priorityLow
+	if i, ok := m.frog(); !ok || i != 2 {
+		t.Fatalf("frog picked %d, %v; want the one task not done or snoozed", i, ok)
+	}
+}
+
+func TestRandomPickPassesOverSnoozed(t *testing.T) {
+	m := snoozedModel(t)
+	for n := 0; n < 50; n++ {
+		m = press(m, runes("r"))
+		if i, _ := m.selected(); m.tasks[i].Text == "sleepy" {
+			t.Fatalf("r picked the snoozed task")
+		}
+	}
+}
+
+func TestCursorPassesOverSnoozed(t *testing.T) {
+	m := snoozedModel(t)
+	var seen []string
+	for n := 0; n < 3; n++ {
+		m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+		i, _ := m.selected()
+		seen = append(seen, m.tasks[i].Text)
+	}
+	for _, text := range seen {
+		if text == "sleepy" {
+			t.Fatalf("moving down landed on the snoozed task: %q", seen)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sortKey is one of the orders , sorts the list in
+type sortKey struct {
+	name    string // As the notice and the status bar put it
+	compare func(a, b task) int
+}
+
+// sortKeys lists the orders in the order , steps through them
+var sortKeys = []sortKey{
+	{"date added", compareCreated},
+	{"name", compareName},
+	{"status", func(a, b task) int { return cmp.Compare(statusRank(a.Status), statusRank(b.Status)) }},
+	{"priority", func(a, b task) int { return cmp.Compare(b.Priority, a.Priority) }},
+	{"due date", func(a, b task) int { return compareDue(a.Due, b.Due) }},
+}
+
+// tieBreaks are the orders sortTieBreak can pick to settle tasks a sort
+// finds equal, by the name the config gives them. With none, they keep the
+// order they had.
+var tieBreaks = map[string]func(a, b task) int{
+	"created": compareCreated,
+	"name":    compareName,
+	"none":    nil,
+}
+
+// defaultTieBreak settles ties when no valid sortTieBreak is configured
+const defaultTieBreak = "created"
+
+// compareCreated orders tasks oldest first. Tasks saved before the app
+// recorded when they were added tie.
+func compareCreated(a, b task) int {
+	return a.Created.Compare(b.Created)
+}
+
+// compareName orders tasks by their text, ignoring case
+func compareName(a, b task) int {
+	return strings.Compare(strings.ToLower(a.Text), strings.ToLower(b.Text))
+}
+
+// sortCompare returns key's order with ties settled by the tie-break, then
+// left in the order they had
+func (m model) sortCompare(key sortKey) func(a, b task) int {
+	if m.tieBreak == nil {
+		return key.compare
+	}
+	return func(a, b task) int {
+		if c := key.compare(a, b); c != 0 {
+			return c
+		}
+		return m.tieBreak(a, b)
+	}
+}
+
+// statusRank orders statuses from the most active to finished: doing, to
+// do, waiting, then done
+func statusRank(s taskStatus) int {
+	switch s {
+	case statusDoing:
+		return 0
+	case statusTodo:
+		return 1
+	case statusWaiting:
+		return 2
+	}
+	return 3
+}
+
+// compareDue orders due dates soonest first, with no due date after them all
+func compareDue(a, b time.Time) int {
+	if a.IsZero() || b.IsZero() {
+		return cmp.Compare(boolRank(a.IsZero()), boolRank(b.IsZero()))
+	}
+	return a.Compare(b)
+}
+
+// boolRank counts true after false
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortOrder returns the indices of tasks in the order compare sorts them to.
+// Each task keeps its subtasks under it, sorted among themselves the same
+// way, and tasks that compare equal keep the order they had.
+func sortOrder(tasks []task, compare func(a, b task) int) []int {
+	order := make([]int, 0, len(tasks))
+	var level func(from, to int)
+	level = func(from, to int) {
+		var roots []int
+		for i := from; i < to; i = subtreeEnd(tasks, i) {
+			roots = append(roots, i)
+		}
+		slices.SortStableFunc(roots, func(a, b int) int { return compare(tasks[a], tasks[b]) })
+		for _, root := range roots {
+			order = append(order, root)
+			level(root+1, subtreeEnd(tasks, root))
+		}
+	}
+	level(0, len(tasks))
+	return order
+}
+
+// sortList sorts the open list in the order after the one it was sorted in
+// last, as a change undo can take back. The cursor stays on the task it
+// was on.
+func (m *model) sortList() {
+	if len(m.tasks) < 2 {
+		return
+	}
+	m.sortedBy = (m.sortedBy + 1) % len(sortKeys)
+	key := sortKeys[m.sortedBy]
+	order := sortOrder(m.tasks, m.sortCompare(key))
+	i, ok := m.selected()
+	m.checkpoint()
+	m.describeChange("sort by " + key.name)
+	sorted := make([]task, len(order))
+	for n, j := range order {
+		sorted[n] = m.tasks[j]
+	}
+	m.tasks = sorted
+	if ok {
+		m.moveCursorTo(slices.Index(order, i))
+	}
+	m.notice = "Sorted by " + key.name + " • , for the next order"
+}
+
+// sortedLabel names the order the list was last sorted in while it's still
+// in it, or is empty once it's been moved about since
+func (m model) sortedLabel() string {
+	if m.sortedBy < 0 {
+		return ""
+	}
+	for n, i := range sortOrder(m.tasks, m.sortCompare(sortKeys[m.sortedBy])) {
+		if n != i {
+			return ""
+		}
+	}
+	return sortKeys[m.sortedBy].name
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// tiedTasks returns tasks that all share a priority, added out of the order
+// their names sort in, under a task of higher priority
+func tiedTasks() []task {
+	day := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	return []task{
+		{Text: "carrots", Priority: priorityLow, Created: day.Add(2 * time.Hour)},
+		{Text: "apples", Priority: priorityLow, Created: day.Add(3 * time.Hour)},
+		{Text: "urgent", Priority: priorityHigh, Created: day.Add(4 * time.Hour)},
+		{Text: "bread", Priority: priorityLow, Created: day.Add(1 * time.Hour)},
+		{Text: "dates", Priority: priorityLow},
+	}
+}
+
+// sortedTexts sorts tasks by priority with the tie-break named, returning
+// their text in the new order
+func sortedTexts(tasks []task, tieBreak string) []string {
+	m := model{tieBreak: tieBreaks[tieBreak]}
+	byPriority := sortKeys[slices.IndexFunc(sortKeys, func(k sortKey) bool { return k.name == "priority" })]
+	var texts []string
+	for _, i := range sortOrder(tasks, m.sortCompare(byPriority)) {
+		texts = append(texts, tasks[i].Text)
+	}
+	return texts
+}
+
+func TestSortTieBreaks(t *testing.T) {
+	for _, tc := range []struct {
+		tieBreak string
+		want     []string
+	}{
+		{"none", []string{"urgent", "carrots", "apples", "bread", "dates"}},
+		{"created", []string{"urgent", "dates", "bread", "carrots", "apples"}},
+		{"name", []string{"urgent", "apples", "bread", "carrots", "dates"}},
+	} {
+		if got := sortedTexts(tiedTasks(), tc.tieBreak); !slices.Equal(got, tc.want) {
+			t.Errorf("by priority, then %s: got %q, want %q", tc.tieBreak, got, tc.want)
+		}
+	}
+}
+
+func TestSortIsStableAcrossRepeats(t *testing.T) {
+	for name := range tieBreaks {
+		m := model{tieBreak: tieBreaks[name]}
+		for _, key := range sortKeys {
+			tasks := tiedTasks()
+			compare := m.sortCompare(key)
+			for n := 0; n < 3; n++ {
+				order := sortOrder(tasks, compare)
+				sorted := make([]task, len(order))
+				for j, i := range order {
+					sorted[j] = tasks[i]
+				}
+				if n > 0 && !slices.Equal(order, []int{0, 1, 2, 3, 4}) {
+					t.Fatalf("sorting by %s, then %s, again moved tasks: %v", key.name, name, order)
+				}
+				tasks = sorted
+			}
+		}
+	}
+}
+
+func TestSortKeepsSubtasksUnderTheirTask(t *testing.T) {
+	tasks := []task{
+		{Text: "b", Priority: priorityLow},
+		{Text: "b2", Depth: 1},
+		{Text: "b1", Depth: 1},
+		{Text: "a", Priority: priorityHigh},
+	}
+	texts := sortedTexts(tasks, "name")
+	if want := []string{"a", "b", "b1", "b2"}; !slices.Equal(texts, want) {
+		t.Fatalf("got %q, want %q", texts, want)
+	}
+}
+
+func TestUnknownTieBreakFallsBack(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.SortTieBreak = "size"
+	if warnings := cfg.validate(); len(warnings) != 1 || cfg.SortTieBreak != defaultTieBreak {
+		t.Fatalf("validate left %q with warnings %q, want %s and one warning", cfg.SortTieBreak, warnings, defaultTieBreak)
+	}
+}
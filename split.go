@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Boxed pane showing the selected task beside the list
+var detailPaneStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("240")).
+	Padding(0, 1)
+
+// splitWidth is how many columns the terminal needs before the detail pane
+// shows beside the list; narrower terminals show the list alone
+const splitWidth = 120
+
+// paneWidth returns how wide the detail pane is drawn, borders included, or
+// zero when the split is off or the terminal is too narrow for it
+func (m model) paneWidth() int {
+	if !m.split || m.zen || m.width < splitWidth {
+		return 0
+	}
+	return m.width * 2 / 5
+}
+
+// listPane returns the model as the list sees it beside the detail pane:
+// narrowed to leave room for it
+func (m model) listPane() model {
+	m.width -= m.paneWidth() + 1
+	m.split = false
+	return m
+}
+
+// viewDetailPane renders the selected task's text and fields, wrapped to
+// fit a pane w cells wide
+func (m model) viewDetailPane(w int) string {
+	lines := []string{titleStyle.UnsetMarginBottom().Render("📄 Task"), ""}
+	if i, ok := m.selected(); ok {
+		t := m.tasks[i]
+		lines = append(lines, m.statusMarker(t.Status)+" "+selectedStyle.Render(t.Text), "")
+		for _, f := range append(m.detailFields(t), m.notesLines(t)...) {
+			lines = append(lines, taskStyle.Render(f))
+		}
+	} else {
+		lines = append(lines, taskStyle.Render("Nothing selected"))
+	}
+	return detailPaneStyle.Width(w - detailPaneStyle.GetHorizontalBorderSize()).Render(strings.Join(lines, "\n"))
+}
+
+// viewSplit renders the list on the left and the selected task's details
+// on the right
+func (m model) viewSplit() string {
+	w := m.paneWidth()
+	list := m.listPane()
+	var body string
+	if list.cheatSheet {
+		body = list.viewTasksWithCheatSheet()
+	} else {
+		body = list.viewTasks()
+	}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(strings.TrimRight(line, " "), list.width, "…")
+	}
+	body = lipgloss.NewStyle().Width(list.width).Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, body, " ", m.viewDetailPane(w))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// savedData is the on-disk layout of the tasks file
+type savedData struct {
+	Lists  []taskList `json:"lists"`
+	Active int        `json:"active"`           // List that was open when the app last saved
+	Folded []string   `json:"folded,omitempty"` // Folders folded in the list switcher
+	Colors string     `json:"colors,omitempty"` // Color mode picked with ctrl+p, empty for auto
+	Theme  string     `json:"theme,omitempty"`  // Theme picked with ctrl+b, empty for the config's
+
+	// Whether ctrl+o left the completed fold open
+	DoneOpen bool `json:"doneOpen,omitempty"`
+
+	// Day the app was last started, as YYYY-MM-DD, so the daily roll-up
+	// only offers itself once a day
+	LastRun string `json:"lastRun,omitempty"`
+
+	// Whether b last hid the key hints, overriding showHints; nil until
+	// it's been pressed
+	HideHints *bool `json:"hideHints,omitempty"`
+
+	// Whether @ last switched due dates to relative, overriding
+	// relativeDue; nil until it's been pressed
+	RelativeDue *bool `json:"relativeDue,omitempty"`
+
+	// Daily and weekly completion goals last set in the overview,
+	// overriding dailyGoal and weeklyGoal; nil until they've been changed
+	Goals *[2]int `json:"goals,omitempty"`
+}
+
+// dataPath returns the default location of the tasks file
+func dataPath() string {
+	return filepath.Join(configDir(), "tasks.json")
+}
+
+// checkDataPath makes sure the tasks file can be kept at path, creating the
+// directory it goes in if that's missing, so a bad --file fails at startup
+// rather than on the first save
+func checkDataPath(path string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a tasks file", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("can't keep tasks in %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadTasks reads every list from the tasks file. A missing file yields no
+// lists and no error. A file that can't be parsed is moved aside to
+// <path>.bad so the next save can't overwrite it, and an error says so.
+func loadTasks(path string) (savedData, error) {
+	var data savedData
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return data, fmt.Errorf("reading tasks: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &data); err != nil {
+		if renameErr := os.Rename(path, path+".bad"); renameErr != nil {
+			return savedData{}, fmt.Errorf("%s is unreadable (%v) and couldn't be moved aside: %w", path, err, renameErr)
+		}
+		return savedData{}, fmt.Errorf("%s was unreadable and has been moved to %s.bad", path, path)
+	}
+	return data, nil
+}
+
+// saveTasks writes every list to the tasks file, creating its directory if
+// needed, unless the file already holds exactly that. The write is atomic: a
+// crash part way through leaves the old file as it was rather than a
+// truncated one. Only once the new file is in place does the one it
+// replaced become backup .1, so a failed save costs no backups; a backup
+// that fails is returned as backupErr, with the save itself done.
+func saveTasks(path string, data savedData, keep int) (backupErr, err error) {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	old, readErr := os.ReadFile(path)
+	if readErr == nil && bytes.Equal(old, raw) {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, raw); err != nil {
+		return nil, err
+	}
+	if readErr != nil {
+		return nil, nil // Nothing was there to back up
+	}
+	return rotateBackups(path, old, keep), nil
+}
+
+// backupName returns the path of the tasks file's nth backup, <path>.n
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// rotateBackups moves each numbered backup of the tasks file along one,
+// so .1 becomes .2 and so on, keeps old, what the file held before the save
+// just made, as .1, and then prunes backups numbered above keep. keep of
+// zero or less makes no backups at all.
+func rotateBackups(path string, old []byte, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	for n := keep - 1; n >= 1; n-- {
+		if err := os.Rename(backupName(path, n), backupName(path, n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := writeFileAtomic(backupName(path, 1), old); err != nil {
+		return err
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), filepath.Base(path)+".")
+		if n, err := strconv.Atoi(suffix); ok && err == nil && n > keep {
+			os.Remove(backupName(path, n))
+		}
+	}
+	return nil
+}
+
+// syncFile flushes f to disk. Tests swap it out to fail a write part way.
+var syncFile = (*os.File).Sync
+
+// writeFileAtomic writes raw to path by way of a temporary file beside it,
+// flushed to disk and then renamed into place, so path is never left half
+// written: it holds either the old contents or the new
+func writeFileAtomic(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Only still there if something failed
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := syncFile(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Flush the rename too where the system allows syncing a directory
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// allLists returns every list with the active list brought up to date from
+// the model
+func (m model) allLists() []taskList {
+	lists := append([]taskList(nil), m.lists...)
+	lists[m.active] = m.activeList()
+	return lists
+}
+
+// save writes the current state to the tasks file, leaving the model dirty
+// and showing a notice if the write fails so it's retried on the next change.
+// The file as it was goes to the newest backup once the save is written;
+// if that fails the save still stands, with a notice.
+func (m *model) save() {
+	m.assignIDs()
+	data := savedData{Lists: m.allLists(), Active: m.active, Folded: m.foldedFolders(), LastRun: m.lastRun, DoneOpen: m.doneOpen}
+	if m.colors > 0 {
+		data.Colors = colorProfiles[m.colors].name
+	}
+	if m.theme != m.themeDefault {
+		data.Theme = themes[m.theme].name
+	}
+	if m.hideHints != m.hintsOff {
+		data.HideHints = &m.hideHints
+	}
+	if m.relativeDue != m.relativeDefault {
+		data.RelativeDue = &m.relativeDue
+	}
+	if goals := [2]int{m.dailyGoal, m.weeklyGoal}; goals != m.goalDefaults {
+		data.Goals = &goals
+	}
+	backupErr, err := saveTasks(m.dataFile, data, m.backups)
+	if err != nil {
+		m.notice = "Could not save: " + err.Error()
+		return
+	}
+	if backupErr != nil {
+		m.notice = "Could not back up: " + backupErr.Error()
+	}
+	m.dirty = false
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// listData is saved data holding one list with a task reading text
+func listData(text string) savedData {
+	return savedData{Lists: []taskList{{Name: "To-Do", Tasks: []task{{Text: text}}}}}
+}
+
+// readFile returns the contents of path, or "" when it isn't there
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+// mustSave saves data to path keeping keep backups, failing the test on
+// any error
+func mustSave(t *testing.T, path string, data savedData, keep int) {
+	t.Helper()
+	backupErr, err := saveTasks(path, data, keep)
+	if err != nil || backupErr != nil {
+		t.Fatalf("saveTasks: %v, backup: %v", err, backupErr)
+	}
+}
+
+// failSync makes every atomic write fail after writing, before the rename,
+// until the test ends
+func failSync(t *testing.T) {
+	t.Helper()
+	saved := syncFile
+	syncFile = func(*os.File) error { return errors.New("no space left on device") }
+	t.Cleanup(func() { syncFile = saved })
+}
+
+func TestSaveUnchangedKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	mustSave(t, path, listData("first"), 3)
+	mustSave(t, path, listData("second"), 3)
+	first := readFile(t, path+".1")
+
+	// Opening and quitting without a change saves the same data again
+	for n := 0; n < 3; n++ {
+		mustSave(t, path, listData("second"), 3)
+	}
+	if got := readFile(t, path+".1"); got != first {
+		t.Errorf(".1 = %q, want the first save kept", got)
+	}
+	if _, err := os.Stat(path + ".2"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf(".2 exists after saves that changed nothing: %v", err)
+	}
+}
+
+func TestFailedSaveKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	mustSave(t, path, listData("first"), 3)
+	mustSave(t, path, listData("second"), 3)
+	current, first := readFile(t, path), readFile(t, path+".1")
+
+	failSync(t)
+	for n := 0; n < 3; n++ { // Retried on every change while it fails
+		if _, err := saveTasks(path, listData("third"), 3); err == nil {
+			t.Fatal("saveTasks succeeded with the write failing")
+		}
+	}
+	if got := readFile(t, path); got != current {
+		t.Errorf("tasks file = %q, want it as it was", got)
+	}
+	if got := readFile(t, path+".1"); got != first {
+		t.Errorf(".1 = %q, want it as it was", got)
+	}
+	if got := readFile(t, path+".2"); got != "" {
+		t.Errorf(".2 = %q, want none made by failed saves", got)
+	}
+}
+
+func TestBackupsRotateAndPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	var saved []string
+	for _, text := range []string{"a", "b", "c", "d"} {
+		mustSave(t, path, listData(text), 2)
+		saved = append(saved, readFile(t, path))
+	}
+	if got := readFile(t, path+".1"); got != saved[2] {
+		t.Errorf(".1 = %q, want the save before last", got)
+	}
+	if got := readFile(t, path+".2"); got != saved[1] {
+		t.Errorf(".2 = %q, want the save before that", got)
+	}
+	if got := readFile(t, path+".3"); got != "" {
+		t.Errorf(".3 = %q, want it pruned", got)
+	}
+}
+
+func TestNoBackupsKeptWithZero(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	mustSave(t, path, listData("a"), 0)
+	mustSave(t, path, listData("b"), 0)
+	if got := readFile(t, path+".1"); got != "" {
+		t.Errorf(".1 = %q, want no backups", got)
+	}
+}
+
+func TestInterruptedWriteLeavesFileIntact(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.json")
+	if err := writeFileAtomic(path, []byte(`{"lists":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	failSync(t)
+	err := writeFileAtomic(path, []byte(`{"lists":[{"name":"half`))
+	if err == nil {
+		t.Fatal("writeFileAtomic succeeded with the write failing")
+	}
+	if got := readFile(t, path); got != `{"lists":[]}` {
+		t.Errorf("file = %q after an interrupted write, want the original", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %v, want only the tasks file", names)
+	}
+}
+
+func TestInterruptedSaveLoadsAsBefore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	mustSave(t, path, listData("kept"), 3)
+
+	failSync(t)
+	if _, err := saveTasks(path, listData("lost"), 3); err == nil {
+		t.Fatal("saveTasks succeeded with the write failing")
+	}
+	data, err := loadTasks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Lists) != 1 || data.Lists[0].Tasks[0].Text != "kept" {
+		t.Errorf("loaded %+v, want the list as last saved", data.Lists)
+	}
+}
+
+func TestAutosaveWritesUnsavedChanges(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m.tasks = append(m.tasks, task{Text: "not yet written"})
+	m.dirty = true
+
+	next, cmd := m.update(autosaveMsg{})
+	if got := readFile(t, m.dataFile); !strings.Contains(got, "not yet written") {
+		t.Fatalf("tasks file after the tick = %q, want the unsaved task in it", got)
+	}
+	if next.(model).dirty {
+		t.Error("still dirty after the tick saved")
+	}
+	if cmd == nil {
+		t.Error("the tick didn't schedule the next one")
+	}
+}
+
+func TestAutosaveLeavesCleanModelUnwritten(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	if err := os.Remove(m.dataFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatal(err)
+	}
+
+	_, cmd := m.update(autosaveMsg{})
+	if _, err := os.Stat(m.dataFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a tick with nothing to save wrote the tasks file: %v", err)
+	}
+	if cmd == nil {
+		t.Error("the tick didn't schedule the next one")
+	}
+}
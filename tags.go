@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Tag style: tags shown next to a task
+var tagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+// tagColor returns the color configured for the first of t's tags that has
+// one
+func (m model) tagColor(t task) (lipgloss.Color, bool) {
+	for _, tag := range t.Tags {
+		if color, ok := m.tagColors[tag]; ok {
+			return lipgloss.Color(color), true
+		}
+	}
+	return "", false
+}
+
+// rowStyle picks the style for a task's text: dimmed while it's waiting,
+// then its tag's color if it has one, then red or yellow for high or medium
+// priority when priorityColors is on, and otherwise colored by age. The
+// zen view only dims finished tasks.
+func (m model) rowStyle(t task, now time.Time) lipgloss.Style {
+	if m.zen {
+		if t.Status == statusDone {
+			return metaStyle
+		}
+		return taskStyle
+	}
+	if color, ok := m.tagColor(t); ok && t.Status != statusWaiting {
+		return taskStyle.Foreground(color)
+	}
+	if m.dueColors && t.Status != statusWaiting {
+		switch {
+		case t.pastDue(now):
+			return taskStyle.Foreground(overdueColor)
+		case t.dueToday(now):
+			return taskStyle.Foreground(dueTodayColor)
+		}
+	}
+	if m.priorityColors && t.Status != statusDone && t.Status != statusWaiting && t.Priority >= priorityMedium {
+		return taskStyle.Foreground(priorityStyle(t.Priority).GetForeground())
+	}
+	return m.ageStyle(t, now)
+}
+
+// toggleTodayTag adds today's date as a tag on task i, or takes it off if
+// it's already there
+func (m *model) toggleTodayTag(i int, now time.Time) {
+	m.checkpoint()
+	t := &m.tasks[i]
+	tag := now.Format(dueLayout)
+	if at := slices.Index(t.Tags, tag); at >= 0 {
+		t.Tags = slices.Delete(t.Tags, at, at+1)
+		m.record("untag #"+tag, t.Text)
+		m.notice = "Removed #" + tag
+		return
+	}
+	t.Tags = append(t.Tags, tag)
+	m.record("tag #"+tag, t.Text)
+	m.notice = "Tagged #" + tag
+}
+
+// marked returns the indices into m.tasks of the marked tasks
+func (m model) marked() []int {
+	var marked []int
+	for i, t := range m.tasks {
+		if t.Marked {
+			marked = append(marked, i)
+		}
+	}
+	return marked
+}
+
+// clearMarks unmarks every task in the open list
+func (m *model) clearMarks() {
+	for i := range m.tasks {
+		m.tasks[i].Marked = false
+	}
+}
+
+// markAll marks every task shown in the list, or unmarks them all when
+// any are marked already
+func (m *model) markAll() {
+	if len(m.marked()) > 0 {
+		m.clearMarks()
+		m.notice = "Unmarked all tasks"
+		return
+	}
+	rows := m.visible()
+	for _, i := range rows {
+		m.tasks[i].Marked = true
+	}
+	if len(rows) > 0 {
+		m.notice = "Marked " + taskCount(len(rows)) + " • x deletes them, * unmarks"
+	}
+}
+
+// markedCount spells out n marked tasks, as in "1 marked task"
+func markedCount(n int) string {
+	if n == 1 {
+		return "1 marked task"
+	}
+	return fmt.Sprintf("%d marked tasks", n)
+}
+
+// deleteMarked deletes every marked task as one change undo can take back,
+// each as x would on its own: a collapsed one takes its subtasks with it
+func (m *model) deleteMarked() {
+	marked := m.marked()
+	if len(marked) == 0 {
+		return
+	}
+	m.checkpoint()
+	m.describeChange("deleting " + markedCount(len(marked)))
+	for n := len(marked) - 1; n >= 0; n-- { // From the bottom, so the rest stay put
+		for _, t := range m.removeTask(marked[n]) {
+			m.record("delete", t.Text)
+		}
+	}
+	m.clearMarks()
+	m.moveCursorTo(marked[0])
+	m.notice = fmt.Sprintf("Deleted %s (u to undo)", markedCount(len(marked)))
+}
+
+// bulkTargets returns the tasks a bulk action applies to: the marked ones,
+// or the selected task when none are marked
+func (m model) bulkTargets() []int {
+	if marked := m.marked(); len(marked) > 0 {
+		return marked
+	}
+	if i, ok := m.selected(); ok {
+		return []int{i}
+	}
+	return nil
+}
+
+// startBulkTag asks for a tag to add to every marked task, or to take off
+// them when written with a leading -
+func (m model) startBulkTag() (tea.Model, tea.Cmd) {
+	n := len(m.bulkTargets())
+	if n == 0 {
+		return m, nil
+	}
+	what := "selected task"
+	if len(m.marked()) > 0 {
+		what = fmt.Sprintf("%d marked tasks", n)
+	}
+	return m.startPrompt(promptBulkTag, "Tag "+what+" (#tag adds, -#tag removes):", "#")
+}
+
+// bulkTag adds the tag in answer to the bulk targets, or removes it when
+// answer starts with -, then unmarks them
+func (m *model) bulkTag(answer string) {
+	remove := strings.HasPrefix(answer, "-")
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(answer, "-"), "#"))
+	if !tagPattern.MatchString("#" + tag) {
+		m.notice = fmt.Sprintf("%q isn't a tag", answer)
+		return
+	}
+
+	var changing []int
+	for _, i := range m.bulkTargets() {
+		if m.tasks[i].hasTag(tag) == remove {
+			changing = append(changing, i)
+		}
+	}
+	m.clearMarks()
+	if len(changing) == 0 {
+		m.notice = "Nothing to change: they all have #" + tag
+		if remove {
+			m.notice = "Nothing to change: none of them have #" + tag
+		}
+		return
+	}
+
+	m.checkpoint()
+	for _, i := range changing {
+		t := &m.tasks[i]
+		if remove {
+			t.Tags = slices.DeleteFunc(t.Tags, func(s string) bool { return s == tag })
+			m.record("untag #"+tag, t.Text)
+		} else {
+			t.Tags = append(t.Tags, tag)
+			m.record("tag #"+tag, t.Text)
+		}
+	}
+	noun := "tasks"
+	if len(changing) == 1 {
+		noun = "task"
+	}
+	if remove {
+		m.notice = fmt.Sprintf("Removed #%s from %d %s", tag, len(changing), noun)
+	} else {
+		m.notice = fmt.Sprintf("Tagged %d %s #%s", len(changing), noun, tag)
+	}
+}
+
+// tagCount summarizes how one tag is used across the open list
+type tagCount struct {
+	Tag   string
+	Total int
+	Done  int
+}
+
+// tagCounts tallies every tag in the open list, most used first and
+// alphabetically among equals
+func (m model) tagCounts() []tagCount {
+	index := map[string]int{}
+	var counts []tagCount
+	for _, t := range m.tasks {
+		for _, tag := range t.Tags {
+			i, ok := index[tag]
+			if !ok {
+				i = len(counts)
+				index[tag] = i
+				counts = append(counts, tagCount{Tag: tag})
+			}
+			counts[i].Total++
+			if t.Status == statusDone {
+				counts[i].Done++
+			}
+		}
+	}
+	sort.Slice(counts, func(a, b int) bool {
+		if counts[a].Total != counts[b].Total {
+			return counts[a].Total > counts[b].Total
+		}
+		return counts[a].Tag < counts[b].Tag
+	})
+	return counts
+}
+
+// updateViewingTags handles key input in the tag overview. Row 0 is "all
+// tasks", which clears the filter; the rest are the tags from tagCounts.
+func (m model) updateViewingTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	counts := m.tagCounts()
+
+	switch msg.String() {
+	case "esc", "q", "T":
+		m.state = browsing
+
+	case "up", "k":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+
+	case "down", "j":
+		if m.tagCursor < len(counts) {
+			m.tagCursor++
+		}
+
+	// Show only the highlighted tag's tasks
+	case "enter":
+		real, _ := m.selected()
+		m.tagFilter = ""
+		if m.tagCursor > 0 {
+			m.tagFilter = counts[m.tagCursor-1].Tag
+		}
+		m.moveCursorTo(real)
+		m.state = browsing
+	}
+	return m, nil
+}
+
+// viewTags renders the tag overview
+func (m model) viewTags() string {
+	s := titleStyle.Render("🏷️ Tags") + "\n\n"
+	counts := m.tagCounts()
+
+	rows := []string{fmt.Sprintf("All tasks (%d)", len(m.tasks))}
+	for _, c := range counts {
+		noun := "tasks"
+		if c.Total == 1 {
+			noun = "task"
+		}
+		rows = append(rows, fmt.Sprintf("#%s  %d %s • %d/%d done", c.Tag, c.Total, noun, c.Done, c.Total))
+	}
+	for i, row := range rows {
+		if i == m.tagCursor {
+			s += cursorStyle.Render(m.icons.Cursor+" ") + selectedStyle.Render(row) + "\n"
+		} else {
+			s += m.gutter() + taskStyle.Render(row) + "\n"
+		}
+	}
+	if len(counts) == 0 {
+		s += "\n" + normalStyle.Render("No tags yet. Add #tags to a task's text.") + "\n"
+	}
+	return s + "\n" + helpStyle.Render("enter: filter • esc: back")
+}
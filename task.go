@@ -0,0 +1,471 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// taskStatus tracks where a task is in its lifecycle
+type taskStatus int
+
+const (
+	statusTodo taskStatus = iota
+	statusDoing
+	statusDone
+	statusWaiting // Blocked on someone else; set apart from the todo → doing → done cycle
+)
+
+// String returns the lowercase name of the status
+func (s taskStatus) String() string {
+	switch s {
+	case statusDoing:
+		return "doing"
+	case statusDone:
+		return "done"
+	case statusWaiting:
+		return "waiting"
+	default:
+		return "todo"
+	}
+}
+
+// glyph returns the unstyled marker for the status
+func (s taskStatus) glyph() string {
+	switch s {
+	case statusDoing:
+		return "◐"
+	case statusDone:
+		return "●"
+	case statusWaiting:
+		return "◌"
+	default:
+		return "○"
+	}
+}
+
+// next returns the status that follows s when cycling todo → doing → done.
+// A waiting task goes back to todo.
+func (s taskStatus) next() taskStatus {
+	if s == statusWaiting {
+		return statusTodo
+	}
+	return (s + 1) % 3
+}
+
+// doneFilter narrows the view to the tasks still to do, or to the finished
+// ones
+type doneFilter int
+
+const (
+	showAll doneFilter = iota
+	showActive
+	showDone
+)
+
+// matches reports whether a task with status s passes the filter
+func (f doneFilter) matches(s taskStatus) bool {
+	switch f {
+	case showActive:
+		return s != statusDone
+	case showDone:
+		return s == statusDone
+	}
+	return true
+}
+
+// String names the filter as the status bar shows it
+func (f doneFilter) String() string {
+	switch f {
+	case showActive:
+		return "active"
+	case showDone:
+		return "done"
+	default:
+		return "all"
+	}
+}
+
+// priority ranks how important a task is
+type priority int
+
+const (
+	priorityNone priority = iota
+	priorityLow
+	priorityMedium
+	priorityHigh
+)
+
+// String returns the lowercase name of the priority
+func (p priority) String() string {
+	switch p {
+	case priorityLow:
+		return "low"
+	case priorityMedium:
+		return "medium"
+	case priorityHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// priorityNamed looks up a priority by the name String gives it
+func priorityNamed(name string) (priority, bool) {
+	for p := priorityNone; p <= priorityHigh; p++ {
+		if strings.EqualFold(name, p.String()) {
+			return p, true
+		}
+	}
+	return priorityNone, false
+}
+
+// next returns the priority that follows p, wrapping from high to none
+func (p priority) next() priority {
+	return (p + 1) % 4
+}
+
+// task is a single to-do item
+type task struct {
+	ID        string     `json:"id,omitempty"` // Short and random, given on the first save and kept from then on
+	Text      string     `json:"text"`
+	Status    taskStatus `json:"status"`
+	Priority  priority   `json:"priority,omitempty"`
+	Progress  int        `json:"progress,omitempty"`  // Percent done, 0-100, finer grained than the status
+	Depth     int        `json:"depth,omitempty"`     // Nesting level; 0 for top-level tasks
+	Collapsed bool       `json:"collapsed,omitempty"` // Subtasks are hidden from the list
+	Pinned    bool       `json:"pinned,omitempty"`    // Kept above the rest of the list; top-level tasks only
+	Boosted   time.Time  `json:"boosted"`             // Kept above the rest like a pin until this moment; zero when never boosted
+	Snoozed   time.Time  `json:"snoozed"`             // Sunk to the bottom until this moment; zero when never snoozed
+	Due       time.Time  `json:"due"`                 // Zero when the task has no due date; midnight unless it's due at a time of day
+	Lead      int        `json:"lead,omitempty"`      // Days ahead of Due to be reminded; zero uses the configured lead
+	Reminded  time.Time  `json:"reminded"`            // Due date the lead reminder last went off for, so moving Due rearms it
+	Bumped    time.Time  `json:"bumped"`              // Due date the priority was last raised for going overdue past
+	Alerted   time.Time  `json:"alerted"`             // Due time the at-the-time reminder last went off for
+	Created   time.Time  `json:"created"`             // Zero for tasks saved before this was tracked
+	Completed time.Time  `json:"completed"`           // When the task was last marked done; zero while unfinished
+	Tags      []string   `json:"tags,omitempty"`      // Lowercase, without the leading #
+	WaitingOn string     `json:"waitingOn,omitempty"` // Who or what a waiting task is blocked on
+	Emoji     string     `json:"emoji,omitempty"`     // Shown before the text, picked with :
+	Carried   int        `json:"carried,omitempty"`   // Times the daily roll-up has moved it on to a new day
+	Notes     string     `json:"notes,omitempty"`     // Free text of any length, over as many lines as it needs
+	Recur     recurrence `json:"recur,omitempty"`     // How often a fresh copy follows it once it's done
+	RecurDays int        `json:"recurDays,omitempty"` // Days after it's done the next one is due, repeating after done
+	Marked    bool       `json:"-"`                   // Picked out for a bulk action; not saved
+
+	TimeSpent    time.Duration `json:"timeSpent,omitempty"` // Stopwatch time from finished runs
+	TimerStarted time.Time     `json:"timerStarted"`        // Zero unless the stopwatch is running on this task
+
+	Attachments []string `json:"attachments,omitempty"` // Paths of files the task refers to; the files themselves aren't kept
+}
+
+// age returns how long the task has existed, or zero when its creation
+// time isn't known
+func (t task) age(now time.Time) time.Duration {
+	if t.Created.IsZero() {
+		return 0
+	}
+	return now.Sub(t.Created)
+}
+
+// subtreeEnd returns the index just past the last descendant of tasks[i].
+// Subtasks are stored inline after their parent with a greater Depth.
+func subtreeEnd(tasks []task, i int) int {
+	j := i + 1
+	for j < len(tasks) && tasks[j].Depth > tasks[i].Depth {
+		j++
+	}
+	return j
+}
+
+// finishedSubtrees returns where each run of tasks that are done, subtasks
+// and all, starts, in order. A finished task with any subtask still to do
+// isn't counted, though its finished subtasks can be.
+func finishedSubtrees(tasks []task) []int {
+	var starts []int
+	for i := 0; i < len(tasks); {
+		end := subtreeEnd(tasks, i)
+		if !slices.ContainsFunc(tasks[i:end], func(t task) bool { return t.Status != statusDone }) {
+			starts = append(starts, i)
+			i = end
+			continue
+		}
+		i++
+	}
+	return starts
+}
+
+// prevSibling returns the index of the task above tasks[i] at its level
+// under the same parent, or -1 when it's the first there
+func prevSibling(tasks []task, i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if tasks[j].Depth == tasks[i].Depth {
+			return j
+		}
+		if tasks[j].Depth < tasks[i].Depth {
+			break // Reached the parent
+		}
+	}
+	return -1
+}
+
+// nextSibling returns the index of the task below tasks[i] at its level
+// under the same parent, or -1 when it's the last there
+func nextSibling(tasks []task, i int) int {
+	if j := subtreeEnd(tasks, i); j < len(tasks) && tasks[j].Depth == tasks[i].Depth {
+		return j
+	}
+	return -1
+}
+
+// moveSubtree moves tasks[i], with its subtasks, to just before its sibling
+// at to when that's above it, or to just after to's subtasks when it's
+// below, returning where tasks[i] ends up. Everything in between shifts
+// along to make room.
+func moveSubtree(tasks []task, i, to int) int {
+	end := subtreeEnd(tasks, i)
+	block := append([]task(nil), tasks[i:end]...)
+	if to < i {
+		copy(tasks[to+len(block):], tasks[to:i])
+		copy(tasks[to:], block)
+		return to
+	}
+	toEnd := subtreeEnd(tasks, to)
+	copy(tasks[i:], tasks[end:toEnd])
+	at := toEnd - len(block)
+	copy(tasks[at:], block)
+	return at
+}
+
+// rootOf returns the index of the top-level task that tasks[i] sits under,
+// which is i itself for a top-level task
+func rootOf(tasks []task, i int) int {
+	for i > 0 && tasks[i].Depth > 0 {
+		i--
+	}
+	return i
+}
+
+// hasChildren reports whether tasks[i] has at least one subtask
+func hasChildren(tasks []task, i int) bool {
+	return i+1 < len(tasks) && tasks[i+1].Depth > tasks[i].Depth
+}
+
+// dueLayout is the format accepted by the due: token
+const dueLayout = "2006-01-02"
+
+// parseDue pulls a due:YYYY-MM-DD token out of text, returning the remaining
+// text and the due date. A token that doesn't parse is left in the text.
+func parseDue(text string) (string, time.Time) {
+	var due time.Time
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, w := range words {
+		if v, ok := strings.CutPrefix(w, "due:"); ok {
+			if d, err := time.ParseInLocation(dueLayout, v, time.Local); err == nil {
+				due = d
+				continue
+			}
+		}
+		kept = append(kept, w)
+	}
+	if due.IsZero() {
+		return text, due
+	}
+	return strings.Join(kept, " "), due
+}
+
+// textRules are the clean-ups applied to a task's text as it's entered
+type textRules struct {
+	trim       bool // Drop leading and trailing whitespace
+	collapse   bool // Squeeze each run of whitespace inside the text to one space
+	capitalize bool // Upper-case the first letter
+}
+
+// apply returns text with every enabled clean-up carried out
+func (r textRules) apply(text string) string {
+	if r.collapse {
+		text = spaceRun.ReplaceAllString(text, " ")
+	}
+	if r.trim {
+		text = strings.TrimSpace(text)
+	}
+	if r.capitalize {
+		if first, size := utf8.DecodeRuneInString(text); first != utf8.RuneError {
+			text = string(unicode.ToUpper(first)) + text[size:]
+		}
+	}
+	return text
+}
+
+// spaceRun matches whitespace that collapsing squeezes to a single space
+var spaceRun = regexp.MustCompile(`\s{2,}|[\t\n\r\f\v]`)
+
+// tagPattern matches a #tag token: a letter followed by letters, digits,
+// dashes or underscores, so that "#42" stays an issue number, or a date
+// such as #2024-06-01
+var tagPattern = regexp.MustCompile(`^#(\pL[\pL\pN_-]*|\d{4}-\d{2}-\d{2})$`)
+
+// parseTags pulls #tag tokens out of text, returning the remaining text and
+// the distinct tags in the order they appeared
+func parseTags(text string) (string, []string) {
+	var tags []string
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, w := range words {
+		if tagPattern.MatchString(w) {
+			if tag := strings.ToLower(w[1:]); !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if tags == nil {
+		return text, nil
+	}
+	return strings.Join(kept, " "), tags
+}
+
+// hasTag reports whether the task carries tag; every task has the empty tag
+func (t task) hasTag(tag string) bool {
+	return tag == "" || slices.Contains(t.Tags, tag)
+}
+
+// parseTask turns a line of input into a task, pulling out the inline
+// attributes: due:YYYY-MM-DD or @when (also in words, as parseDay reads
+// them, and with a time of day after it as in "@fri 3pm") for the due
+// date, !0 to !3 for the priority, #tag for tags, and every: or after:
+// for how it repeats.
+// Unrecognized tokens stay in the text. The task gets priority p unless
+// the text sets one.
+func parseTask(text string, now time.Time, p priority) task {
+	text, due := parseDue(text)
+	text, tags := parseTags(text)
+
+	var when time.Time
+	var recur recurrence
+	var recurDays int
+	words := strings.Fields(text)
+	kept := words[:0]
+	stripped := false
+	for n := 0; n < len(words); n++ {
+		w := words[n]
+		if v, ok := parsePriority(w); ok {
+			p, stripped = v, true
+			continue
+		}
+		if r, days, ok := parseRecurrence(w); ok {
+			recur, recurDays, stripped = r, days, true
+			continue
+		}
+		if d, used, ok := parseWhenPhrase(words[n:], now); ok {
+			when = d
+			n += used - 1
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if stripped || !when.IsZero() {
+		text = strings.Join(kept, " ")
+	}
+	if due.IsZero() {
+		due = when
+	}
+	return task{Text: text, Priority: p, Due: due, Tags: tags, Recur: recur, RecurDays: recurDays}
+}
+
+// parsePriority reads a !0 (none), !1 (low), !2 (medium) or !3 (high) token
+func parsePriority(word string) (priority, bool) {
+	switch word {
+	case "!0":
+		return priorityNone, true
+	case "!1":
+		return priorityLow, true
+	case "!2":
+		return priorityMedium, true
+	case "!3":
+		return priorityHigh, true
+	}
+	return priorityNone, false
+}
+
+// parseWhen reads an @when token relative to now: @today, @tomorrow (or
+// @tmr), a weekday such as @fri for its next occurrence, @+3d or @2w for a
+// number of days or weeks ahead, or @YYYY-MM-DD
+func parseWhen(word string, now time.Time) (time.Time, bool) {
+	when, ok := strings.CutPrefix(strings.ToLower(word), "@")
+	if !ok || when == "" {
+		return time.Time{}, false
+	}
+	today := startOfDay(now)
+
+	switch when {
+	case "today":
+		return today, true
+	case "tomorrow", "tmr":
+		return today.AddDate(0, 0, 1), true
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if when == name || when == name[:3] {
+			ahead := (int(d)-int(today.Weekday())+6)%7 + 1
+			return today.AddDate(0, 0, ahead), true
+		}
+	}
+	if n, unit, ok := parseOffset(strings.TrimPrefix(when, "+")); ok {
+		return today.AddDate(0, 0, n*unit), true
+	}
+	if d, err := time.ParseInLocation(dueLayout, when, time.Local); err == nil {
+		return d, true
+	}
+	return time.Time{}, false
+}
+
+// parseOffset reads a count of days or weeks such as 3d or 2w, returning the
+// count and the days per unit
+func parseOffset(s string) (int, int, bool) {
+	if len(s) < 2 {
+		return 0, 0, false
+	}
+	unit := map[byte]int{'d': 1, 'w': 7}[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if unit == 0 || err != nil || n < 0 {
+		return 0, 0, false
+	}
+	return n, unit, true
+}
+
+// startOfDay truncates t to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+// dueToday reports whether an unfinished task falls due on now's date
+func (t task) dueToday(now time.Time) bool {
+	return !t.Due.IsZero() && t.Status != statusDone && startOfDay(t.Due).Equal(startOfDay(now))
+}
+
+// dueSoon reports whether an unfinished task falls due within lead days
+// from now, but not yet today
+func (t task) dueSoon(now time.Time, lead int) bool {
+	today := startOfDay(now)
+	return lead > 0 && !t.Due.IsZero() && t.Status != statusDone &&
+		!t.Due.Before(today.AddDate(0, 0, 1)) && t.Due.Before(today.AddDate(0, 0, lead+1))
+}
+
+// pastDue reports whether an unfinished task is overdue, or was due at a
+// time of day today that's gone by
+func (t task) pastDue(now time.Time) bool {
+	return t.overdue(now) || hasClock(t.Due) && t.Status != statusDone && t.Due.Before(now)
+}
+
+// overdue reports whether an unfinished task was due before today
+func (t task) overdue(now time.Time) bool {
+	return !t.Due.IsZero() && t.Status != statusDone && t.Due.Before(startOfDay(now))
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette holds the colors the app draws with, each 0-255 or #hex. Each
+// color has a role, and every style in that role takes it.
+type palette struct {
+	Accent      string `json:"accent"`      // The selected task, the cursor, prompts and marks
+	Title       string `json:"title"`       // Screen titles, fold markers and the key panel's border
+	Text        string `json:"text"`        // Task text and plain lines
+	Dim         string `json:"dim"`         // Key hints and the status bar
+	Faint       string `json:"faint"`       // Metadata, waiting tasks and the screen while unfocused
+	Warn        string `json:"warn"`        // Tasks in progress, due soon, badges and notices
+	Good        string `json:"good"`        // Finished tasks and progress bars
+	Info        string `json:"info"`        // Due dates, goals and the quick action bar
+	Heading     string `json:"heading"`     // Section headings in the help
+	Highlight   string `json:"highlight"`   // Behind the task just picked and search matches
+	OnHighlight string `json:"onHighlight"` // The text on Highlight
+
+	// Behind zebra rows and the highlighted cursor row; left empty, they
+	// follow the terminal's own background, light or dark
+	Stripe    string `json:"stripe"`
+	CursorRow string `json:"cursorRow"`
+}
+
+// entries lists the palette's colors with their names in the config
+func (p *palette) entries() []struct {
+	name  string
+	value *string
+} {
+	return []struct {
+		name  string
+		value *string
+	}{
+		{"accent", &p.Accent}, {"title", &p.Title}, {"text", &p.Text},
+		{"dim", &p.Dim}, {"faint", &p.Faint}, {"warn", &p.Warn},
+		{"good", &p.Good}, {"info", &p.Info}, {"heading", &p.Heading},
+		{"highlight", &p.Highlight}, {"onHighlight", &p.OnHighlight},
+		{"stripe", &p.Stripe}, {"cursorRow", &p.CursorRow},
+	}
+}
+
+// over fills each color p leaves empty from base
+func (p palette) over(base palette) palette {
+	fill := base.entries()
+	for n, e := range p.entries() {
+		if *e.value == "" {
+			*e.value = *fill[n].value
+		}
+	}
+	return p
+}
+
+// validate empties any color that isn't 0-255 or #hex, so the theme's is
+// used, returning a warning for each
+func (p *palette) validate() []string {
+	var warnings []string
+	for _, e := range p.entries() {
+		if *e.value != "" && !colorPattern.MatchString(*e.value) {
+			warnings = append(warnings, fmt.Sprintf("ignoring colors %s %q; use 0-255 or #hex", e.name, *e.value))
+			*e.value = ""
+		}
+	}
+	return warnings
+}
+
+// theme is one of the built-in palettes ctrl+b switches between
+type theme struct {
+	name   string // As set in the config and saved in the tasks file
+	colors palette
+}
+
+// themes lists the built-in palettes in the order ctrl+b cycles them. The
+// first, dark, is the default.
+var themes = []theme{
+	{name: "dark", colors: palette{
+		Accent: "212", Title: "99", Text: "245", Dim: "241", Faint: "240",
+		Warn: "214", Good: "78", Info: "109", Heading: "205",
+		Highlight: "62", OnHighlight: "230",
+	}},
+	{name: "light", colors: palette{
+		Accent: "162", Title: "56", Text: "238", Dim: "243", Faint: "246",
+		Warn: "166", Good: "28", Info: "24", Heading: "125",
+		Highlight: "62", OnHighlight: "231",
+	}},
+}
+
+// themeNamed finds a theme by name, reporting whether there is one
+func themeNamed(name string) (int, bool) {
+	i := slices.IndexFunc(themes, func(t theme) bool { return t.name == name })
+	return max(i, 0), i >= 0
+}
+
+// setTheme recolors every style from the theme at index i of themes, with
+// the config's colors over it
+func (m *model) setTheme(i int) {
+	m.theme = i
+	applyPalette(m.themeColors.over(themes[i].colors))
+}
+
+// cycleTheme steps on to the next theme and saves the choice
+func (m *model) cycleTheme() {
+	m.setTheme((m.theme + 1) % len(themes))
+	m.dirty = true
+	m.notice = "Using the " + themes[m.theme].name + " theme • ctrl+b for the next"
+}
+
+// applyPalette gives each style its role's color from p, leaving the rest
+// of its look as it is
+func applyPalette(p palette) {
+	accent, title, text := lipgloss.Color(p.Accent), lipgloss.Color(p.Title), lipgloss.Color(p.Text)
+	dim, faint, warn := lipgloss.Color(p.Dim), lipgloss.Color(p.Faint), lipgloss.Color(p.Warn)
+	good, info := lipgloss.Color(p.Good), lipgloss.Color(p.Info)
+	highlight, onHighlight := lipgloss.Color(p.Highlight), lipgloss.Color(p.OnHighlight)
+
+	selectedStyle = selectedStyle.Foreground(accent)
+	cursorStyle = cursorStyle.Foreground(accent)
+	inputPromptStyle = inputPromptStyle.Foreground(accent)
+	markStyle = markStyle.Foreground(accent)
+	cheatKeyStyle = cheatKeyStyle.Foreground(accent)
+	boostStyle = boostStyle.Foreground(accent)
+	calTodayStyle = calTodayStyle.Foreground(accent)
+	calSelectedStyle = calSelectedStyle.Background(accent)
+	emojiSelectedStyle = emojiCellStyle.Background(accent)
+
+	titleStyle = titleStyle.Foreground(title)
+	foldStyle = foldStyle.Foreground(title)
+	cheatSheetStyle = cheatSheetStyle.BorderForeground(title)
+
+	taskStyle = taskStyle.Foreground(text)
+	normalStyle = normalStyle.Foreground(text)
+	todoStyle = todoStyle.Foreground(text)
+	calDayStyle = calDayStyle.Foreground(text)
+
+	helpStyle = helpStyle.Foreground(dim)
+	statusBarStyle = statusBarStyle.Foreground(dim)
+	priorityBadgeStyle = priorityBadgeStyle.Foreground(dim)
+	calHeaderStyle = calHeaderStyle.Foreground(dim)
+
+	blurredStyle = blurredStyle.Foreground(faint)
+	waitingStyle = waitingStyle.Foreground(faint)
+	metaStyle = metaStyle.Foreground(faint)
+	brokenStyle = metaStyle.Strikethrough(true)
+	detailPaneStyle = detailPaneStyle.BorderForeground(faint)
+
+	doingStyle = doingStyle.Foreground(warn)
+	dueSoonStyle = dueSoonStyle.Foreground(warn)
+	badgeStyle = badgeStyle.Foreground(warn)
+	noticeStyle = noticeStyle.Foreground(warn)
+
+	doneStyle = doneStyle.Foreground(good)
+	progressStyle = progressStyle.Foreground(good)
+
+	dueStyle = dueStyle.Foreground(info)
+	goalStyle = goalStyle.Foreground(info)
+	quickBarStyle = quickBarStyle.Foreground(info)
+
+	headingStyle = headingStyle.Foreground(lipgloss.Color(p.Heading))
+	pickedStyle = pickedStyle.Foreground(onHighlight).Background(highlight)
+	matchStyle = matchStyle.Foreground(onHighlight).Background(highlight)
+
+	stripeStyle = stripeStyle.Background(backdrop(p.Stripe, lipgloss.AdaptiveColor{Light: "254", Dark: "236"}))
+	cursorRowStyle = cursorRowStyle.Background(backdrop(p.CursorRow, lipgloss.AdaptiveColor{Light: "252", Dark: "238"}))
+}
+
+// backdrop returns color as a background, or fallback, which follows the
+// terminal's, when it's empty
+func backdrop(color string, fallback lipgloss.AdaptiveColor) lipgloss.TerminalColor {
+	if color == "" {
+		return fallback
+	}
+	return lipgloss.Color(color)
+}
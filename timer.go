@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// timerTickMsg refreshes the running stopwatch. It carries the ID of the run
+// that scheduled it so ticks from a stopped run are dropped.
+type timerTickMsg int
+
+// tickTimer schedules the next refresh of stopwatch run id
+func tickTimer(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return timerTickMsg(id) })
+}
+
+// spent returns the time tracked on the task, including a run in progress
+func (t task) spent(now time.Time) time.Duration {
+	if t.TimerStarted.IsZero() {
+		return t.TimeSpent
+	}
+	return t.TimeSpent + now.Sub(t.TimerStarted)
+}
+
+// runningTimer finds the task whose stopwatch is running, in whichever list
+// it's in, returning nil when none is
+func (m *model) runningTimer() *task {
+	for l := range m.lists {
+		tasks := m.lists[l].Tasks
+		if l == m.active {
+			tasks = m.tasks
+		}
+		for i := range tasks {
+			if !tasks[i].TimerStarted.IsZero() {
+				return &tasks[i]
+			}
+		}
+	}
+	return nil
+}
+
+// stopTimer adds the running stopwatch's time to its task, reporting
+// whether one was running
+func (m *model) stopTimer() bool {
+	t := m.runningTimer()
+	if t == nil {
+		return false
+	}
+	t.TimeSpent += time.Since(t.TimerStarted).Round(time.Second)
+	t.TimerStarted = time.Time{}
+	m.dirty = true
+	return true
+}
+
+// toggleTimer stops the stopwatch on task i if it's running there, and
+// otherwise starts it, stopping any other run first
+func (m model) toggleTimer(i int) (tea.Model, tea.Cmd) {
+	running := !m.tasks[i].TimerStarted.IsZero()
+	m.stopTimer()
+	if running {
+		m.notice = "Stopped after " + formatDuration(m.tasks[i].TimeSpent) + " in total"
+		return m, nil
+	}
+	m.tasks[i].TimerStarted = time.Now()
+	m.dirty = true
+	m.timerID++
+	return m, tickTimer(m.timerID)
+}
+
+// endFocus stops the stopwatch when its run has lasted a focus session and
+// asks what's next: move on to the next unfinished task, stay on this one,
+// or mark it done. Away from the list, or when the run is in another list,
+// a notice says so instead.
+func (m model) endFocus() (tea.Model, tea.Cmd) {
+	text := m.runningTimer().Text
+	m.focusTask = slices.IndexFunc(m.tasks, func(t task) bool { return !t.TimerStarted.IsZero() })
+	m.stopTimer()
+	if m.state != browsing || m.focusTask < 0 {
+		m.notice = fmt.Sprintf("Focus session on %q is over", truncateRunes(text, 30))
+		return m, nil
+	}
+	return m.startPrompt(promptFocusDone, fmt.Sprintf("Focus session on %q is over. n: next task • s: stay • d: mark done", truncateRunes(text, 30)), "n")
+}
+
+// finishFocus carries out the answer to the end of a focus session
+func (m *model) finishFocus(answer string) {
+	switch strings.ToLower(answer) {
+	case "n", "next":
+		m.moveCursorTo(m.focusTask)
+		m.advanceCursor()
+	case "d", "done":
+		if m.tasks[m.focusTask].Status != statusDone {
+			m.setStatus(m.focusTask, statusDone)
+		}
+	}
+}
+
+// countdownTickMsg refreshes the deadline countdown. Like timerTickMsg it
+// carries the ID of the countdown that scheduled it.
+type countdownTickMsg int
+
+// tickCountdown schedules the next refresh of countdown id
+func tickCountdown(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return countdownTickMsg(id) })
+}
+
+// toggleCountdown shows or hides the countdown to the selected task's
+// deadline, ticking only while it's shown
+func (m model) toggleCountdown() (tea.Model, tea.Cmd) {
+	m.countdown = !m.countdown
+	m.countdownID++ // Drops the ticks of the countdown shown before
+	if !m.countdown {
+		return m, nil
+	}
+	return m, tickCountdown(m.countdownID)
+}
+
+// deadline returns when t runs out of time: its time of day when it has
+// one, or else the end of the day it's due
+func (t task) deadline() time.Time {
+	if hasClock(t.Due) {
+		return t.Due
+	}
+	y, mo, d := t.Due.Date()
+	return time.Date(y, mo, d+1, 0, 0, 0, 0, time.Local)
+}
+
+// formatCountdown says how long is left until deadline, or how long ago it
+// passed, to the second once under an hour
+func formatCountdown(deadline, now time.Time) string {
+	left := deadline.Sub(now).Round(time.Second)
+	over := left < 0
+	if over {
+		left = -left
+	}
+	var s string
+	switch {
+	case left >= 24*time.Hour:
+		s = fmt.Sprintf("%dd %dh", int(left.Hours())/24, int(left.Hours())%24)
+	case left >= time.Hour:
+		s = fmt.Sprintf("%dh %dm", int(left.Hours()), int(left.Minutes())%60)
+	case left >= time.Minute:
+		s = fmt.Sprintf("%dm %02ds", int(left.Minutes()), int(left.Seconds())%60)
+	default:
+		s = fmt.Sprintf("%ds", int(left.Seconds()))
+	}
+	if over {
+		return "overdue by " + s
+	}
+	return s + " left"
+}
+
+// formatDuration renders d as m:ss, or h:mm:ss from an hour up
+func formatDuration(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
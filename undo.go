@@ -0,0 +1,118 @@
+package main
+
+import "slices"
+
+// maxUndo bounds how many snapshots each of the undo and redo stacks keep
+const maxUndo = 100
+
+// snapshot captures everything needed to restore the lists to an earlier
+// state. Every list is kept since some changes, like moving a task, touch
+// more than one.
+type snapshot struct {
+	list   int // Index of the list that was open
+	lists  []taskList
+	cursor int
+	what   string // The change made after it, as undo and redo describe it
+}
+
+// snapshot copies every list along with the open list and cursor
+func (m model) snapshot() snapshot {
+	lists := m.allLists()
+	for i := range lists {
+		lists[i].Tasks = cloneTasks(lists[i].Tasks)
+	}
+	return snapshot{list: m.active, lists: lists, cursor: m.cursor}
+}
+
+// restore puts back the snapshot's lists and reopens its list and cursor
+func (m *model) restore(s snapshot) {
+	m.lists = s.lists
+	m.active = s.list
+	m.tasks = m.lists[m.active].Tasks
+	m.cursor = s.cursor
+	m.listCursor = min(m.listCursor, len(m.lists)-1)
+	if m.prevList >= len(m.lists) || m.prevList == m.active {
+		m.prevList = -1 // Undoing a new list or a merge can take it away
+	}
+}
+
+// checkpoint records the current state on the undo stack and marks the
+// model as needing a save. Call it just before a change; any redo history
+// is discarded since it no longer follows.
+func (m *model) checkpoint() {
+	m.undo = pushSnapshot(m.undo, m.snapshot())
+	m.redo = nil
+	m.dirty = true
+}
+
+// describeChange names the change since the last checkpoint, for undo and
+// redo to say what they took back, unless it's been named already
+func (m *model) describeChange(what string) {
+	if n := len(m.undo); n > 0 && m.undo[n-1].what == "" {
+		m.undo[n-1].what = what
+	}
+}
+
+// describe names the change the snapshot was taken before
+func (s snapshot) describe() string {
+	if s.what == "" {
+		return "the last change"
+	}
+	return s.what
+}
+
+// undoChange steps back to the most recent checkpoint, reporting whether
+// there was one
+func (m *model) undoChange() bool {
+	if len(m.undo) == 0 {
+		return false
+	}
+	target := m.undo[len(m.undo)-1]
+	m.switchList(target.list)
+	current := m.snapshot()
+	current.what = target.what
+	m.redo = pushSnapshot(m.redo, current)
+	m.restore(target)
+	m.undo = m.undo[:len(m.undo)-1]
+	m.dirty = true
+	m.notice = "Undid " + target.describe()
+	return true
+}
+
+// redoChange reapplies the most recently undone change, reporting whether
+// there was one
+func (m *model) redoChange() bool {
+	if len(m.redo) == 0 {
+		return false
+	}
+	target := m.redo[len(m.redo)-1]
+	m.switchList(target.list)
+	current := m.snapshot()
+	current.what = target.what
+	m.undo = pushSnapshot(m.undo, current)
+	m.restore(target)
+	m.redo = m.redo[:len(m.redo)-1]
+	m.dirty = true
+	m.notice = "Redid " + target.describe()
+	return true
+}
+
+// pushSnapshot appends s to stack, dropping the oldest entry once the stack
+// is full
+func pushSnapshot(stack []snapshot, s snapshot) []snapshot {
+	stack = append(stack, s)
+	if len(stack) > maxUndo {
+		stack = stack[len(stack)-maxUndo:]
+	}
+	return stack
+}
+
+// cloneTasks returns a copy of tasks that shares no memory with the original
+func cloneTasks(tasks []task) []task {
+	clone := append([]task(nil), tasks...)
+	for i := range clone {
+		clone[i].Tags = slices.Clone(clone[i].Tags)
+		clone[i].Attachments = slices.Clone(clone[i].Attachments)
+	}
+	return clone
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bigModel returns the app in a 100x40 terminal with n tasks in its list,
+// of mixed status, priority and depth, and a few tagged
+func bigModel(b *testing.B, n int) model {
+	b.Helper()
+	m := newTestModel(b, defaultConfig())
+	m.tasks = make([]task, n)
+	for i := range m.tasks {
+		m.tasks[i] = task{
+			Text:     fmt.Sprintf("Task number %d with some words to wrap", i),
+			Status:   taskStatus(i % 3),
+			Priority: priority(i % 4),
+			Depth:    i % 3,
+		}
+		if i%10 == 0 {
+			m.tasks[i].Tags = []string{"work", "later"}
+		}
+	}
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	return next.(model)
+}
+
+func BenchmarkViewThousands(b *testing.B) {
+	m := bigModel(b, 5000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.View()
+	}
+}
+
+// BenchmarkListGrowth draws the list and moves the cursor through it at
+// growing sizes, so a frame's time and allocations can be compared as the
+// list gets longer
+func BenchmarkListGrowth(b *testing.B) {
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for _, n := range []int{1000, 10000, 50000} {
+		m := bigModel(b, n)
+		b.Run(fmt.Sprintf("view/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = m.View()
+			}
+		})
+		b.Run(fmt.Sprintf("move/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			m := m
+			for i := 0; i < b.N; i++ {
+				next, _ := m.Update(down)
+				m = next.(model)
+				_ = m.View()
+			}
+		})
+	}
+}
+
+func TestRowsShowCheckboxes(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "open one", "finished one")
+	m.setStatus(1, statusDone)
+	view := m.View()
+	if !strings.Contains(view, "[ ] open one") || !strings.Contains(view, "[x]") {
+		t.Fatalf("rows lack their checkboxes:\n%s", view)
+	}
+
+	cfg := defaultConfig()
+	cfg.Checkboxes = false
+	m = addTasks(newTestModel(t, cfg), "open one")
+	if view := m.View(); strings.Contains(view, "[ ]") {
+		t.Fatalf("checkboxes off still drew one:\n%s", view)
+	}
+}
+
+func TestDetailPaneShowsOnWideTerminals(t *testing.T) {
+	m := newTestModel(t, defaultConfig())
+	m = addTasks(m, "write the report")
+	for _, tc := range []struct {
+		width int
+		pane  bool
+	}{
+		{80, false},
+		{splitWidth - 1, false},
+		{splitWidth, true},
+		{160, true},
+	} {
+		next, _ := m.Update(tea.WindowSizeMsg{Width: tc.width, Height: 30})
+		if got := strings.Contains(next.(model).View(), "📄 Task"); got != tc.pane {
+			t.Errorf("%d columns wide: detail pane shown %v, want %v", tc.width, got, tc.pane)
+		}
+	}
+
+	// | turns the pane off however wide the terminal is
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	m = press(next.(model), runes("|"))
+	if strings.Contains(m.View(), "📄 Task") {
+		t.Fatal("| left the detail pane showing")
+	}
+}